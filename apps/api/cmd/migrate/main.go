@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+
+	"github.com/it-tms/apps/api/db"
+	"github.com/it-tms/apps/api/internal/migrate"
+	"github.com/it-tms/apps/api/pkg/config"
+	"github.com/it-tms/apps/api/pkg/logger"
+)
+
+const usage = `Usage: migrate [-database URL] <command> [arg]
+
+Commands:
+  up                apply all pending migrations
+  down [n]          roll back the last n migrations (default 1)
+  to <version>      migrate up or down to version (0 rolls back everything)
+  status            list migrations and whether they are applied
+  baseline <version>
+                    record migrations up to version as applied without running
+                    them, for databases created before migrations were versioned
+
+The database defaults to DATABASE_URL.
+`
+
+func main() {
+	viper.AutomaticEnv()
+	cfg := config.Load()
+	logger.Init()
+
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	dbURL := flag.String("database", cfg.DatabaseURL, "database URL")
+	flag.Parse()
+	if flag.NArg() == 0 || *dbURL == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load migrations")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, *dbURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create db pool")
+	}
+	defer pool.Close()
+	m := migrate.New(pool, migrations)
+
+	cmd, arg := flag.Arg(0), flag.Arg(1)
+	switch cmd {
+	case "up":
+		n, err := m.Up(ctx)
+		done(n, err)
+	case "down":
+		steps := 1
+		if arg != "" {
+			if steps, err = strconv.Atoi(arg); err != nil || steps < 1 {
+				log.Fatal().Str("n", arg).Msg("down takes a positive number of migrations")
+			}
+		}
+		n, err := m.Down(ctx, steps)
+		done(n, err)
+	case "to":
+		version, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || version < 0 {
+			log.Fatal().Str("version", arg).Msg("to takes a migration version")
+		}
+		n, err := m.To(ctx, version)
+		done(n, err)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to read migration status")
+		}
+		printStatus(status)
+	case "baseline":
+		version, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			log.Fatal().Str("version", arg).Msg("baseline takes a migration version")
+		}
+		if err := m.Baseline(ctx, version); err != nil {
+			log.Fatal().Err(err).Msg("baseline failed")
+		}
+		log.Info().Int64("version", version).Msg("baseline recorded")
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func done(n int, err error) {
+	if err != nil {
+		log.Fatal().Err(err).Int("ran", n).Msg("migration failed")
+	}
+	if n == 0 {
+		log.Info().Msg("database is up to date")
+		return
+	}
+	log.Info().Int("ran", n).Msg("migrations complete")
+}
+
+func printStatus(status []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, s := range status {
+		at := "-"
+		if s.AppliedAt != nil {
+			at = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, at)
+	}
+	w.Flush()
+}
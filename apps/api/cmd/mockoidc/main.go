@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/it-tms/apps/api/internal/oidc/oidctest"
+	"github.com/it-tms/apps/api/pkg/logger"
+)
+
+// mockoidc runs the test OpenID Connect provider for trying single sign-on
+// locally. Every sign-in is approved at once as the user given by the flags;
+// point OIDC_ISSUER_URL at the address it prints.
+func main() {
+	logger.Init()
+
+	addr := flag.String("addr", "127.0.0.1:9400", "listen address")
+	clientID := flag.String("client-id", "it-tms", "client ID the API uses (OIDC_CLIENT_ID)")
+	secret := flag.String("client-secret", "it-tms-secret", "client secret the API uses (OIDC_CLIENT_SECRET)")
+	subject := flag.String("sub", "mock-user-1", "subject of the signed-in user")
+	email := flag.String("email", "somchai@example.com", "email of the signed-in user")
+	name := flag.String("name", "Somchai Jaidee", "name of the signed-in user")
+	groups := flag.String("groups", "it-staff", "comma-separated groups of the signed-in user")
+	verified := flag.Bool("email-verified", true, "email_verified claim of the signed-in user")
+	omitVerified := flag.Bool("omit-email-verified", false, "leave the email_verified claim out")
+	flag.Parse()
+
+	p, err := oidctest.Listen(*addr, *clientID, *secret)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to start provider")
+	}
+	defer p.Close()
+	var gs []string
+	for _, g := range strings.Split(*groups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			gs = append(gs, g)
+		}
+	}
+	u := oidctest.User{Subject: *subject, Email: *email, EmailVerified: verified, Name: *name, Groups: gs}
+	if *omitVerified {
+		u.EmailVerified = nil
+	}
+	p.SetUser(u)
+	log.Info().Str("issuer", p.Issuer).Str("email", *email).Strs("groups", gs).Msg("mock OIDC provider running")
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
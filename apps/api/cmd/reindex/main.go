@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+
+	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/pkg/config"
+	"github.com/it-tms/apps/api/pkg/logger"
+)
+
+// reindex rebuilds the search index from every ticket and comment. The API
+// maintains the index on write and migration 0014 backfills it; run this
+// after seeding or whenever the tokenizer in internal/search changes.
+func main() {
+	viper.AutomaticEnv()
+	cfg := config.Load()
+	logger.Init()
+
+	dbURL := flag.String("database", cfg.DatabaseURL, "database URL")
+	flag.Parse()
+	if *dbURL == "" {
+		log.Fatal().Msg("no database URL: set DATABASE_URL or -database")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, *dbURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create db pool")
+	}
+	defer pool.Close()
+
+	var tickets, comments int
+	err = repositories.New(pool).WithTx(ctx, func(tx *repositories.Repo) error {
+		tickets, comments, err = tx.Search.Reindex(ctx)
+		return err
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("reindex failed")
+	}
+	log.Info().Int("tickets", tickets).Int("comments", comments).Msg("search index rebuilt")
+}
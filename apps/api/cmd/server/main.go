@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,8 +15,10 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 
+	"github.com/it-tms/apps/api/db"
 	"github.com/it-tms/apps/api/internal/http/handlers"
 	"github.com/it-tms/apps/api/internal/http/middleware"
+	"github.com/it-tms/apps/api/internal/migrate"
 	"github.com/it-tms/apps/api/pkg/config"
 	"github.com/it-tms/apps/api/pkg/logger"
 )
@@ -24,6 +27,8 @@ func main() {
 	// Load env
 	viper.AutomaticEnv()
 	cfg := config.Load()
+	migrateOnStart := flag.Bool("migrate", cfg.MigrateOnStart, "apply pending database migrations before serving (MIGRATE_ON_START)")
+	flag.Parse()
 
 	// Logger
 	logger.Init()
@@ -41,6 +46,19 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to ping db")
 	}
 
+	// Schema migrations
+	if *migrateOnStart {
+		migrations, err := migrate.Load(db.Migrations, "migrations")
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load migrations")
+		}
+		n, err := migrate.New(pool, migrations).Up(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to apply migrations")
+		}
+		log.Info().Int("applied", n).Msg("database schema is up to date")
+	}
+
 	// Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "IT-TMS API",
@@ -64,6 +82,14 @@ func main() {
 
 	// Initialize handlers
 	h := handlers.New(pool, cfg)
+	sessions := h.Sessions()
+
+	// Background jobs
+	go h.RunAutoClose(ctx, time.Hour)
+	go h.RunEventBus(ctx)
+	go h.RunNotifications(ctx, 30*time.Second)
+	go h.RunDigests(ctx, 15*time.Minute)
+	go h.RunWebhooks(ctx, 10*time.Second)
 
 	// Health endpoint
 	app.Get("/healthz", func(c *fiber.Ctx) error {
@@ -81,47 +107,102 @@ func main() {
 	auth.Post("/sign-in", h.SignIn)
 	auth.Post("/sign-out", h.SignOut)
 	auth.Post("/sign-up", h.SignUp)
+	auth.Post("/refresh", h.AuthRefresh)
+	auth.Get("/invitation", h.AuthInvitation)
+	auth.Post("/invitation/accept", h.AuthInvitationAccept)
+	auth.Post("/password/forgot", h.AuthPasswordForgot)
+	auth.Post("/password/reset", h.AuthPasswordReset)
+	auth.Get("/oidc/login", h.AuthOIDCLogin)
+	auth.Get("/oidc/callback", h.AuthOIDCCallback)
 
 	// Optional auth routes (for anonymous access)
-	v1.Get("/me", middleware.AuthOptional(cfg.JWTSecret), h.Me)
-	v1.Post("/tickets", middleware.AuthOptional(cfg.JWTSecret), h.TicketsCreate)
-	v1.Get("/tickets", middleware.AuthOptional(cfg.JWTSecret), h.TicketsList)
-	v1.Get("/tickets/:id", middleware.AuthOptional(cfg.JWTSecret), h.TicketsDetail)
-	v1.Post("/tickets/:id/attachments", middleware.AuthOptional(cfg.JWTSecret), h.TicketsUploadAttachments)
+	v1.Get("/me", middleware.AuthOptional(cfg.JWTSecret, sessions), h.Me)
+	v1.Post("/tickets", middleware.AuthOptional(cfg.JWTSecret, sessions), h.TicketsCreate)
+	v1.Get("/tickets", middleware.AuthOptional(cfg.JWTSecret, sessions), h.TicketsList)
+	v1.Get("/tickets/:id", middleware.AuthOptional(cfg.JWTSecret, sessions), h.TicketsDetail)
+	v1.Post("/tickets/:id/attachments", middleware.AuthOptional(cfg.JWTSecret, sessions), h.TicketsUploadAttachments)
 	v1.Get("/metrics/summary", h.MetricsSummary)
+	v1.Get("/metrics/sla", h.MetricsSLA)
 	v1.Get("/rankings", h.GetUserRankings)
 	v1.Post("/priority/compute", h.PriorityCompute)
 
+	// Inbound email, authenticated with MAIL_INBOUND_TOKEN rather than a session
+	v1.Post("/mail/inbound", h.MailInbound)
+
 	// Protected routes (require authentication)
-	protected := v1.Group("/", middleware.AuthRequired(cfg.JWTSecret))
+	protected := v1.Group("/", middleware.AuthRequired(cfg.JWTSecret, sessions))
+	protected.Get("/me/mentions", h.MeMentions)
+	protected.Get("/me/sessions", h.MeSessions)
+	protected.Delete("/me/sessions", h.MeSessionsRevokeOthers)
+	protected.Delete("/me/sessions/:id", h.MeSessionsRevoke)
 	protected.Patch("/profile", h.ProfileUpdate)
+	protected.Post("/profile/password", h.ProfilePassword)
+	protected.Get("/profile/notifications", h.ProfileNotificationsGet)
+	protected.Patch("/profile/notifications", h.ProfileNotificationsUpdate)
 	protected.Post("/profile/picture", h.ProfilePictureUpload)
 	protected.Get("/profile/performance", h.GetUserPerformanceStats)
 	protected.Get("/users/search", h.UsersSearch)
+	protected.Get("/search", h.Search)
+	protected.Get("/events", h.Events)
+	protected.Get("/views", h.ViewsList)
+	protected.Post("/views", h.ViewsCreate)
+	protected.Get("/views/counts", h.ViewsCounts)
+	protected.Get("/views/:id", h.ViewsGet)
+	protected.Patch("/views/:id", h.ViewsUpdate)
+	protected.Delete("/views/:id", h.ViewsDelete)
+	protected.Get("/sla/policies", h.SLAPoliciesList)
+	protected.Get("/calendar", h.CalendarGet)
+	protected.Get("/calendar/holidays", h.HolidaysList)
 	protected.Patch("/tickets/:id", h.TicketsUpdate)
 	protected.Patch("/tickets/:id/fields", h.TicketsUpdateFields)
 	protected.Post("/tickets/:id/assign", h.TicketsAssign)
 	protected.Delete("/tickets/:id/assign", h.TicketsUnassign)
 	protected.Post("/tickets/:id/status", h.TicketsStatus)
+	protected.Post("/tickets/:id/reopen", h.TicketsReopen)
 	protected.Post("/tickets/:id/comments", h.TicketsAddComment)
 	protected.Get("/tickets/:id/comments", h.TicketsGetComments)
+	protected.Post("/tickets/:id/watch", h.TicketsWatch)
+	protected.Delete("/tickets/:id/watch", h.TicketsUnwatch)
 	protected.Post("/tickets/:id/comments/:commentId/attachments", h.CommentsUploadAttachments)
 	
 	// Download routes (require auth with redirect for browser requests)
 	signInURL := cfg.WebAppURL + "/sign-in"
-	v1.Get("/attachments/:attachmentId/download", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, signInURL), h.DownloadAttachment)
-	v1.Get("/comment-attachments/:attachmentId/download", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, signInURL), h.DownloadCommentAttachment)
+	v1.Get("/attachments/:attachmentId/download", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, sessions, signInURL), h.DownloadAttachment)
+	v1.Get("/comment-attachments/:attachmentId/download", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, sessions, signInURL), h.DownloadCommentAttachment)
 
 	// Admin routes (require Supervisor or Manager roles)
-	admin := v1.Group("/", middleware.RequireSupervisorOrManager(cfg.JWTSecret))
+	admin := v1.Group("/", middleware.RequireSupervisorOrManager(cfg.JWTSecret, sessions))
 	admin.Post("/tickets/:id/classify", h.TicketsClassify)
 	admin.Put("/tickets/:id/red-flags", h.TicketsUpdateRedFlags)
 	admin.Put("/tickets/:id/impact-assessment", h.TicketsUpdateImpactAssessment)
 	admin.Put("/tickets/:id/urgency-timeline", h.TicketsUpdateUrgencyTimeline)
 	admin.Post("/tickets/:id/effort", h.TicketsUpdateEffort)
 
+	// Manager-only routes
+	manager := v1.Group("/", middleware.RequireRole(cfg.JWTSecret, sessions, "Manager"))
+	manager.Put("/sla/policies", h.SLAPoliciesUpsert)
+	manager.Delete("/sla/policies/:id", h.SLAPoliciesDelete)
+	manager.Put("/calendar/hours", h.CalendarUpdateHours)
+	manager.Post("/calendar/holidays", h.HolidaysCreate)
+	manager.Delete("/calendar/holidays/:id", h.HolidaysDelete)
+	manager.Get("/users", h.UsersList)
+	manager.Post("/users", h.UsersCreate)
+	manager.Patch("/users/:id", h.UsersUpdate)
+	manager.Post("/users/:id/deactivate", h.UsersDeactivate)
+	manager.Post("/users/:id/reactivate", h.UsersReactivate)
+	manager.Get("/invitations", h.InvitationsList)
+	manager.Post("/invitations", h.InvitationsCreate)
+	manager.Delete("/invitations/:id", h.InvitationsRevoke)
+	manager.Get("/webhooks", h.WebhooksList)
+	manager.Post("/webhooks", h.WebhooksCreate)
+	manager.Get("/webhooks/:id", h.WebhooksGet)
+	manager.Patch("/webhooks/:id", h.WebhooksUpdate)
+	manager.Delete("/webhooks/:id", h.WebhooksDelete)
+	manager.Get("/webhooks/:id/deliveries", h.WebhookDeliveriesList)
+	manager.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", h.WebhookRedeliver)
+
 	// Static file serving - protected with authentication
-	app.Get("/uploads/*", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, signInURL), func(c *fiber.Ctx) error {
+	app.Get("/uploads/*", middleware.AuthRequiredWithRedirect(cfg.JWTSecret, sessions, signInURL), func(c *fiber.Ctx) error {
 		// Extract the file path after /uploads/
 		filePath := c.Params("*")
 		fullPath := filepath.Join(cfg.UploadDir, filePath)
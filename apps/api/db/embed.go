@@ -0,0 +1,11 @@
+// Package db holds the SQL schema of the API. Migrations are embedded so that
+// cmd/migrate and cmd/server can bring a database up to date from the binary
+// alone.
+package db
+
+import "embed"
+
+// Migrations contains migrations/NNNN_name.up.sql and NNNN_name.down.sql.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
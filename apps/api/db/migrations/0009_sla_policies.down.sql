@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_tickets_resolution_due_at;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS first_responded_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS resolution_due_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS response_due_at;
+
+DROP TABLE IF EXISTS sla_policies;
@@ -0,0 +1,26 @@
+-- SLA policies: response and resolution targets per priority, optionally per ticket type
+CREATE TABLE IF NOT EXISTS sla_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  priority ticket_priority NOT NULL,
+  initial_type ticket_initial_type NULL,
+  response_minutes INTEGER NOT NULL CHECK (response_minutes > 0),
+  resolution_minutes INTEGER NOT NULL CHECK (resolution_minutes > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE NULLS NOT DISTINCT (priority, initial_type)
+);
+
+-- Default targets per priority (NULL initial_type applies to every ticket type)
+INSERT INTO sla_policies (priority, initial_type, response_minutes, resolution_minutes) VALUES
+  ('P0', NULL, 30, 240),
+  ('P1', NULL, 60, 480),
+  ('P2', NULL, 240, 4320),
+  ('P3', NULL, 480, 7200)
+ON CONFLICT DO NOTHING;
+
+-- SLA deadlines stamped on tickets
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS response_due_at TIMESTAMPTZ NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS resolution_due_at TIMESTAMPTZ NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS first_responded_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_resolution_due_at ON tickets (resolution_due_at);
@@ -0,0 +1,28 @@
+-- Weekly working schedule used by SLA clocks (weekday: 0=Sunday .. 6=Saturday)
+CREATE TABLE IF NOT EXISTS business_hours (
+  weekday SMALLINT PRIMARY KEY CHECK (weekday BETWEEN 0 AND 6),
+  start_time TIME NOT NULL DEFAULT '08:30',
+  end_time TIME NOT NULL DEFAULT '17:30',
+  is_working_day BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK (end_time > start_time)
+);
+
+-- Thai office hours: Monday to Friday, 08:30-17:30
+INSERT INTO business_hours (weekday, start_time, end_time, is_working_day) VALUES
+  (0, '08:30', '17:30', FALSE),
+  (1, '08:30', '17:30', TRUE),
+  (2, '08:30', '17:30', TRUE),
+  (3, '08:30', '17:30', TRUE),
+  (4, '08:30', '17:30', TRUE),
+  (5, '08:30', '17:30', TRUE),
+  (6, '08:30', '17:30', FALSE)
+ON CONFLICT DO NOTHING;
+
+-- Public holidays; SLA clocks do not run on these dates
+CREATE TABLE IF NOT EXISTS holidays (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  date DATE NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_tickets_paused_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS paused_at;
+
+-- Enum values cannot be dropped: move paused tickets back and recreate the type
+UPDATE tickets SET status = 'in_progress' WHERE status IN ('on_hold', 'waiting_for_requester');
+
+ALTER TYPE ticket_status RENAME TO ticket_status_old;
+CREATE TYPE ticket_status AS ENUM ('pending', 'in_progress', 'completed', 'canceled');
+ALTER TABLE tickets ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tickets ALTER COLUMN status TYPE ticket_status USING status::text::ticket_status;
+ALTER TABLE tickets ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE ticket_status_old;
@@ -0,0 +1,8 @@
+-- Paused statuses stop SLA clocks until work resumes
+ALTER TYPE ticket_status ADD VALUE IF NOT EXISTS 'on_hold';
+ALTER TYPE ticket_status ADD VALUE IF NOT EXISTS 'waiting_for_requester';
+
+-- When the SLA clock stopped; NULL while the ticket is not paused
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_paused_at ON tickets (paused_at) WHERE paused_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE tickets DROP COLUMN IF EXISTS last_reopened_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS reopen_count;
@@ -0,0 +1,3 @@
+-- Reopen tracking: how often a completed ticket came back
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS reopen_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS last_reopened_at TIMESTAMPTZ NULL;
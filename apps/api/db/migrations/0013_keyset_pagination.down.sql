@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_ticket_created;
+DROP INDEX IF EXISTS idx_tickets_list_order;
+ALTER TABLE tickets DROP COLUMN IF EXISTS priority_rank;
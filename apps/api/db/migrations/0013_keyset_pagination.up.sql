@@ -0,0 +1,8 @@
+-- Keyset pagination: the ticket list sort key as a column, and indexes
+-- matching the ticket and comment list orders
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS priority_rank SMALLINT NOT NULL GENERATED ALWAYS AS (
+  CASE priority WHEN 'P0' THEN 0 WHEN 'P1' THEN 1 WHEN 'P2' THEN 2 WHEN 'P3' THEN 3 ELSE 4 END
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_list_order ON tickets (priority_rank, updated_at DESC, effort_score, id);
+CREATE INDEX IF NOT EXISTS idx_comments_ticket_created ON comments (ticket_id, created_at DESC, id DESC);
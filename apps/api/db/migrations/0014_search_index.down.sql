@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_tickets_fulltext ON tickets USING GIN (to_tsvector('english', title || ' ' || description));
+DROP TABLE IF EXISTS search_index;
@@ -0,0 +1,105 @@
+-- Search documents for tickets (comment_id NULL) and their comments. The
+-- lexemes come from internal/search, which segments Thai text, so the API
+-- keeps this table up to date on every write; cmd/reindex rebuilds it.
+-- Existing rows are backfilled at the end of this migration.
+CREATE TABLE IF NOT EXISTS search_index (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+  comment_id UUID NULL REFERENCES comments(id) ON DELETE CASCADE,
+  document TSVECTOR NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_index_ticket ON search_index (ticket_id) WHERE comment_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_index_comment ON search_index (comment_id) WHERE comment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_search_index_document ON search_index USING GIN (document);
+
+-- The English-only expression index is superseded by search_index
+DROP INDEX IF EXISTS idx_tickets_fulltext;
+
+-- Backfill existing tickets and comments so search works as soon as the
+-- migration has run. search_document mirrors search.Document (lowercased
+-- words; Thai as clusters and cluster bigrams); cmd/reindex rebuilds the
+-- index with the Go tokenizer itself.
+CREATE FUNCTION pg_temp.search_document(body TEXT) RETURNS TEXT[] LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+  tokens TEXT[] := '{}';
+  clusters TEXT[] := '{}';
+  word TEXT := '';
+  cluster TEXT := '';
+  leading BOOLEAN := FALSE;
+  ch TEXT;
+  cp INT;
+BEGIN
+  -- A trailing space flushes the last word or Thai run
+  FOREACH ch IN ARRAY regexp_split_to_array(COALESCE(body, '') || ' ', '') LOOP
+    cp := ascii(ch);
+    -- Thai letters, U+0E01..U+0E4E except the signs ฯ and ๆ
+    IF cp BETWEEN 3585 AND 3662 AND cp NOT IN (3631, 3654) THEN
+      IF word <> '' THEN
+        tokens := tokens || word;
+        word := '';
+      END IF;
+      IF cp BETWEEN 3648 AND 3652 THEN
+        -- Leading vowels start a cluster with the consonant after them
+        IF cluster <> '' AND NOT leading THEN
+          clusters := clusters || cluster;
+          cluster := '';
+        END IF;
+        cluster := cluster || ch;
+        leading := TRUE;
+      ELSIF cp BETWEEN 3632 AND 3642 OR cp BETWEEN 3655 AND 3662 THEN
+        -- Following vowels and marks join the cluster before them
+        cluster := cluster || ch;
+      ELSE
+        IF cluster <> '' AND NOT leading THEN
+          clusters := clusters || cluster;
+          cluster := '';
+        END IF;
+        cluster := cluster || ch;
+        leading := FALSE;
+      END IF;
+      CONTINUE;
+    END IF;
+
+    IF cluster <> '' THEN
+      clusters := clusters || cluster;
+      cluster := '';
+      leading := FALSE;
+    END IF;
+    IF cardinality(clusters) > 0 THEN
+      tokens := tokens || clusters;
+      FOR i IN 1 .. cardinality(clusters) - 1 LOOP
+        tokens := tokens || (clusters[i] || clusters[i + 1]);
+      END LOOP;
+      clusters := '{}';
+    END IF;
+    IF ch ~ '[[:alnum:]]' AND cp NOT IN (3631, 3654) THEN
+      word := word || lower(ch);
+    ELSIF word <> '' THEN
+      tokens := tokens || word;
+      word := '';
+    END IF;
+  END LOOP;
+  RETURN tokens;
+END
+$$;
+
+INSERT INTO search_index (ticket_id, document)
+SELECT t.id,
+  setweight(array_to_tsvector(pg_temp.search_document(t.title)), 'A') ||
+  setweight(array_to_tsvector(pg_temp.search_document(t.description)), 'B') ||
+  setweight(array_to_tsvector(pg_temp.search_document(
+    (SELECT string_agg(v #>> '{}', E'\n')
+     FROM jsonb_path_query(t.details, 'strict $.** ? (@.type() == "string" || @.type() == "number")') v))), 'C')
+FROM tickets t
+ON CONFLICT (ticket_id) WHERE comment_id IS NULL DO NOTHING;
+
+-- System-generated comments are not indexed
+INSERT INTO search_index (ticket_id, comment_id, document)
+SELECT c.ticket_id, c.id, setweight(array_to_tsvector(pg_temp.search_document(c.body)), 'B')
+FROM comments c
+WHERE c.is_system_generated IS NOT TRUE
+ON CONFLICT (comment_id) WHERE comment_id IS NOT NULL DO NOTHING;
+
+DROP FUNCTION pg_temp.search_document(TEXT);
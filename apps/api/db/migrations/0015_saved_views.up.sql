@@ -0,0 +1,16 @@
+-- Named ticket list views: filters, sort and visible columns, owned by a
+-- user and optionally shared with everyone in a role
+CREATE TABLE IF NOT EXISTS saved_views (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+  sort TEXT NOT NULL DEFAULT '',
+  columns TEXT[] NOT NULL DEFAULT '{}',
+  shared_role user_role NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_views_owner_id ON saved_views (owner_id);
+CREATE INDEX IF NOT EXISTS idx_saved_views_shared_role ON saved_views (shared_role) WHERE shared_role IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Email notifications waiting to be sent. Rows are written in the same
+-- transaction as the change they describe and delivered by the API's
+-- background worker, which retries with backoff until max attempts.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  recipient_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+  to_address TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  ticket_id UUID NULL REFERENCES tickets(id) ON DELETE CASCADE,
+  subject TEXT NOT NULL,
+  body_text TEXT NOT NULL,
+  body_html TEXT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_error TEXT NULL,
+  sent_at TIMESTAMPTZ NULL,
+  failed_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox (next_attempt_at)
+  WHERE sent_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,18 @@
+-- How each user wants to hear about ticket activity. Users without a row
+-- get every event type by email as it happens. Quiet hours are local times
+-- in the business timezone; last_digest_at is the last daily digest time
+-- covered, so each digest is sent once across API replicas.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  channel TEXT NOT NULL DEFAULT 'email' CHECK (channel IN ('email', 'none')),
+  event_types TEXT[] NOT NULL DEFAULT '{assigned,status_changed,comment,mention}',
+  delivery TEXT NOT NULL DEFAULT 'immediate' CHECK (delivery IN ('immediate', 'digest')),
+  quiet_hours_start TIME NULL,
+  quiet_hours_end TIME NULL,
+  last_digest_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_digest ON notification_preferences (last_digest_at)
+  WHERE delivery = 'digest';
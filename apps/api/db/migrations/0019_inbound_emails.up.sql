@@ -0,0 +1,9 @@
+-- Messages accepted by the inbound email gateway, keyed by Message-ID so a
+-- message delivered twice opens one ticket or adds one comment.
+CREATE TABLE IF NOT EXISTS inbound_emails (
+  message_id TEXT PRIMARY KEY,
+  sender TEXT NOT NULL,
+  ticket_id UUID NULL REFERENCES tickets(id) ON DELETE SET NULL,
+  comment_id UUID NULL REFERENCES comments(id) ON DELETE SET NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,22 @@
+-- Users following a ticket: the audience for its notifications. Creators,
+-- assignees, commenters and mentioned users are added automatically; anyone
+-- can watch or unwatch.
+CREATE TABLE IF NOT EXISTS ticket_watchers (
+  ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (ticket_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_watchers_user ON ticket_watchers (user_id);
+
+-- Existing tickets are watched by their creators and assignees. Mentions
+-- only add watchers when MENTION_WATCH is on, which a migration cannot see,
+-- so past commenters and mentioned users are left to watch on their own.
+INSERT INTO ticket_watchers (ticket_id, user_id)
+SELECT id, created_by FROM tickets WHERE created_by IS NOT NULL
+UNION
+SELECT ticket_id, assignee_id FROM ticket_assignments
+UNION
+SELECT id, assignee_id FROM tickets WHERE assignee_id IS NOT NULL
+ON CONFLICT DO NOTHING;
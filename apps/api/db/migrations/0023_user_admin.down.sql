@@ -0,0 +1,5 @@
+DELETE FROM audit_logs WHERE ticket_id IS NULL;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_subject;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS user_id;
+ALTER TABLE audit_logs ALTER COLUMN ticket_id SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,10 @@
+-- Deactivated users can no longer sign in; their sessions are revoked and
+-- their tickets and comments keep pointing at them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ NULL;
+
+-- The audit log also records changes to users, which have no ticket
+ALTER TABLE audit_logs ALTER COLUMN ticket_id DROP NOT NULL;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS user_id UUID NULL REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_subject CHECK (ticket_id IS NOT NULL OR user_id IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user ON audit_logs (user_id, created_at) WHERE user_id IS NOT NULL;
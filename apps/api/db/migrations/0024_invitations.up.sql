@@ -0,0 +1,20 @@
+-- Managers invite an email address with a role. The invitation link carries
+-- a token signed with the server secret over the invitation id and expiry,
+-- so nothing secret is stored; accepted_at makes it work only once.
+CREATE TABLE IF NOT EXISTS invitations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  email TEXT NOT NULL,
+  role user_role NOT NULL,
+  invited_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_at TIMESTAMPTZ NULL,
+  accepted_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+  revoked_at TIMESTAMPTZ NULL
+);
+
+-- Inviting an address again replaces its open invitation
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_open_email ON invitations (email)
+  WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_invitations_created ON invitations (created_at DESC);
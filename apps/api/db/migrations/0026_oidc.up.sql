@@ -0,0 +1,5 @@
+-- Users who sign in through the OpenID Connect provider are linked to it by
+-- the provider's subject identifier. A linked user has no local password.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users (oidc_subject) WHERE oidc_subject IS NOT NULL;
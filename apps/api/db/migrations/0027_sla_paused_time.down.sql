@@ -0,0 +1 @@
+ALTER TABLE tickets DROP COLUMN IF EXISTS sla_paused_seconds;
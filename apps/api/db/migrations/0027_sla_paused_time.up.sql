@@ -0,0 +1,4 @@
+-- Business time the SLA clock of a ticket has spent paused (on_hold /
+-- waiting_for_requester), so deadlines recalculated after a priority or type
+-- change keep the extensions earlier pauses earned.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS sla_paused_seconds BIGINT NOT NULL DEFAULT 0;
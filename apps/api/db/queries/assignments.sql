@@ -0,0 +1,27 @@
+-- name: AssignUsers :exec
+INSERT INTO ticket_assignments (ticket_id, assignee_id, assigned_by)
+SELECT @ticket_id::uuid, unnest(@assignee_ids::uuid[]), sqlc.narg('assigned_by')::uuid
+ON CONFLICT (ticket_id, assignee_id) DO NOTHING;
+
+-- name: UnassignUsers :exec
+DELETE FROM ticket_assignments WHERE ticket_id = @ticket_id AND assignee_id = ANY(@assignee_ids::uuid[]);
+
+-- name: ListAssigneesByTicket :many
+-- Assignees of several tickets in one round trip, in assignment order.
+-- Tickets without ticket_assignments rows fall back to the legacy assignee_id.
+SELECT ta.ticket_id, u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at, ta.assigned_at
+FROM ticket_assignments ta
+JOIN users u ON ta.assignee_id = u.id
+WHERE ta.ticket_id = ANY(@ticket_ids::uuid[])
+UNION ALL
+SELECT t.id, u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at, t.updated_at
+FROM tickets t
+JOIN users u ON t.assignee_id = u.id
+WHERE t.id = ANY(@ticket_ids::uuid[])
+  AND NOT EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id)
+ORDER BY ticket_id, assigned_at;
+
+-- name: IsUserAssigned :one
+SELECT EXISTS(
+  SELECT 1 FROM ticket_assignments WHERE ticket_id = $1 AND assignee_id = $2
+);
@@ -0,0 +1,21 @@
+-- name: CreateAttachment :exec
+INSERT INTO attachments (ticket_id, filename, mime, size, path) VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetAttachment :one
+SELECT id, ticket_id, filename, mime, size, path, created_at FROM attachments WHERE id = $1;
+
+-- name: ListTicketAttachments :many
+SELECT id, ticket_id, filename, mime, size, path, created_at FROM attachments
+WHERE ticket_id = $1
+ORDER BY created_at ASC;
+
+-- name: CreateCommentAttachment :exec
+INSERT INTO comment_attachments (comment_id, filename, mime, size, path) VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetCommentAttachment :one
+SELECT id, comment_id, filename, mime, size, path, created_at FROM comment_attachments WHERE id = $1;
+
+-- name: ListCommentAttachments :many
+SELECT id, comment_id, filename, mime, size, path, created_at FROM comment_attachments
+WHERE comment_id = $1
+ORDER BY created_at;
@@ -0,0 +1,6 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (ticket_id, actor_id, action, after) VALUES ($1, $2, $3, $4);
+
+-- name: CreateUserAuditLog :exec
+INSERT INTO audit_logs (user_id, actor_id, action, before, after)
+VALUES (@user_id, sqlc.narg('actor_id'), @action, @before, @after);
@@ -0,0 +1,26 @@
+-- name: ListBusinessHours :many
+SELECT weekday, to_char(start_time, 'HH24:MI')::text AS start_time, to_char(end_time, 'HH24:MI')::text AS end_time, is_working_day
+FROM business_hours
+ORDER BY weekday ASC;
+
+-- name: UpsertBusinessHours :batchexec
+INSERT INTO business_hours (weekday, start_time, end_time, is_working_day)
+VALUES (@weekday, @start_time::text::time, @end_time::text::time, @is_working_day)
+ON CONFLICT (weekday)
+DO UPDATE SET start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+  is_working_day = EXCLUDED.is_working_day, updated_at = NOW();
+
+-- name: ListHolidays :many
+SELECT id, to_char(date, 'YYYY-MM-DD')::text AS date, name, created_at
+FROM holidays
+WHERE (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM date) = sqlc.narg('year'))
+ORDER BY holidays.date ASC;
+
+-- name: AddHoliday :one
+-- Adding an existing date renames its holiday
+INSERT INTO holidays (date, name) VALUES (@date::text::date, @name)
+ON CONFLICT (date) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, to_char(date, 'YYYY-MM-DD')::text AS date, name, created_at;
+
+-- name: DeleteHoliday :execrows
+DELETE FROM holidays WHERE id = $1;
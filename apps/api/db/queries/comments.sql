@@ -0,0 +1,44 @@
+-- name: CreateComment :one
+INSERT INTO comments (ticket_id, author_id, body, is_system_generated)
+VALUES (@ticket_id, @author_id, @body, @is_system_generated::boolean)
+RETURNING id;
+
+-- name: ListTicketComments :many
+SELECT c.id, c.ticket_id, c.author_id, u.name AS author_name, u.role AS author_role, c.body, c.is_system_generated, c.created_at
+FROM comments c
+LEFT JOIN users u ON c.author_id = u.id
+WHERE c.ticket_id = $1
+ORDER BY c.created_at DESC;
+
+-- name: ListTicketCommentsPage :many
+SELECT c.id, c.ticket_id, c.author_id, u.name AS author_name, u.role AS author_role, c.body, c.is_system_generated, c.created_at
+FROM comments c
+LEFT JOIN users u ON c.author_id = u.id
+WHERE c.ticket_id = $1
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListTicketCommentsAfter :many
+-- Keyset page, newest first, following the cursor row or from the newest
+-- comment when there is no cursor
+SELECT c.id, c.ticket_id, c.author_id, u.name AS author_name, u.role AS author_role, c.body, c.is_system_generated, c.created_at
+FROM comments c
+LEFT JOIN users u ON c.author_id = u.id
+WHERE c.ticket_id = @ticket_id
+  AND (sqlc.narg('cursor_id')::uuid IS NULL
+    OR (c.created_at, c.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListTicketCommentsBefore :many
+-- Keyset page preceding the cursor row, oldest first
+SELECT c.id, c.ticket_id, c.author_id, u.name AS author_name, u.role AS author_role, c.body, c.is_system_generated, c.created_at
+FROM comments c
+LEFT JOIN users u ON c.author_id = u.id
+WHERE c.ticket_id = @ticket_id
+  AND (c.created_at, c.id) > (@cursor_created_at::timestamptz, @cursor_id::uuid)
+ORDER BY c.created_at ASC, c.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: CountTicketComments :one
+SELECT COUNT(*) FROM comments WHERE ticket_id = $1;
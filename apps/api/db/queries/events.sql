@@ -0,0 +1,3 @@
+-- name: NotifyEvent :exec
+-- Delivered to listeners when the surrounding transaction commits
+SELECT pg_notify(@channel::text, @payload::text);
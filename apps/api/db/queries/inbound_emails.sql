@@ -0,0 +1,5 @@
+-- name: RecordInboundEmail :execrows
+-- Affects no rows when the message was already handled
+INSERT INTO inbound_emails (message_id, sender, ticket_id, comment_id)
+VALUES (@message_id, @sender, @ticket_id::uuid, sqlc.narg('comment_id')::uuid)
+ON CONFLICT (message_id) DO NOTHING;
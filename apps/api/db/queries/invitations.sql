@@ -0,0 +1,40 @@
+-- name: CreateInvitation :one
+INSERT INTO invitations (email, role, invited_by, expires_at)
+VALUES (@email, @role, sqlc.narg('invited_by'), @expires_at)
+RETURNING *;
+
+-- name: RevokeOpenInvitations :exec
+-- Closes an address's open invitation, expired or not, before a new one
+UPDATE invitations SET revoked_at = NOW()
+WHERE email = @email AND accepted_at IS NULL AND revoked_at IS NULL;
+
+-- name: GetInvitation :one
+SELECT * FROM invitations WHERE id = $1;
+
+-- name: ListInvitations :many
+-- A NULL status lists every invitation
+SELECT * FROM invitations
+WHERE sqlc.narg('status')::text IS NULL OR sqlc.narg('status') = CASE
+  WHEN accepted_at IS NOT NULL THEN 'accepted'
+  WHEN revoked_at IS NOT NULL THEN 'revoked'
+  WHEN expires_at <= NOW() THEN 'expired'
+  ELSE 'pending' END
+ORDER BY created_at DESC
+LIMIT @max_results OFFSET @skip;
+
+-- name: CountInvitations :one
+SELECT COUNT(*) FROM invitations
+WHERE sqlc.narg('status')::text IS NULL OR sqlc.narg('status') = CASE
+  WHEN accepted_at IS NOT NULL THEN 'accepted'
+  WHEN revoked_at IS NOT NULL THEN 'revoked'
+  WHEN expires_at <= NOW() THEN 'expired'
+  ELSE 'pending' END;
+
+-- name: RevokeInvitation :execrows
+UPDATE invitations SET revoked_at = NOW()
+WHERE id = @id AND accepted_at IS NULL AND revoked_at IS NULL;
+
+-- name: AcceptInvitation :execrows
+-- Matches only a pending invitation, so each one is redeemed once
+UPDATE invitations SET accepted_at = NOW(), accepted_by = @accepted_by
+WHERE id = @id AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW();
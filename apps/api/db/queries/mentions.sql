@@ -0,0 +1,19 @@
+-- name: CreateMention :exec
+INSERT INTO mentions (comment_id, ticket_id, user_id, mentioned_by)
+VALUES (@comment_id, @ticket_id, @user_id, sqlc.narg('mentioned_by'))
+ON CONFLICT (comment_id, user_id) DO NOTHING;
+
+-- name: ListMentions :many
+-- Newest first, with the comment and ticket each mention was made in
+SELECT m.id, m.ticket_id, t.code, t.title, m.comment_id, c.body, m.mentioned_by,
+  u.name AS mentioned_by_name, m.created_at
+FROM mentions m
+JOIN comments c ON c.id = m.comment_id
+JOIN tickets t ON t.id = m.ticket_id
+LEFT JOIN users u ON u.id = m.mentioned_by
+WHERE m.user_id = @user_id
+ORDER BY m.created_at DESC, m.id DESC
+LIMIT @max_results OFFSET @skip;
+
+-- name: CountMentions :one
+SELECT COUNT(*) FROM mentions WHERE user_id = $1;
@@ -0,0 +1,108 @@
+-- name: ListInProgressTickets :many
+-- Currently active tickets for the dashboard, most urgent first
+SELECT
+  t.id,
+  t.title,
+  t.priority,
+  t.assignee_id,
+  u.name AS assignee_name,
+  t.updated_at,
+  (SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) AS latest_comment,
+  (SELECT STRING_AGG(au.name, ', ' ORDER BY au.name)
+   FROM ticket_assignments ta
+   JOIN users au ON ta.assignee_id = au.id
+   WHERE ta.ticket_id = t.id) AS assignee_names
+FROM tickets t
+LEFT JOIN users u ON t.assignee_id = u.id
+WHERE t.status = 'in_progress'
+ORDER BY
+  CASE t.priority
+    WHEN 'P0' THEN 0
+    WHEN 'P1' THEN 1
+    WHEN 'P2' THEN 2
+    WHEN 'P3' THEN 3
+    ELSE 4
+  END ASC,
+  t.updated_at DESC,
+  t.effort_score ASC
+LIMIT 20;
+
+-- name: CountTicketsByStatus :many
+-- Counts narrow to tickets created in the given year (and month), when set
+SELECT status, COUNT(*) AS count FROM tickets
+WHERE (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM created_at) = sqlc.narg('year'))
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'))
+GROUP BY status;
+
+-- name: CountTicketsByCategory :many
+-- Category is the resolved type when classified, otherwise the initial type
+SELECT COALESCE(resolved_type::text, initial_type::text)::text AS category, COUNT(*) AS count FROM tickets
+WHERE (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM created_at) = sqlc.narg('year'))
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'))
+GROUP BY 1;
+
+-- name: CountTicketsByPriority :many
+SELECT priority, COUNT(*) AS count FROM tickets
+WHERE (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM created_at) = sqlc.narg('year'))
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'))
+GROUP BY priority;
+
+-- name: CountReopens :one
+-- A reopened ticket was completed at least once, whatever its status now
+SELECT
+  COUNT(*) FILTER (WHERE reopen_count > 0) AS reopened,
+  COUNT(*) FILTER (WHERE status = 'completed' OR reopen_count > 0) AS resolved
+FROM tickets
+WHERE (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM created_at) = sqlc.narg('year'))
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'));
+
+-- name: CountIssueReportsByClassification :many
+SELECT
+  (CASE
+    WHEN status = 'canceled' THEN 'Rejected'
+    WHEN resolved_type IS NULL THEN 'Unclassified'
+    WHEN resolved_type = 'DATA_CORRECTION' THEN 'Data Correction'
+    WHEN resolved_type = 'EMERGENCY_CHANGE' THEN 'Emergency Change'
+    ELSE 'Other'
+  END)::text AS classification,
+  COUNT(*) AS count
+FROM tickets
+WHERE initial_type = 'ISSUE_REPORT'
+  AND (sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM created_at) = sqlc.narg('year'))
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'))
+GROUP BY 1;
+
+-- name: GetSLACompliance :many
+-- Canceled tickets and tickets without SLA deadlines are excluded
+SELECT
+  EXTRACT(YEAR FROM created_at)::int AS year,
+  EXTRACT(MONTH FROM created_at)::int AS month,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE first_responded_at <= response_due_at) AS response_met,
+  COUNT(*) FILTER (WHERE first_responded_at > response_due_at
+    OR (first_responded_at IS NULL AND COALESCE(paused_at, NOW()) > response_due_at)) AS response_breached,
+  COUNT(*) FILTER (WHERE status = 'completed' AND closed_at <= resolution_due_at) AS resolution_met,
+  COUNT(*) FILTER (WHERE (status = 'completed' AND closed_at > resolution_due_at)
+    OR (status <> 'completed' AND COALESCE(paused_at, NOW()) > resolution_due_at)) AS resolution_breached
+FROM tickets
+WHERE resolution_due_at IS NOT NULL AND status <> 'canceled'
+  AND EXTRACT(YEAR FROM created_at) = @year::int
+  AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM created_at) = sqlc.narg('month'))
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+-- name: GetUserPerformanceCounts :one
+-- Effort scores sum completed tickets by the month they were last updated
+SELECT
+  (SELECT COUNT(DISTINCT t.id) FROM tickets t JOIN ticket_assignments ta ON t.id = ta.ticket_id
+    WHERE ta.assignee_id = @user_id::uuid AND t.status = 'in_progress')::int AS in_progress_count,
+  (SELECT COUNT(DISTINCT t.id) FROM tickets t JOIN ticket_assignments ta ON t.id = ta.ticket_id
+    WHERE ta.assignee_id = @user_id::uuid AND t.status = 'completed')::int AS completed_count,
+  (SELECT COUNT(*) FROM tickets WHERE status = 'in_progress')::int AS total_system_in_progress,
+  (SELECT COUNT(*) FROM tickets WHERE status = 'completed')::int AS total_system_completed,
+  (SELECT COALESCE(SUM(t.effort_score), 0) FROM tickets t JOIN ticket_assignments ta ON t.id = ta.ticket_id
+    WHERE ta.assignee_id = @user_id::uuid AND t.status = 'completed'
+      AND DATE_TRUNC('month', t.updated_at) = DATE_TRUNC('month', CURRENT_DATE))::int AS effort_score_current_month,
+  (SELECT COALESCE(SUM(t.effort_score), 0) FROM tickets t JOIN ticket_assignments ta ON t.id = ta.ticket_id
+    WHERE ta.assignee_id = @user_id::uuid AND t.status = 'completed'
+      AND DATE_TRUNC('month', t.updated_at) = DATE_TRUNC('month', CURRENT_DATE - INTERVAL '1 month'))::int AS effort_score_previous_month;
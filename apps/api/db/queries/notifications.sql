@@ -0,0 +1,103 @@
+-- name: EnqueueNotification :exec
+-- send_after holds a message back, e.g. until the recipient's quiet hours end
+INSERT INTO notification_outbox (recipient_id, to_address, kind, ticket_id, subject, body_text, body_html, next_attempt_at)
+VALUES (sqlc.narg('recipient_id'), @to_address, @kind, sqlc.narg('ticket_id'), @subject, @body_text, @body_html,
+  COALESCE(sqlc.narg('send_after'), NOW()));
+
+-- name: ClaimNotifications :many
+-- Leases due messages to one worker: they are not due again until the lease
+-- ends, so replicas never send the same message concurrently
+UPDATE notification_outbox SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => @lease_seconds::int)
+WHERE id IN (
+  SELECT o.id FROM notification_outbox o
+  WHERE o.sent_at IS NULL AND o.failed_at IS NULL AND o.next_attempt_at <= NOW()
+  ORDER BY o.next_attempt_at
+  LIMIT @batch_size
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkNotificationSent :exec
+UPDATE notification_outbox SET sent_at = NOW(), last_error = NULL WHERE id = $1;
+
+-- name: MarkNotificationFailed :exec
+-- Schedules a retry, or gives up when retry_at is NULL
+UPDATE notification_outbox SET last_error = @last_error::text,
+  next_attempt_at = COALESCE(sqlc.narg('retry_at'), next_attempt_at),
+  failed_at = CASE WHEN sqlc.narg('retry_at')::timestamptz IS NULL THEN NOW() END
+WHERE id = @id;
+
+-- name: GetNotificationPreferences :one
+SELECT user_id, channel, event_types, delivery,
+  to_char(quiet_hours_start, 'HH24:MI') AS quiet_hours_start, to_char(quiet_hours_end, 'HH24:MI') AS quiet_hours_end
+FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: UpsertNotificationPreferences :exec
+-- Switching to digests starts the first digest from now, so it does not
+-- repeat activity that was already mailed immediately
+INSERT INTO notification_preferences (user_id, channel, event_types, delivery, quiet_hours_start, quiet_hours_end, last_digest_at)
+VALUES (@user_id, @channel, @event_types::text[], @delivery,
+  sqlc.narg('quiet_hours_start')::text::time, sqlc.narg('quiet_hours_end')::text::time,
+  CASE WHEN @delivery = 'digest' THEN NOW() END)
+ON CONFLICT (user_id)
+DO UPDATE SET channel = EXCLUDED.channel, event_types = EXCLUDED.event_types, delivery = EXCLUDED.delivery,
+  quiet_hours_start = EXCLUDED.quiet_hours_start, quiet_hours_end = EXCLUDED.quiet_hours_end,
+  last_digest_at = CASE WHEN notification_preferences.delivery <> 'digest' THEN EXCLUDED.last_digest_at
+    ELSE notification_preferences.last_digest_at END,
+  updated_at = NOW();
+
+-- name: ListDigestRecipients :many
+-- Active users on daily digests whose digest for due has not been sent
+SELECT p.user_id, u.name, u.email, p.channel, p.event_types, p.delivery,
+  to_char(p.quiet_hours_start, 'HH24:MI') AS quiet_hours_start, to_char(p.quiet_hours_end, 'HH24:MI') AS quiet_hours_end,
+  p.last_digest_at
+FROM notification_preferences p
+JOIN users u ON u.id = p.user_id
+WHERE p.delivery = 'digest' AND p.channel = 'email' AND u.deactivated_at IS NULL
+  AND (p.last_digest_at IS NULL OR p.last_digest_at < @due::timestamptz)
+ORDER BY p.user_id;
+
+-- name: ClaimDigest :execrows
+-- Marks a user's digest for due as taken; only one replica gets a row back
+UPDATE notification_preferences SET last_digest_at = @due::timestamptz
+WHERE user_id = @user_id AND (last_digest_at IS NULL OR last_digest_at < @due::timestamptz);
+
+-- name: ListDigestActivity :many
+-- Activity a user hears about between since and until, oldest first:
+-- assignments of them and status changes on tickets they watch, from
+-- audit_logs, comments on tickets they watch, and comments that mention
+-- them (reported once, as a mention). Comments that only record
+-- field changes are left out: a user's comment is written in the same
+-- transaction, so at the same NOW(), as its add_comment audit entry.
+-- The user's own actions are never included.
+SELECT activity.kind::text AS kind, t.id AS ticket_id, t.code, t.title,
+  COALESCE(u.name, '')::text AS actor_name, activity.detail::text AS detail, activity.created_at
+FROM (
+  SELECT 'assigned' AS kind, a.ticket_id, a.actor_id, '' AS detail, a.created_at
+  FROM audit_logs a
+  JOIN ticket_assignments ta ON ta.ticket_id = a.ticket_id AND ta.assigned_at = a.created_at
+  WHERE a.action = 'assign' AND ta.assignee_id = @user_id
+  UNION ALL
+  SELECT 'status_changed', a.ticket_id, a.actor_id, a.after #>> '{}', a.created_at
+  FROM audit_logs a
+  WHERE a.action = 'status_change'
+    AND EXISTS (SELECT 1 FROM ticket_watchers w WHERE w.ticket_id = a.ticket_id AND w.user_id = @user_id)
+  UNION ALL
+  SELECT 'comment', c.ticket_id, c.author_id, c.body, c.created_at
+  FROM comments c
+  WHERE EXISTS (SELECT 1 FROM ticket_watchers w WHERE w.ticket_id = c.ticket_id AND w.user_id = @user_id)
+    AND EXISTS (SELECT 1 FROM audit_logs a
+      WHERE a.ticket_id = c.ticket_id AND a.action = 'add_comment' AND a.created_at = c.created_at)
+    AND NOT EXISTS (SELECT 1 FROM mentions m WHERE m.comment_id = c.id AND m.user_id = @user_id)
+  UNION ALL
+  SELECT 'mention', m.ticket_id, m.mentioned_by, mc.body, m.created_at
+  FROM mentions m
+  JOIN comments mc ON mc.id = m.comment_id
+  WHERE m.user_id = @user_id
+) activity
+JOIN tickets t ON t.id = activity.ticket_id
+LEFT JOIN users u ON u.id = activity.actor_id
+WHERE activity.created_at >= @since AND activity.created_at < @until
+  AND activity.actor_id IS DISTINCT FROM @user_id::uuid
+ORDER BY activity.created_at, activity.kind;
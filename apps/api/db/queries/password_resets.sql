@@ -0,0 +1,13 @@
+-- name: CreatePasswordReset :exec
+INSERT INTO password_resets (user_id, token_hash, expires_at)
+VALUES (@user_id, @token_hash, @expires_at);
+
+-- name: UsePasswordReset :one
+-- Consumes a live reset token and returns its user; a used or expired
+-- token matches nothing
+DELETE FROM password_resets
+WHERE token_hash = @token_hash AND expires_at > NOW()
+RETURNING user_id;
+
+-- name: ClearPasswordResets :exec
+DELETE FROM password_resets WHERE user_id = @user_id;
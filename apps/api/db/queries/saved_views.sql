@@ -0,0 +1,23 @@
+-- name: ListSavedViews :many
+-- Views the user owns plus views shared with their role
+SELECT * FROM saved_views
+WHERE owner_id = @user_id OR shared_role = @role::user_role
+ORDER BY name, created_at;
+
+-- name: GetSavedView :one
+SELECT * FROM saved_views WHERE id = $1;
+
+-- name: CreateSavedView :one
+INSERT INTO saved_views (owner_id, name, filters, sort, columns, shared_role)
+VALUES (@owner_id, @name, @filters, @sort, @columns, sqlc.narg('shared_role'))
+RETURNING *;
+
+-- name: UpdateSavedView :one
+-- Only the owner can change a view
+UPDATE saved_views SET name = @name, filters = @filters, sort = @sort, columns = @columns,
+  shared_role = sqlc.narg('shared_role'), updated_at = NOW()
+WHERE id = @id AND owner_id = @owner_id
+RETURNING *;
+
+-- name: DeleteSavedView :execrows
+DELETE FROM saved_views WHERE id = @id AND owner_id = @owner_id;
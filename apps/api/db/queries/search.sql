@@ -0,0 +1,44 @@
+-- name: UpsertTicketSearch :exec
+-- Lexemes come from search.Document; title matches rank above the
+-- description, and both above the details
+INSERT INTO search_index (ticket_id, document)
+VALUES (@ticket_id,
+  setweight(array_to_tsvector(@title::text[]), 'A') ||
+  setweight(array_to_tsvector(@description::text[]), 'B') ||
+  setweight(array_to_tsvector(@details::text[]), 'C'))
+ON CONFLICT (ticket_id) WHERE comment_id IS NULL
+DO UPDATE SET document = EXCLUDED.document, updated_at = NOW();
+
+-- name: UpsertCommentSearch :exec
+INSERT INTO search_index (ticket_id, comment_id, document)
+VALUES (@ticket_id, @comment_id::uuid, setweight(array_to_tsvector(@body::text[]), 'B'))
+ON CONFLICT (comment_id) WHERE comment_id IS NOT NULL
+DO UPDATE SET document = EXCLUDED.document, updated_at = NOW();
+
+-- name: SearchTickets :many
+-- @query is tsquery text built by search.Query
+SELECT t.id, t.code, t.title, t.description, t.details, t.status, t.priority, t.updated_at,
+  ts_rank(s.document, @query::text::tsquery)::float8 AS score
+FROM search_index s
+JOIN tickets t ON t.id = s.ticket_id
+WHERE s.comment_id IS NULL AND s.document @@ @query::text::tsquery
+ORDER BY score DESC, t.updated_at DESC
+LIMIT @max_results;
+
+-- name: SearchComments :many
+SELECT c.id, c.ticket_id, t.code AS ticket_code, t.title AS ticket_title, c.author_id, u.name AS author_name,
+  c.body, c.created_at, ts_rank(s.document, @query::text::tsquery)::float8 AS score
+FROM search_index s
+JOIN comments c ON c.id = s.comment_id
+JOIN tickets t ON t.id = c.ticket_id
+LEFT JOIN users u ON u.id = c.author_id
+WHERE s.document @@ @query::text::tsquery
+ORDER BY score DESC, c.created_at DESC
+LIMIT @max_results;
+
+-- name: ListTicketsForSearch :many
+SELECT id, title, description, details FROM tickets ORDER BY created_at;
+
+-- name: ListCommentsForSearch :many
+-- System-generated comments are not indexed
+SELECT id, ticket_id, body FROM comments WHERE is_system_generated IS NOT TRUE ORDER BY created_at;
@@ -0,0 +1,41 @@
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, refresh_hash, user_agent, ip, expires_at)
+VALUES (@user_id, @refresh_hash, @user_agent, @ip, @expires_at)
+RETURNING *;
+
+-- name: RotateSession :one
+-- Swaps a live session's refresh token for a new one; a token that was
+-- already rotated, revoked or has expired matches nothing
+UPDATE sessions SET refresh_hash = @new_hash, expires_at = @expires_at, last_used_at = NOW()
+WHERE refresh_hash = @refresh_hash AND revoked_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: IsSessionActive :one
+SELECT EXISTS (
+  SELECT 1 FROM sessions s
+  JOIN users u ON u.id = s.user_id
+  WHERE s.id = @id AND s.revoked_at IS NULL AND s.expires_at > NOW() AND u.deactivated_at IS NULL
+);
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE user_id = @user_id AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeSession :execrows
+UPDATE sessions SET revoked_at = NOW()
+WHERE id = @id AND user_id = @user_id AND revoked_at IS NULL;
+
+-- name: RevokeSessionByRefreshHash :execrows
+UPDATE sessions SET revoked_at = NOW()
+WHERE refresh_hash = @refresh_hash AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :execrows
+-- Signs a user out everywhere, or everywhere but except_id
+UPDATE sessions SET revoked_at = NOW()
+WHERE user_id = @user_id AND revoked_at IS NULL
+  AND (sqlc.narg('except_id')::uuid IS NULL OR id <> sqlc.narg('except_id'));
+
+-- name: PruneUserSessions :exec
+-- Drops a user's revoked and expired sessions, which nothing reads again
+DELETE FROM sessions WHERE user_id = @user_id AND (revoked_at IS NOT NULL OR expires_at <= NOW());
@@ -0,0 +1,14 @@
+-- name: ListSLAPolicies :many
+SELECT id, priority, initial_type, response_minutes, resolution_minutes, created_at, updated_at
+FROM sla_policies
+ORDER BY priority ASC, initial_type ASC NULLS FIRST;
+
+-- name: UpsertSLAPolicy :one
+INSERT INTO sla_policies (priority, initial_type, response_minutes, resolution_minutes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (priority, initial_type)
+DO UPDATE SET response_minutes = EXCLUDED.response_minutes, resolution_minutes = EXCLUDED.resolution_minutes, updated_at = NOW()
+RETURNING id, priority, initial_type, response_minutes, resolution_minutes, created_at, updated_at;
+
+-- name: DeleteSLAPolicy :execrows
+DELETE FROM sla_policies WHERE id = $1;
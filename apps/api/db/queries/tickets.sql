@@ -1,41 +1,249 @@
 -- name: CreateTicket :one
-INSERT INTO tickets (created_by, initial_type, status, title, description, details, impact_score, urgency_score, final_score, red_flag, priority)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
-RETURNING *;
+INSERT INTO tickets (
+  created_by, initial_type, status, title, description, details,
+  impact_score, urgency_score, final_score, red_flag, priority,
+  red_flags_data, impact_assessment_data, urgency_timeline_data, effort_data, effort_score,
+  response_due_at, resolution_due_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+)
+RETURNING id, code, created_at, updated_at;
+
+-- name: GetTicket :one
+SELECT sqlc.embed(t),
+  (SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) AS latest_comment
+FROM tickets t
+WHERE t.id = $1;
 
 -- name: ListTickets :many
-SELECT * FROM tickets ORDER BY 
-	CASE priority 
-		WHEN 'P0' THEN 0 
-		WHEN 'P1' THEN 1 
-		WHEN 'P2' THEN 2 
-		WHEN 'P3' THEN 3 
-		ELSE 4 
-	END ASC, 
-	updated_at DESC, 
-	effort_score ASC 
-LIMIT $1 OFFSET $2;
+-- Filters are optional: a NULL parameter or empty list disables its condition.
+-- Enum lists are compared as text; date ranges include from and exclude to.
+-- The assignee filter matches both ticket_assignments and the legacy assignee_id.
+-- The query is tsquery text from search.Query, matched against search_index.
+-- sort is one of the column keys in repositories.TicketSorts, "-" prefixed
+-- for descending; every order falls back to the default list order.
+-- ListTicketsAfter, ListTicketsBefore and CountTickets repeat this WHERE
+-- clause; TestTicketQueries_SameFilters keeps the copies identical.
+SELECT sqlc.embed(t),
+  (SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) AS latest_comment
+FROM tickets t
+WHERE (COALESCE(cardinality(@statuses::text[]), 0) = 0 OR t.status::text = ANY(@statuses::text[]))
+  AND (COALESCE(cardinality(@priorities::text[]), 0) = 0 OR t.priority::text = ANY(@priorities::text[]))
+  AND (COALESCE(cardinality(@initial_types::text[]), 0) = 0 OR t.initial_type::text = ANY(@initial_types::text[]))
+  AND (COALESCE(cardinality(@resolved_types::text[]), 0) = 0 OR t.resolved_type::text = ANY(@resolved_types::text[]))
+  AND (sqlc.narg('assignee_id')::uuid IS NULL OR t.assignee_id = sqlc.narg('assignee_id')
+    OR EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id AND ta.assignee_id = sqlc.narg('assignee_id')))
+  AND (NOT @unassigned::boolean OR (t.assignee_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id)))
+  AND (sqlc.narg('created_by')::uuid IS NULL OR t.created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('red_flag')::boolean IS NULL OR t.red_flag = sqlc.narg('red_flag'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR t.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR t.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('updated_from')::timestamptz IS NULL OR t.updated_at >= sqlc.narg('updated_from'))
+  AND (sqlc.narg('updated_to')::timestamptz IS NULL OR t.updated_at < sqlc.narg('updated_to'))
+  AND (sqlc.narg('closed_from')::timestamptz IS NULL OR t.closed_at >= sqlc.narg('closed_from'))
+  AND (sqlc.narg('closed_to')::timestamptz IS NULL OR t.closed_at < sqlc.narg('closed_to'))
+  AND (sqlc.narg('effort_min')::smallint IS NULL OR t.effort_score >= sqlc.narg('effort_min'))
+  AND (sqlc.narg('effort_max')::smallint IS NULL OR t.effort_score <= sqlc.narg('effort_max'))
+  AND (sqlc.narg('query')::text IS NULL OR EXISTS (SELECT 1 FROM search_index s
+    WHERE s.ticket_id = t.id AND s.comment_id IS NULL AND s.document @@ sqlc.narg('query')::tsquery))
+  AND (sqlc.narg('watcher_id')::uuid IS NULL OR EXISTS (SELECT 1 FROM ticket_watchers w
+    WHERE w.ticket_id = t.id AND w.user_id = sqlc.narg('watcher_id')))
+ORDER BY
+  CASE WHEN @sort::text = 'created_at' THEN t.created_at END ASC,
+  CASE WHEN @sort::text = '-created_at' THEN t.created_at END DESC,
+  CASE WHEN @sort::text = 'updated_at' THEN t.updated_at END ASC,
+  CASE WHEN @sort::text = '-updated_at' THEN t.updated_at END DESC,
+  CASE WHEN @sort::text = 'closed_at' THEN t.closed_at END ASC NULLS LAST,
+  CASE WHEN @sort::text = '-closed_at' THEN t.closed_at END DESC NULLS LAST,
+  CASE WHEN @sort::text = 'resolution_due_at' THEN t.resolution_due_at END ASC NULLS LAST,
+  CASE WHEN @sort::text = '-resolution_due_at' THEN t.resolution_due_at END DESC NULLS LAST,
+  CASE WHEN @sort::text = 'code' THEN t.code END ASC,
+  CASE WHEN @sort::text = '-code' THEN t.code END DESC,
+  CASE WHEN @sort::text = 'effort_score' THEN t.effort_score END ASC,
+  CASE WHEN @sort::text = '-effort_score' THEN t.effort_score END DESC,
+  CASE WHEN @sort::text = 'final_score' THEN t.final_score END ASC,
+  CASE WHEN @sort::text = '-final_score' THEN t.final_score END DESC,
+  CASE WHEN @sort::text = '-priority_rank' THEN t.priority_rank END DESC,
+  t.priority_rank ASC, t.updated_at DESC, t.effort_score ASC, t.id ASC
+OFFSET sqlc.arg('offset') LIMIT sqlc.arg('limit');
 
--- name: GetTicket :one
-SELECT * FROM tickets WHERE id=$1;
+-- name: ListTicketsAfter :many
+-- Keyset page in list order following the cursor row, or the first page
+-- when there is no cursor. Takes the same filters as ListTickets.
+SELECT sqlc.embed(t),
+  (SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) AS latest_comment
+FROM tickets t
+WHERE (COALESCE(cardinality(@statuses::text[]), 0) = 0 OR t.status::text = ANY(@statuses::text[]))
+  AND (COALESCE(cardinality(@priorities::text[]), 0) = 0 OR t.priority::text = ANY(@priorities::text[]))
+  AND (COALESCE(cardinality(@initial_types::text[]), 0) = 0 OR t.initial_type::text = ANY(@initial_types::text[]))
+  AND (COALESCE(cardinality(@resolved_types::text[]), 0) = 0 OR t.resolved_type::text = ANY(@resolved_types::text[]))
+  AND (sqlc.narg('assignee_id')::uuid IS NULL OR t.assignee_id = sqlc.narg('assignee_id')
+    OR EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id AND ta.assignee_id = sqlc.narg('assignee_id')))
+  AND (NOT @unassigned::boolean OR (t.assignee_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id)))
+  AND (sqlc.narg('created_by')::uuid IS NULL OR t.created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('red_flag')::boolean IS NULL OR t.red_flag = sqlc.narg('red_flag'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR t.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR t.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('updated_from')::timestamptz IS NULL OR t.updated_at >= sqlc.narg('updated_from'))
+  AND (sqlc.narg('updated_to')::timestamptz IS NULL OR t.updated_at < sqlc.narg('updated_to'))
+  AND (sqlc.narg('closed_from')::timestamptz IS NULL OR t.closed_at >= sqlc.narg('closed_from'))
+  AND (sqlc.narg('closed_to')::timestamptz IS NULL OR t.closed_at < sqlc.narg('closed_to'))
+  AND (sqlc.narg('effort_min')::smallint IS NULL OR t.effort_score >= sqlc.narg('effort_min'))
+  AND (sqlc.narg('effort_max')::smallint IS NULL OR t.effort_score <= sqlc.narg('effort_max'))
+  AND (sqlc.narg('query')::text IS NULL OR EXISTS (SELECT 1 FROM search_index s
+    WHERE s.ticket_id = t.id AND s.comment_id IS NULL AND s.document @@ sqlc.narg('query')::tsquery))
+  AND (sqlc.narg('watcher_id')::uuid IS NULL OR EXISTS (SELECT 1 FROM ticket_watchers w
+    WHERE w.ticket_id = t.id AND w.user_id = sqlc.narg('watcher_id')))
+  AND (sqlc.narg('cursor_id')::uuid IS NULL
+    OR t.priority_rank > sqlc.narg('cursor_rank')::smallint
+    OR (t.priority_rank = sqlc.narg('cursor_rank')::smallint AND (t.updated_at < sqlc.narg('cursor_updated_at')::timestamptz
+      OR (t.updated_at = sqlc.narg('cursor_updated_at')::timestamptz AND (t.effort_score > sqlc.narg('cursor_effort')::smallint
+        OR (t.effort_score = sqlc.narg('cursor_effort')::smallint AND t.id > sqlc.narg('cursor_id')::uuid))))))
+ORDER BY t.priority_rank ASC, t.updated_at DESC, t.effort_score ASC, t.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListTicketsBefore :many
+-- Keyset page preceding the cursor row, in reverse list order so the rows
+-- nearest the cursor come first.
+SELECT sqlc.embed(t),
+  (SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) AS latest_comment
+FROM tickets t
+WHERE (COALESCE(cardinality(@statuses::text[]), 0) = 0 OR t.status::text = ANY(@statuses::text[]))
+  AND (COALESCE(cardinality(@priorities::text[]), 0) = 0 OR t.priority::text = ANY(@priorities::text[]))
+  AND (COALESCE(cardinality(@initial_types::text[]), 0) = 0 OR t.initial_type::text = ANY(@initial_types::text[]))
+  AND (COALESCE(cardinality(@resolved_types::text[]), 0) = 0 OR t.resolved_type::text = ANY(@resolved_types::text[]))
+  AND (sqlc.narg('assignee_id')::uuid IS NULL OR t.assignee_id = sqlc.narg('assignee_id')
+    OR EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id AND ta.assignee_id = sqlc.narg('assignee_id')))
+  AND (NOT @unassigned::boolean OR (t.assignee_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id)))
+  AND (sqlc.narg('created_by')::uuid IS NULL OR t.created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('red_flag')::boolean IS NULL OR t.red_flag = sqlc.narg('red_flag'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR t.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR t.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('updated_from')::timestamptz IS NULL OR t.updated_at >= sqlc.narg('updated_from'))
+  AND (sqlc.narg('updated_to')::timestamptz IS NULL OR t.updated_at < sqlc.narg('updated_to'))
+  AND (sqlc.narg('closed_from')::timestamptz IS NULL OR t.closed_at >= sqlc.narg('closed_from'))
+  AND (sqlc.narg('closed_to')::timestamptz IS NULL OR t.closed_at < sqlc.narg('closed_to'))
+  AND (sqlc.narg('effort_min')::smallint IS NULL OR t.effort_score >= sqlc.narg('effort_min'))
+  AND (sqlc.narg('effort_max')::smallint IS NULL OR t.effort_score <= sqlc.narg('effort_max'))
+  AND (sqlc.narg('query')::text IS NULL OR EXISTS (SELECT 1 FROM search_index s
+    WHERE s.ticket_id = t.id AND s.comment_id IS NULL AND s.document @@ sqlc.narg('query')::tsquery))
+  AND (sqlc.narg('watcher_id')::uuid IS NULL OR EXISTS (SELECT 1 FROM ticket_watchers w
+    WHERE w.ticket_id = t.id AND w.user_id = sqlc.narg('watcher_id')))
+  AND (t.priority_rank < @cursor_rank::smallint
+    OR (t.priority_rank = @cursor_rank::smallint AND (t.updated_at > @cursor_updated_at::timestamptz
+      OR (t.updated_at = @cursor_updated_at::timestamptz AND (t.effort_score < @cursor_effort::smallint
+        OR (t.effort_score = @cursor_effort::smallint AND t.id < @cursor_id::uuid))))))
+ORDER BY t.priority_rank DESC, t.updated_at ASC, t.effort_score DESC, t.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountTickets :one
+SELECT COUNT(*) FROM tickets t
+WHERE (COALESCE(cardinality(@statuses::text[]), 0) = 0 OR t.status::text = ANY(@statuses::text[]))
+  AND (COALESCE(cardinality(@priorities::text[]), 0) = 0 OR t.priority::text = ANY(@priorities::text[]))
+  AND (COALESCE(cardinality(@initial_types::text[]), 0) = 0 OR t.initial_type::text = ANY(@initial_types::text[]))
+  AND (COALESCE(cardinality(@resolved_types::text[]), 0) = 0 OR t.resolved_type::text = ANY(@resolved_types::text[]))
+  AND (sqlc.narg('assignee_id')::uuid IS NULL OR t.assignee_id = sqlc.narg('assignee_id')
+    OR EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id AND ta.assignee_id = sqlc.narg('assignee_id')))
+  AND (NOT @unassigned::boolean OR (t.assignee_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id)))
+  AND (sqlc.narg('created_by')::uuid IS NULL OR t.created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('red_flag')::boolean IS NULL OR t.red_flag = sqlc.narg('red_flag'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR t.created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR t.created_at < sqlc.narg('created_to'))
+  AND (sqlc.narg('updated_from')::timestamptz IS NULL OR t.updated_at >= sqlc.narg('updated_from'))
+  AND (sqlc.narg('updated_to')::timestamptz IS NULL OR t.updated_at < sqlc.narg('updated_to'))
+  AND (sqlc.narg('closed_from')::timestamptz IS NULL OR t.closed_at >= sqlc.narg('closed_from'))
+  AND (sqlc.narg('closed_to')::timestamptz IS NULL OR t.closed_at < sqlc.narg('closed_to'))
+  AND (sqlc.narg('effort_min')::smallint IS NULL OR t.effort_score >= sqlc.narg('effort_min'))
+  AND (sqlc.narg('effort_max')::smallint IS NULL OR t.effort_score <= sqlc.narg('effort_max'))
+  AND (sqlc.narg('query')::text IS NULL OR EXISTS (SELECT 1 FROM search_index s
+    WHERE s.ticket_id = t.id AND s.comment_id IS NULL AND s.document @@ sqlc.narg('query')::tsquery))
+  AND (sqlc.narg('watcher_id')::uuid IS NULL OR EXISTS (SELECT 1 FROM ticket_watchers w
+    WHERE w.ticket_id = t.id AND w.user_id = sqlc.narg('watcher_id')));
 
--- name: UpdateTicket :exec
-UPDATE tickets SET title=COALESCE($2,title), description=COALESCE($3,description), details=COALESCE($4,details), updated_at=NOW() WHERE id=$1;
+-- name: GetTicketIDByCode :one
+SELECT id FROM tickets WHERE code = $1;
+
+-- name: GetTicketInitialType :one
+SELECT initial_type FROM tickets WHERE id = $1;
+
+-- name: UpdateTicket :one
+-- Returns the searchable fields so the search document can be rebuilt
+UPDATE tickets SET
+  title = COALESCE(sqlc.narg('title')::text, title),
+  description = COALESCE(sqlc.narg('description')::text, description),
+  details = COALESCE(sqlc.narg('details')::jsonb, details),
+  updated_at = NOW()
+WHERE id = @id
+RETURNING title, description, details;
 
 -- name: AssignTicket :exec
-UPDATE tickets SET assignee_id=$2, updated_at=NOW() WHERE id=$1;
+UPDATE tickets SET assignee_id = $1, updated_at = NOW() WHERE id = $2;
+
+-- name: ChangeStatus :execrows
+-- Any move out of pending counts as the first response for SLA purposes.
+-- paused_at marks when the SLA clock stopped for on_hold / waiting_for_requester.
+-- The update only applies while the ticket still has the status the workflow checked.
+UPDATE tickets SET status = @status, closed_at = @closed_at,
+  first_responded_at = CASE WHEN @status::ticket_status <> 'pending' THEN COALESCE(first_responded_at, NOW()) ELSE first_responded_at END,
+  paused_at = CASE WHEN @status::ticket_status IN ('on_hold', 'waiting_for_requester') THEN COALESCE(paused_at, NOW()) ELSE NULL END,
+  updated_at = NOW()
+WHERE id = @id AND status = @from_status;
+
+-- name: ReopenTicket :one
+-- A reopened ticket owes a new response: first_responded_at is cleared and set
+-- again by its next status change or assignment.
+UPDATE tickets SET status = 'in_progress', closed_at = NULL, paused_at = NULL, sla_paused_seconds = 0,
+  first_responded_at = NULL,
+  reopen_count = reopen_count + 1, last_reopened_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'completed'
+RETURNING reopen_count;
 
--- name: ChangeStatus :exec
-UPDATE tickets SET status=$2, updated_at=NOW() WHERE id=$1;
+-- name: ListIdlePausedTickets :many
+SELECT t.id FROM tickets t
+WHERE t.status IN ('on_hold', 'waiting_for_requester') AND t.paused_at IS NOT NULL
+  AND GREATEST(t.paused_at, COALESCE((SELECT MAX(c.created_at) FROM comments c WHERE c.ticket_id = t.id), t.paused_at)) < @before::timestamptz
+ORDER BY t.paused_at ASC;
+
+-- name: AddSLAPausedTime :exec
+UPDATE tickets SET sla_paused_seconds = sla_paused_seconds + @seconds::bigint WHERE id = @id;
+
+-- name: UpdateSLADueDates :exec
+UPDATE tickets SET response_due_at = $1, resolution_due_at = $2 WHERE id = $3;
 
 -- name: UpdateTicketFields :exec
-UPDATE tickets SET 
-  initial_type=COALESCE($2,initial_type),
-  resolved_type=COALESCE($3,resolved_type),
-  priority=COALESCE($4,priority),
-  impact_score=COALESCE($5,impact_score),
-  urgency_score=COALESCE($6,urgency_score),
-  final_score=COALESCE($7,final_score),
-  red_flag=COALESCE($8,red_flag),
-  updated_at=NOW() 
-WHERE id=$1;
\ No newline at end of file
+UPDATE tickets SET
+  initial_type = COALESCE(sqlc.narg('initial_type')::ticket_initial_type, initial_type),
+  resolved_type = COALESCE(sqlc.narg('resolved_type')::ticket_resolved_type, resolved_type),
+  priority = COALESCE(sqlc.narg('priority')::ticket_priority, priority),
+  impact_score = COALESCE(sqlc.narg('impact_score')::smallint, impact_score),
+  urgency_score = COALESCE(sqlc.narg('urgency_score')::smallint, urgency_score),
+  final_score = COALESCE(sqlc.narg('final_score')::smallint, final_score),
+  red_flag = COALESCE(sqlc.narg('red_flag')::boolean, red_flag),
+  updated_at = NOW()
+WHERE id = @id;
+
+-- name: ClassifyTicket :exec
+UPDATE tickets SET resolved_type = @resolved_type::ticket_resolved_type, updated_at = NOW() WHERE id = @id;
+
+-- name: UpdateRedFlagsData :exec
+UPDATE tickets SET red_flags_data = $1, updated_at = NOW() WHERE id = $2;
+
+-- name: UpdateImpactAssessmentData :exec
+UPDATE tickets SET impact_assessment_data = $1, updated_at = NOW() WHERE id = $2;
+
+-- name: UpdateUrgencyTimelineData :exec
+UPDATE tickets SET urgency_timeline_data = $1, updated_at = NOW() WHERE id = $2;
+
+-- name: UpdateEffort :exec
+UPDATE tickets SET effort_data = $1, effort_score = $2, updated_at = NOW() WHERE id = $3;
+
+-- name: TouchTicket :exec
+UPDATE tickets SET updated_at = NOW() WHERE id = $1;
+
+-- name: MarkTicketAssigned :exec
+-- The first assignment also counts as the first response
+UPDATE tickets SET first_responded_at = COALESCE(first_responded_at, NOW()), updated_at = NOW() WHERE id = $1;
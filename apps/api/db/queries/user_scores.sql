@@ -0,0 +1,40 @@
+-- name: AwardPoints :exec
+INSERT INTO user_scores (user_id, ticket_id, points)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, ticket_id)
+DO UPDATE SET points = EXCLUDED.points, awarded_at = NOW();
+
+-- name: RemovePoints :exec
+DELETE FROM user_scores WHERE user_id = $1 AND ticket_id = $2;
+
+-- name: RemoveTicketPoints :exec
+DELETE FROM user_scores WHERE ticket_id = $1;
+
+-- name: ListUserRankings :many
+-- Points only count for tickets created in the given year (and month), when set.
+SELECT
+  u.id,
+  u.name,
+  u.email,
+  u.role,
+  u.profile_picture,
+  COALESCE(SUM(us.points), 0)::float8 AS total_points,
+  COUNT(us.ticket_id) AS tickets_completed,
+  ROW_NUMBER() OVER (ORDER BY COALESCE(SUM(us.points), 0) DESC, u.name ASC) AS rank
+FROM users u
+LEFT JOIN user_scores us ON u.id = us.user_id
+LEFT JOIN tickets t ON us.ticket_id = t.id
+WHERE t.id IS NULL
+  OR ((sqlc.narg('year')::int IS NULL OR EXTRACT(YEAR FROM t.created_at) = sqlc.narg('year'))
+    AND (sqlc.narg('month')::int IS NULL OR EXTRACT(MONTH FROM t.created_at) = sqlc.narg('month')))
+GROUP BY u.id, u.name, u.email, u.role
+ORDER BY total_points DESC, u.name ASC
+LIMIT @max_results;
+
+-- name: GetUserTotalPoints :one
+SELECT COALESCE(SUM(points), 0)::float8 AS total_points FROM user_scores WHERE user_id = $1;
+
+-- name: ListTicketPoints :many
+SELECT id, user_id, ticket_id, points, awarded_at FROM user_scores
+WHERE ticket_id = $1
+ORDER BY awarded_at DESC;
@@ -5,4 +5,65 @@ SELECT * FROM users WHERE email = $1;
 SELECT * FROM users WHERE id = $1;
 
 -- name: CreateUser :exec
-INSERT INTO users (name, email, role, password_hash) VALUES ($1,$2,$3,$4);
\ No newline at end of file
+INSERT INTO users (name, email, role, password_hash) VALUES ($1, $2, $3, $4);
+
+-- name: UpdateUserProfile :exec
+UPDATE users SET name = $1, email = $2, updated_at = NOW() WHERE id = $3;
+
+-- name: UpdateUserProfilePicture :exec
+UPDATE users SET profile_picture = $1, updated_at = NOW() WHERE id = $2;
+
+-- name: SearchUsers :many
+-- A NULL pattern matches every user; an empty roles array matches every role.
+-- Deactivated users are left out.
+SELECT id, name, email, role, profile_picture, created_at, updated_at
+FROM users
+WHERE (sqlc.narg('pattern')::text IS NULL OR name ILIKE sqlc.narg('pattern') OR email ILIKE sqlc.narg('pattern'))
+  AND (cardinality(@roles::text[]) = 0 OR role::text = ANY(@roles::text[]))
+  AND deactivated_at IS NULL
+ORDER BY name ASC
+LIMIT @max_results;
+
+-- name: UpdateUserRole :execrows
+UPDATE users SET role = @role, updated_at = NOW() WHERE id = @id;
+
+-- name: ListUsers :many
+-- The admin user list. A NULL pattern, role or active matches everyone;
+-- active picks users without (true) or with (false) deactivated_at.
+SELECT id, name, email, role, profile_picture, created_at, updated_at, deactivated_at
+FROM users
+WHERE (sqlc.narg('pattern')::text IS NULL OR name ILIKE sqlc.narg('pattern') OR email ILIKE sqlc.narg('pattern'))
+  AND (sqlc.narg('role')::user_role IS NULL OR role = sqlc.narg('role'))
+  AND (sqlc.narg('active')::boolean IS NULL OR (deactivated_at IS NULL) = sqlc.narg('active'))
+ORDER BY name ASC, id ASC
+LIMIT @max_results OFFSET @skip;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE (sqlc.narg('pattern')::text IS NULL OR name ILIKE sqlc.narg('pattern') OR email ILIKE sqlc.narg('pattern'))
+  AND (sqlc.narg('role')::user_role IS NULL OR role = sqlc.narg('role'))
+  AND (sqlc.narg('active')::boolean IS NULL OR (deactivated_at IS NULL) = sqlc.narg('active'));
+
+-- name: SetUserDeactivated :execrows
+-- Deactivates or reactivates a user; a user already in that state is left
+-- alone and counts as no row
+UPDATE users SET deactivated_at = CASE WHEN @deactivated::boolean THEN NOW() END, updated_at = NOW()
+WHERE id = @id AND (deactivated_at IS NOT NULL) <> @deactivated::boolean;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users SET password_hash = @password_hash, updated_at = NOW() WHERE id = @id;
+
+-- name: GetUserByOIDCSubject :one
+SELECT * FROM users WHERE oidc_subject = $1;
+
+-- name: CreateOIDCUser :one
+-- Provisions a user on their first single sign-on; they get no password
+INSERT INTO users (name, email, role, password_hash, oidc_subject)
+VALUES (@name, @email, @role, '', @oidc_subject)
+RETURNING *;
+
+-- name: LinkUserOIDCSubject :execrows
+-- Links an existing account to the provider and drops its password; an
+-- account already linked counts as no row
+UPDATE users SET oidc_subject = @oidc_subject, password_hash = '', updated_at = NOW()
+WHERE id = @id AND oidc_subject IS NULL;
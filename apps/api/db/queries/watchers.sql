@@ -0,0 +1,12 @@
+-- name: WatchTicket :exec
+INSERT INTO ticket_watchers (ticket_id, user_id) VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: UnwatchTicket :execrows
+DELETE FROM ticket_watchers WHERE ticket_id = $1 AND user_id = $2;
+
+-- name: ListTicketWatchers :many
+SELECT user_id FROM ticket_watchers WHERE ticket_id = $1 ORDER BY created_at, user_id;
+
+-- name: IsWatchingTicket :one
+SELECT EXISTS (SELECT 1 FROM ticket_watchers WHERE ticket_id = $1 AND user_id = $2);
@@ -0,0 +1,69 @@
+-- name: ListWebhooks :many
+SELECT * FROM webhooks ORDER BY created_at ASC;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks WHERE id = $1;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (url, description, events, secret, active, created_by)
+VALUES (@url, @description, @events::text[], @secret, @active, sqlc.narg('created_by'))
+RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks SET url = @url, description = @description, events = @events::text[], secret = @secret,
+  active = @active, updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: EnqueueWebhookDeliveries :execrows
+-- Queues an event for every active subscription that wants it
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+SELECT w.id, @event::text, @payload::jsonb
+FROM webhooks w
+WHERE w.active AND (cardinality(w.events) = 0 OR @event::text = ANY(w.events));
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases due deliveries of active subscriptions to one worker, with the
+-- subscription's current URL and secret
+UPDATE webhook_deliveries d SET attempts = d.attempts + 1,
+  next_attempt_at = NOW() + make_interval(secs => @lease_seconds::int)
+FROM webhooks w
+WHERE w.id = d.webhook_id AND d.id IN (
+  SELECT q.id FROM webhook_deliveries q
+  JOIN webhooks qw ON qw.id = q.webhook_id
+  WHERE qw.active AND q.delivered_at IS NULL AND q.failed_at IS NULL AND q.next_attempt_at <= NOW()
+  ORDER BY q.next_attempt_at
+  LIMIT @batch_size
+  FOR UPDATE OF q SKIP LOCKED
+)
+RETURNING d.id, d.event, d.payload, d.attempts, w.url, w.secret;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries SET delivered_at = NOW(), response_status = @response_status::int,
+  response_body = @response_body::text, last_error = NULL, duration_ms = @duration_ms::int
+WHERE id = @id;
+
+-- name: MarkWebhookFailed :exec
+-- Records a failed attempt and schedules a retry, or gives up when retry_at is NULL
+UPDATE webhook_deliveries SET response_status = sqlc.narg('response_status')::int,
+  response_body = sqlc.narg('response_body')::text, last_error = @last_error::text, duration_ms = @duration_ms::int,
+  next_attempt_at = COALESCE(sqlc.narg('retry_at'), next_attempt_at),
+  failed_at = CASE WHEN sqlc.narg('retry_at')::timestamptz IS NULL THEN NOW() END
+WHERE id = @id;
+
+-- name: ListWebhookDeliveries :many
+-- The delivery log of one subscription, newest first
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = @webhook_id
+ORDER BY created_at DESC, id DESC
+LIMIT @max_results;
+
+-- name: RedeliverWebhookDelivery :one
+-- Queues a delivery's payload again as a new delivery
+INSERT INTO webhook_deliveries (webhook_id, event, payload, redelivery_of)
+SELECT webhook_id, event, payload, id FROM webhook_deliveries
+WHERE id = @id AND webhook_id = @webhook_id
+RETURNING *;
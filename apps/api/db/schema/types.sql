@@ -0,0 +1,16 @@
+-- Enum types for sqlc only; never applied to a database.
+-- 0001_init creates these inside DO blocks so it can be re-run, and sqlc does
+-- not look inside DO blocks. Values added by later migrations are declared
+-- there with ALTER TYPE ... ADD VALUE.
+CREATE TYPE user_role AS ENUM ('Anonymous', 'User', 'Supervisor', 'Manager');
+CREATE TYPE ticket_status AS ENUM ('pending', 'in_progress', 'completed', 'canceled');
+CREATE TYPE ticket_initial_type AS ENUM (
+  'ISSUE_REPORT',
+  'CHANGE_REQUEST_NORMAL',
+  'SERVICE_REQUEST_DATA_CORRECTION',
+  'SERVICE_REQUEST_DATA_EXTRACTION',
+  'SERVICE_REQUEST_ADVISORY',
+  'SERVICE_REQUEST_GENERAL'
+);
+CREATE TYPE ticket_resolved_type AS ENUM ('EMERGENCY_CHANGE', 'DATA_CORRECTION');
+CREATE TYPE ticket_priority AS ENUM ('P0', 'P1', 'P2', 'P3');
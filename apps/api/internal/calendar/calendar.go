@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"time"
+)
+
+// DefaultTimezone is the team's working timezone.
+const DefaultTimezone = "Asia/Bangkok"
+
+// maxScanDays bounds how far Add searches for working time, so a calendar
+// with every day marked as a holiday cannot loop forever.
+const maxScanDays = 3660
+
+// Window is a working period within a day, as offsets from midnight.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Calendar describes when the team works: a weekly schedule plus holidays.
+// Time outside working windows does not count towards SLA clocks.
+type Calendar struct {
+	Location *time.Location
+	// Week holds the working window per weekday; nil means a day off.
+	Week [7]*Window
+	// Holidays maps dates (YYYY-MM-DD, in Location) to a holiday name.
+	Holidays map[string]string
+}
+
+// LoadLocation returns the named location, falling back to UTC+7 when the
+// timezone database is not available (e.g. in minimal containers).
+func LoadLocation(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.FixedZone("ICT", 7*3600)
+}
+
+// Default returns Thai office hours: Monday to Friday, 08:30-17:30.
+func Default(loc *time.Location) *Calendar {
+	c := &Calendar{Location: loc, Holidays: map[string]string{}}
+	office := &Window{Start: 8*time.Hour + 30*time.Minute, End: 17*time.Hour + 30*time.Minute}
+	for d := time.Monday; d <= time.Friday; d++ {
+		c.Week[d] = office
+	}
+	return c
+}
+
+// ParseTimeOfDay parses a local time of day in HH:MM form into an offset
+// from midnight. "24:00" is accepted as the end of the day.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// DateKey formats a time as the holiday key for its date in loc.
+func DateKey(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+func (c *Calendar) hasWorkingDays() bool {
+	for _, w := range c.Week {
+		if w != nil && w.End > w.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the working window on the day of t, if it is a working day.
+func (c *Calendar) window(t time.Time) (time.Time, time.Time, bool) {
+	t = t.In(c.Location)
+	w := c.Week[t.Weekday()]
+	if w == nil || w.End <= w.Start {
+		return time.Time{}, time.Time{}, false
+	}
+	if _, holiday := c.Holidays[t.Format("2006-01-02")]; holiday {
+		return time.Time{}, time.Time{}, false
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.Location)
+	return midnight.Add(w.Start), midnight.Add(w.End), true
+}
+
+func nextMidnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// IsWorkingTime reports whether t falls inside a working window.
+func (c *Calendar) IsWorkingTime(t time.Time) bool {
+	start, end, ok := c.window(t)
+	return ok && !t.Before(start) && t.Before(end)
+}
+
+// IsWorkingDay reports whether the date of t is a working day.
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	_, _, ok := c.window(t)
+	return ok
+}
+
+// Add returns the instant at which d of working time has elapsed after start.
+// A calendar without any working days behaves like a wall clock.
+func (c *Calendar) Add(start time.Time, d time.Duration) time.Time {
+	if d <= 0 || !c.hasWorkingDays() {
+		return start.Add(d)
+	}
+	t := start
+	remaining := d
+	for i := 0; i < maxScanDays; i++ {
+		if ws, we, ok := c.window(t); ok {
+			from := t
+			if from.Before(ws) {
+				from = ws
+			}
+			if from.Before(we) {
+				avail := we.Sub(from)
+				if remaining <= avail {
+					return from.Add(remaining)
+				}
+				remaining -= avail
+			}
+		}
+		t = nextMidnight(t, c.Location)
+	}
+	return start.Add(d)
+}
+
+// Between returns the working time elapsed between from and to.
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if !c.hasWorkingDays() {
+		return to.Sub(from)
+	}
+	var total time.Duration
+	for t := from; t.Before(to); t = nextMidnight(t, c.Location) {
+		ws, we, ok := c.window(t)
+		if !ok {
+			continue
+		}
+		s, e := ws, we
+		if from.After(s) {
+			s = from
+		}
+		if to.Before(e) {
+			e = to
+		}
+		if e.After(s) {
+			total += e.Sub(s)
+		}
+	}
+	return total
+}
+
+// BusinessDaysUntil counts working days after the date of from up to and
+// including the date of to. A deadline today or in the past counts as 0, and
+// only the first maxScanDays days are looked at, so a far-off deadline cannot
+// make it loop for long.
+func (c *Calendar) BusinessDaysUntil(from, to time.Time) int {
+	if !c.hasWorkingDays() {
+		return 0
+	}
+	days := 0
+	end := DateKey(to, c.Location)
+	t := nextMidnight(from, c.Location)
+	for i := 0; i < maxScanDays && DateKey(t, c.Location) <= end; i++ {
+		if c.IsWorkingDay(t) {
+			days++
+		}
+		t = nextMidnight(t, c.Location)
+	}
+	return days
+}
@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddAndBetween(t *testing.T) {
+	loc := time.FixedZone("ICT", 7*3600)
+	c := Default(loc)
+	c.Holidays["2025-04-14"] = "Songkran"
+
+	// Inside working hours: plain addition
+	start := time.Date(2025, 4, 8, 9, 0, 0, 0, loc) // Tuesday
+	if got := c.Add(start, 2*time.Hour); !got.Equal(time.Date(2025, 4, 8, 11, 0, 0, 0, loc)) {
+		t.Fatalf("expected 11:00 same day, got %v", got)
+	}
+
+	// Crossing the end of day carries over to the next morning
+	start = time.Date(2025, 4, 8, 16, 30, 0, 0, loc)
+	if got := c.Add(start, 2*time.Hour); !got.Equal(time.Date(2025, 4, 9, 9, 30, 0, 0, loc)) {
+		t.Fatalf("expected 09:30 next day, got %v", got)
+	}
+
+	// Created on Friday evening, clock starts Monday morning
+	start = time.Date(2025, 4, 4, 20, 0, 0, 0, loc)
+	if got := c.Add(start, 30*time.Minute); !got.Equal(time.Date(2025, 4, 7, 9, 0, 0, 0, loc)) {
+		t.Fatalf("expected Monday 09:00, got %v", got)
+	}
+
+	// Holidays are skipped
+	start = time.Date(2025, 4, 11, 17, 0, 0, 0, loc) // Friday before Songkran Monday
+	if got := c.Add(start, time.Hour); !got.Equal(time.Date(2025, 4, 15, 9, 0, 0, 0, loc)) {
+		t.Fatalf("expected Tuesday 09:00 after holiday, got %v", got)
+	}
+
+	// Between is the inverse of Add
+	from := time.Date(2025, 4, 4, 16, 0, 0, 0, loc)
+	to := c.Add(from, 5*time.Hour)
+	if got := c.Between(from, to); got != 5*time.Hour {
+		t.Fatalf("expected 5h of working time, got %v", got)
+	}
+
+	// A weekend contributes nothing
+	if got := c.Between(time.Date(2025, 4, 5, 0, 0, 0, 0, loc), time.Date(2025, 4, 7, 0, 0, 0, 0, loc)); got != 0 {
+		t.Fatalf("expected 0 over weekend, got %v", got)
+	}
+}
+
+func TestWallClockFallback(t *testing.T) {
+	c := &Calendar{Location: time.UTC}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := c.Add(start, 3*time.Hour); !got.Equal(start.Add(3 * time.Hour)) {
+		t.Fatalf("calendar without working days should act as wall clock, got %v", got)
+	}
+	if got := c.Between(start, start.Add(3*time.Hour)); got != 3*time.Hour {
+		t.Fatalf("expected 3h, got %v", got)
+	}
+}
+
+func TestBusinessDaysUntil(t *testing.T) {
+	loc := time.FixedZone("ICT", 7*3600)
+	c := Default(loc)
+	friday := time.Date(2025, 4, 4, 10, 0, 0, 0, loc)
+
+	if got := c.BusinessDaysUntil(friday, friday); got != 0 {
+		t.Fatalf("same day should be 0, got %d", got)
+	}
+	if got := c.BusinessDaysUntil(friday, time.Date(2025, 4, 7, 12, 0, 0, 0, loc)); got != 1 {
+		t.Fatalf("Friday to Monday should be 1, got %d", got)
+	}
+	c.Holidays["2025-04-07"] = "Chakri Day"
+	if got := c.BusinessDaysUntil(friday, time.Date(2025, 4, 8, 12, 0, 0, 0, loc)); got != 1 {
+		t.Fatalf("holiday Monday should be skipped, got %d", got)
+	}
+
+	// Far-off deadlines stop counting after maxScanDays days
+	farOff := time.Date(9999, 12, 31, 0, 0, 0, 0, loc)
+	if got := c.BusinessDaysUntil(friday, farOff); got <= 0 || got > maxScanDays {
+		t.Fatalf("expected a bounded count, got %d", got)
+	}
+	if got := (&Calendar{Location: loc}).BusinessDaysUntil(friday, farOff); got != 0 {
+		t.Fatalf("a calendar without working days should count 0, got %d", got)
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	if d, err := ParseTimeOfDay("08:30"); err != nil || d != 8*time.Hour+30*time.Minute {
+		t.Fatalf("expected 8h30m, got %v (%v)", d, err)
+	}
+	if d, err := ParseTimeOfDay("24:00"); err != nil || d != 24*time.Hour {
+		t.Fatalf("expected 24h, got %v (%v)", d, err)
+	}
+	if _, err := ParseTimeOfDay("8.30"); err == nil {
+		t.Fatalf("expected error for malformed time")
+	}
+}
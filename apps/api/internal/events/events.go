@@ -0,0 +1,170 @@
+// Package events fans ticket changes out to live clients.
+//
+// Writers publish an Event with pg_notify on the Channel, inside the
+// transaction that made the change, so an event is only delivered once its
+// change has committed. Every API replica runs a Bus that LISTENs on the
+// channel and hands each notification to its own subscribers, which is how
+// a client connected to one replica sees changes made through another.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Channel is the Postgres notification channel events travel on
+const Channel = "ticket_events"
+
+// Event types
+const (
+	TicketCreated       = "ticket.created"
+	TicketUpdated       = "ticket.updated"
+	TicketAssigned      = "ticket.assigned"
+	TicketUnassigned    = "ticket.unassigned"
+	TicketStatusChanged = "ticket.status_changed"
+	TicketReopened      = "ticket.reopened"
+	CommentCreated      = "comment.created"
+	TicketClassified    = "ticket.classified"
+)
+
+// Event tells clients that a ticket changed; they fetch what they need
+// rather than receiving the ticket itself, which keeps notifications well
+// under Postgres' 8000-byte payload limit.
+type Event struct {
+	Type     string  `json:"type"`
+	TicketID string  `json:"ticketId"`
+	ActorID  *string `json:"actorId,omitempty"`
+	// Assignees are the users assigned to the ticket after the change, plus
+	// any the change unassigned
+	Assignees []string       `json:"assignees"`
+	Data      map[string]any `json:"data,omitempty"`
+	At        time.Time      `json:"at"`
+}
+
+// Filter selects the events a subscriber receives; zero fields match all
+type Filter struct {
+	TicketID string
+	// AssigneeID keeps events of tickets assigned to this user
+	AssigneeID string
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.TicketID != "" && e.TicketID != f.TicketID {
+		return false
+	}
+	if f.AssigneeID != "" && !slices.Contains(e.Assignees, f.AssigneeID) {
+		return false
+	}
+	return true
+}
+
+// bufferSize is how many events a subscriber may fall behind by before
+// further events are dropped for it
+const bufferSize = 64
+
+// Subscription receives matching events on C until Close
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	bus    *Bus
+}
+
+// Close stops delivery and closes C
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.c)
+	}
+}
+
+// Bus delivers the events of the notification channel to local subscribers
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[*Subscription]struct{}{}}
+}
+
+func (b *Bus) Subscribe(f Filter) *Subscription {
+	c := make(chan Event, bufferSize)
+	s := &Subscription{C: c, c: c, filter: f, bus: b}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Dispatch hands e to every matching subscriber without blocking; a
+// subscriber whose buffer is full misses the event.
+func (b *Bus) Dispatch(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+		}
+	}
+}
+
+// handle dispatches one notification payload
+func (b *Bus) handle(payload string) {
+	var e Event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		log.Warn().Err(err).Msg("ignoring malformed ticket event")
+		return
+	}
+	b.Dispatch(e)
+}
+
+// Listen holds a pool connection listening on Channel and dispatches its
+// notifications until ctx is done, reconnecting after failures. Events
+// published while it reconnects are lost; clients refetch on reconnect.
+func (b *Bus) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	for {
+		err := b.listen(ctx, pool)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("ticket event listener stopped, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (b *Bus) listen(ctx context.Context, pool *pgxpool.Pool) error {
+	pc, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A connection that was listening must not go back to the pool
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		b.handle(n.Payload)
+	}
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Match(t *testing.T) {
+	e := Event{Type: CommentCreated, TicketID: "t1", Assignees: []string{"u1", "u2"}}
+
+	assert.True(t, Filter{}.Match(e))
+	assert.True(t, Filter{TicketID: "t1"}.Match(e))
+	assert.False(t, Filter{TicketID: "t2"}.Match(e))
+	assert.True(t, Filter{AssigneeID: "u2"}.Match(e))
+	assert.False(t, Filter{AssigneeID: "u3"}.Match(e))
+	assert.False(t, Filter{TicketID: "t1", AssigneeID: "u3"}.Match(e))
+}
+
+func TestBus_Dispatch(t *testing.T) {
+	b := NewBus()
+	all := b.Subscribe(Filter{})
+	mine := b.Subscribe(Filter{AssigneeID: "u1"})
+	defer all.Close()
+	defer mine.Close()
+
+	b.Dispatch(Event{Type: TicketCreated, TicketID: "t1"})
+	b.Dispatch(Event{Type: TicketAssigned, TicketID: "t1", Assignees: []string{"u1"}})
+
+	require.Len(t, all.C, 2)
+	require.Len(t, mine.C, 1)
+	assert.Equal(t, TicketAssigned, (<-mine.C).Type)
+}
+
+func TestBus_SlowSubscriberDropsEvents(t *testing.T) {
+	b := NewBus()
+	s := b.Subscribe(Filter{})
+	defer s.Close()
+	for i := 0; i < bufferSize+10; i++ {
+		b.Dispatch(Event{Type: TicketUpdated, TicketID: "t1"})
+	}
+	assert.Len(t, s.C, bufferSize)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	b := NewBus()
+	s := b.Subscribe(Filter{})
+	s.Close()
+	s.Close()
+	_, ok := <-s.C
+	assert.False(t, ok)
+	// Dispatching after Close must not panic on the closed channel
+	b.Dispatch(Event{Type: TicketUpdated})
+}
+
+func TestBus_Handle(t *testing.T) {
+	b := NewBus()
+	s := b.Subscribe(Filter{TicketID: "t1"})
+	defer s.Close()
+
+	b.handle(`not json`)
+	b.handle(`{"type":"ticket.status_changed","ticketId":"t1","assignees":["u1"],"data":{"status":"completed"},"at":"2025-03-01T09:00:00Z"}`)
+
+	require.Len(t, s.C, 1)
+	e := <-s.C
+	assert.Equal(t, TicketStatusChanged, e.Type)
+	assert.Equal(t, []string{"u1"}, e.Assignees)
+	assert.Equal(t, "completed", e.Data["status"])
+}
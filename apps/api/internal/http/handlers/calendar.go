@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/priority"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Business Calendar --------------------
+
+// businessCalendar loads the weekly schedule and holidays used by SLA clocks.
+// If the schedule cannot be loaded the default Thai office hours are used.
+func (h *Handlers) businessCalendar(ctx context.Context) *calendar.Calendar {
+	loc := calendar.LoadLocation(h.cfg.BusinessTimezone)
+	hours, err := h.repo.Calendar.GetWeeklySchedule(ctx)
+	if err != nil || len(hours) == 0 {
+		return calendar.Default(loc)
+	}
+
+	cal := &calendar.Calendar{Location: loc, Holidays: map[string]string{}}
+	for _, bh := range hours {
+		if !bh.IsWorkingDay || bh.Weekday < 0 || bh.Weekday > 6 {
+			continue
+		}
+		start, err1 := calendar.ParseTimeOfDay(bh.StartTime)
+		end, err2 := calendar.ParseTimeOfDay(bh.EndTime)
+		if err1 != nil || err2 != nil || end <= start {
+			continue
+		}
+		cal.Week[bh.Weekday] = &calendar.Window{Start: start, End: end}
+	}
+
+	holidays, err := h.repo.Calendar.ListHolidays(ctx, nil)
+	if err == nil {
+		for _, hd := range holidays {
+			cal.Holidays[hd.Date] = hd.Name
+		}
+	}
+	return cal
+}
+
+// applyDeadline converts a concrete deadline into an urgency bucket by counting
+// business days, so a deadline after a long weekend is not treated as distant.
+func applyDeadline(cal *calendar.Calendar, in *priority.PriorityInput, now time.Time) {
+	if in == nil || in.Deadline == nil {
+		return
+	}
+	in.Urgency = priority.UrgencyForBusinessDays(cal.BusinessDaysUntil(now, *in.Deadline))
+}
+
+type BusinessHoursReq struct {
+	Hours []models.BusinessHours `json:"hours"`
+}
+
+type HolidayReq struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+func (h *Handlers) CalendarGet(c *fiber.Ctx) error {
+	ctx := context.Background()
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_PARAMETERS", "message": "invalid year"}})
+		}
+		year = y
+	}
+
+	hours, err := h.repo.Calendar.GetWeeklySchedule(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load business hours"}})
+	}
+	holidays, err := h.repo.Calendar.ListHolidays(ctx, &year)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load holidays"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{
+		"timezone": h.cfg.BusinessTimezone,
+		"hours":    hours,
+		"holidays": holidays,
+	}))
+}
+
+func (h *Handlers) CalendarUpdateHours(c *fiber.Ctx) error {
+	var body BusinessHoursReq
+	if err := c.BodyParser(&body); err != nil || len(body.Hours) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	seen := map[int]bool{}
+	for _, bh := range body.Hours {
+		if bh.Weekday < 0 || bh.Weekday > 6 || seen[bh.Weekday] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "weekday must be 0-6 and appear once"}})
+		}
+		seen[bh.Weekday] = true
+		start, err1 := calendar.ParseTimeOfDay(bh.StartTime)
+		end, err2 := calendar.ParseTimeOfDay(bh.EndTime)
+		if err1 != nil || err2 != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "startTime and endTime must be HH:MM"}})
+		}
+		if end <= start {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "endTime must be after startTime"}})
+		}
+	}
+
+	ctx := context.Background()
+	if err := h.repo.Calendar.UpsertBusinessHours(ctx, body.Hours); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save business hours"}})
+	}
+	hours, err := h.repo.Calendar.GetWeeklySchedule(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load business hours"}})
+	}
+	return c.JSON(h.envelope(hours))
+}
+
+func (h *Handlers) HolidaysList(c *fiber.Ctx) error {
+	ctx := context.Background()
+	var year *int
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_PARAMETERS", "message": "invalid year"}})
+		}
+		year = &y
+	}
+	holidays, err := h.repo.Calendar.ListHolidays(ctx, year)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list holidays"}})
+	}
+	return c.JSON(h.envelope(holidays))
+}
+
+func (h *Handlers) HolidaysCreate(c *fiber.Ctx) error {
+	var body HolidayReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if _, err := time.Parse("2006-01-02", body.Date); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "date must be YYYY-MM-DD"}})
+	}
+	if body.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "name is required"}})
+	}
+
+	ctx := context.Background()
+	holiday, err := h.repo.Calendar.AddHoliday(ctx, body.Date, body.Name)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save holiday"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(holiday))
+}
+
+func (h *Handlers) HolidaysDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ctx := context.Background()
+	if err := h.repo.Calendar.DeleteHoliday(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "holiday not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to delete holiday"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id}))
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/events"
+)
+
+// -------------------- Events --------------------
+
+// heartbeatInterval keeps idle streams alive through proxies and lets the
+// server notice clients that went away
+const heartbeatInterval = 25 * time.Second
+
+// RunEventBus delivers ticket events published by any replica to this
+// replica's streams until ctx is done.
+func (h *Handlers) RunEventBus(ctx context.Context) {
+	h.bus.Listen(ctx, h.pool)
+}
+
+// Events streams ticket events as Server-Sent Events. ticketId limits the
+// stream to one ticket and mine=true to tickets assigned to the caller.
+// Events carry ids, not content: clients refetch what changed, and refetch
+// everything after reconnecting since missed events are not replayed.
+func (h *Handlers) Events(c *fiber.Ctx) error {
+	userID, _ := currentUser(c)
+	filter := events.Filter{TicketID: c.Query("ticketId")}
+	if v := c.Query("mine"); v != "" {
+		mine, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid mine"}})
+		}
+		if mine {
+			filter.AssigneeID = userID
+		}
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	// Tell nginx not to buffer the stream
+	c.Set("X-Accel-Buffering", "no")
+
+	sub := h.bus.Subscribe(filter)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		for {
+			if err := w.Flush(); err != nil {
+				return
+			}
+			select {
+			case e, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				writeEvent(w, e)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+		}
+	})
+	return nil
+}
+
+// writeEvent writes e as one SSE message named after its type
+func writeEvent(w *bufio.Writer, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
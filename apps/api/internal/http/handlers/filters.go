@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Ticket Filters --------------------
+
+// ticketFilters reads the ticket list filters from the query string. List
+// filters take repeated or comma-separated values. Dates are RFC 3339 times
+// or YYYY-MM-DD days in the business timezone; a day used as the end of a
+// range includes the whole day.
+func (h *Handlers) ticketFilters(c *fiber.Ctx) (repositories.TicketFilters, error) {
+	f := repositories.TicketFilters{
+		AssigneeID: c.Query("assigneeId"),
+		CreatedBy:  c.Query("createdBy"),
+		Query:      c.Query("q"),
+		Sort:       c.Query("sort"),
+	}
+	f.Statuses = enumList[models.TicketStatus](c, "status")
+	f.Priorities = enumList[models.TicketPriority](c, "priority")
+	f.InitialTypes = enumList[models.TicketInitialType](c, "initialType")
+	f.ResolvedTypes = enumList[models.TicketResolvedType](c, "resolvedType")
+
+	var err error
+	if v := c.Query("unassigned"); v != "" {
+		if f.Unassigned, err = strconv.ParseBool(v); err != nil {
+			return f, fmt.Errorf("invalid unassigned %q", v)
+		}
+	}
+	if v := c.Query("watching"); v != "" {
+		if f.Watching, err = strconv.ParseBool(v); err != nil {
+			return f, fmt.Errorf("invalid watching %q", v)
+		}
+	}
+	if v := c.Query("redFlag"); v != "" {
+		redFlag, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid redFlag %q", v)
+		}
+		f.RedFlag = &redFlag
+	}
+
+	loc := calendar.LoadLocation(h.cfg.BusinessTimezone)
+	for _, d := range []struct {
+		key string
+		end bool
+		dst **time.Time
+	}{
+		{"createdFrom", false, &f.CreatedFrom}, {"createdTo", true, &f.CreatedTo},
+		{"updatedFrom", false, &f.UpdatedFrom}, {"updatedTo", true, &f.UpdatedTo},
+		{"closedFrom", false, &f.ClosedFrom}, {"closedTo", true, &f.ClosedTo},
+	} {
+		if *d.dst, err = dateParam(c.Query(d.key), loc, d.end); err != nil {
+			return f, fmt.Errorf("invalid %s %q", d.key, c.Query(d.key))
+		}
+	}
+
+	for _, e := range []struct {
+		key string
+		dst **int
+	}{{"effortMin", &f.EffortMin}, {"effortMax", &f.EffortMax}} {
+		if v := c.Query(e.key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s %q", e.key, v)
+			}
+			*e.dst = &n
+		}
+	}
+	return f, f.Validate()
+}
+
+// queryList collects a query parameter given repeatedly or comma-separated
+func queryList(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, v := range strings.Split(string(raw), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func enumList[T ~string](c *fiber.Ctx, key string) []T {
+	var values []T
+	for _, v := range queryList(c, key) {
+		values = append(values, T(v))
+	}
+	return values
+}
+
+// dateParam parses an RFC 3339 time or a YYYY-MM-DD day in loc. A day that
+// ends a range becomes the start of the next day, since ranges exclude
+// their end.
+func dateParam(v string, loc *time.Location, end bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, loc)
+	if err != nil {
+		return nil, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
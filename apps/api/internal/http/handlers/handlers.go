@@ -20,11 +20,15 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/it-tms/apps/api/internal/events"
 	"github.com/it-tms/apps/api/internal/http/middleware"
 	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
 	"github.com/it-tms/apps/api/internal/priority"
 	"github.com/it-tms/apps/api/internal/effort"
 	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/internal/sla"
+	"github.com/it-tms/apps/api/internal/workflow"
 	"github.com/it-tms/apps/api/pkg/config"
 )
 
@@ -32,10 +36,13 @@ type Handlers struct {
 	cfg  config.Config
 	pool *pgxpool.Pool
 	repo *repositories.Repo
+	bus  *events.Bus
+	// sso is nil while single sign-on is off
+	sso *ssoSettings
 }
 
 func New(pool *pgxpool.Pool, cfg config.Config) *Handlers {
-	return &Handlers{cfg: cfg, pool: pool, repo: repositories.New(pool)}
+	return &Handlers{cfg: cfg, pool: pool, repo: repositories.New(pool), bus: events.NewBus(), sso: newSSO(cfg)}
 }
 
 func (h *Handlers) envelope(data any) any {
@@ -53,19 +60,35 @@ type SignInReq struct {
 	Password string `json:"password"`
 }
 
+// SignUpReq registers a User; roles are only given out by Managers
 type SignUpReq struct {
-	Name     string      `json:"name"`
-	Email    string      `json:"email"`
-	Password string      `json:"password"`
-	Role     models.Role `json:"role"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// validate applies the rules UsersCreate and invitations use to a self sign-up
+func (r *SignUpReq) validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Email = strings.TrimSpace(r.Email)
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if err := validateEmail(r.Email); err != nil {
+		return err
+	}
+	return validatePassword(r.Password)
 }
 
-func (h *Handlers) issueJWT(user models.User) (string, error) {
+// issueJWT signs a short-lived access token for one of user's sessions;
+// the session ID is its jti, which AuthRequired checks is not revoked
+func (h *Handlers) issueJWT(user models.User, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email,
 		"role":  user.Role,
-		"exp":   time.Now().Add(7 * 24 * time.Hour).Unix(),
+		"jti":   sessionID,
+		"exp":   time.Now().Add(h.cfg.AccessTokenTTL).Unix(),
 		"iat":   time.Now().Unix(),
 	}
 	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -80,7 +103,7 @@ func (h *Handlers) setAuthCookie(c *fiber.Ctx, token string) {
 		Secure:   h.cfg.SecureCookies,
 		Path:     "/",
 		SameSite: "Lax",
-		MaxAge:   7 * 24 * 3600,
+		MaxAge:   int(h.cfg.AccessTokenTTL.Seconds()),
 	})
 }
 
@@ -97,35 +120,66 @@ func (h *Handlers) SignIn(c *fiber.Ctx) error {
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)) != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code":"UNAUTHORIZED","message":"invalid credentials"}})
 	}
-	tok, err := h.issueJWT(user)
+	if user.DeactivatedAt != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code":"FORBIDDEN","message":"account is deactivated"}})
+	}
+	tokens, err := h.startSession(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to sign token"}})
 	}
-	h.setAuthCookie(c, tok)
-	return c.JSON(h.envelope(fiber.Map{"token": tok}))
+	return c.JSON(h.envelope(tokens))
 }
 
 func (h *Handlers) SignUp(c *fiber.Ctx) error {
+	if h.cfg.SignupMode == "invite" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code":"FORBIDDEN","message":"sign-up is by invitation only"}})
+	}
 	var body SignUpReq
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"invalid payload"}})
 	}
-	hash, _ := bcrypt.GenerateFromPassword([]byte(body.Password), 12)
-	u := models.User{
-		Name: body.Name, Email: body.Email, Role: body.Role, PasswordHash: string(hash),
+	if err := body.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":err.Error()}})
+	}
+	hash, err := hashPassword(body.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to hash password"}})
 	}
 	ctx := context.Background()
-	if err := h.repo.Users.Create(ctx, u); err != nil {
+	var user models.User
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if _, err := tx.Users.GetByEmail(ctx, body.Email); err == nil {
+			return errEmailTaken
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		if err := tx.Users.Create(ctx, models.User{Name: body.Name, Email: body.Email, Role: models.RoleUser, PasswordHash: hash}); err != nil {
+			return err
+		}
+		if user, err = tx.Users.GetByEmail(ctx, body.Email); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, user.ID, &user.ID, "sign_up", nil, auditedUser(user))
+	})
+	if errors.Is(err, errEmailTaken) {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code":"CONFLICT","message":"email already exists"}})
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to sign up"}})
+	}
 	// Sign in immediately
-	user, _ := h.repo.Users.GetByEmail(ctx, body.Email)
-	tok, _ := h.issueJWT(user)
-	h.setAuthCookie(c, tok)
-	return c.Status(fiber.StatusCreated).JSON(h.envelope(fiber.Map{"token": tok}))
+	tokens, err := h.startSession(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to sign token"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(tokens))
 }
 
 func (h *Handlers) SignOut(c *fiber.Ctx) error {
+	// Revoke the session so copies of its tokens stop working too
+	if err := h.endSession(c); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to sign out"}})
+	}
 	// Clear the auth cookie by setting it to expire immediately
 	c.Cookie(&fiber.Cookie{
 		Name:     "token",
@@ -221,6 +275,7 @@ func (h *Handlers) PriorityCompute(c *fiber.Ctx) error {
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"invalid payload"}})
 	}
+	applyDeadline(h.businessCalendar(context.Background()), &input, time.Now())
 	out := priority.Compute(input)
 	return c.JSON(h.envelope(out))
 }
@@ -275,8 +330,10 @@ func (h *Handlers) TicketsCreate(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code":"FORBIDDEN","message":"invalid role"}})
 	}
 
+	ctx := context.Background()
 	impact, urgency, final, red, prio := 0,0,0,false, models.PriorityP3
 	if body.PriorityInput != nil {
+		applyDeadline(h.businessCalendar(ctx), body.PriorityInput, time.Now())
 		p := priority.Compute(*body.PriorityInput)
 		impact, urgency, final = p.Impact, p.Urgency, p.Final
 		red = p.RedFlag
@@ -330,11 +387,27 @@ func (h *Handlers) TicketsCreate(c *fiber.Ctx) error {
 		EffortData: effortData,
 		EffortScore: int32(effortScore),
 	}
-	ctx := context.Background()
-	if err := h.repo.Tickets.Create(ctx, &t); err != nil {
+	// Stamp SLA deadlines from the resolved policy
+	due, ok, err := h.slaDueDates(ctx, t.Priority, t.InitialType, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to create"}})
+	}
+	if ok {
+		t.ResponseDueAt = &due.Response
+		t.ResolutionDueAt = &due.Resolution
+	}
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.Create(ctx, &t); err != nil {
+			return err
+		}
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketCreated, TicketID: t.ID, ActorID: createdBy}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, t.ID, createdBy, "create_ticket", nil, t)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to create"}})
 	}
-	h.repo.Audits.Insert(ctx, t.ID, createdBy, "create_ticket", nil, t)
 	return c.Status(fiber.StatusCreated).JSON(h.envelope(t))
 }
 
@@ -345,26 +418,61 @@ func (h *Handlers) TicketsList(c *fiber.Ctx) error {
 	if pageSize > 100 { pageSize = 100 }
 	offset := (page - 1) * pageSize
 
-	filters := repositories.TicketFilters{
-		Status:      c.Query("status"),
-		Priority:    c.Query("priority"),
-		AssigneeID:  c.Query("assigneeId"),
-		CreatedBy:   c.Query("createdBy"),
-		Query:       c.Query("q"),
+	filters, err := h.ticketFilters(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":err.Error()}})
 	}
 
 	ctx := context.Background()
+
+	// A saved view supplies the filters and sort; query parameters refine it
+	if viewID := c.Query("view"); viewID != "" {
+		view, err := h.visibleView(ctx, c, viewID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"view not found"}})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to load view"}})
+		}
+		filters = view.TicketFilters().Merge(filters)
+	}
+	// watching=true lists the caller's own watched tickets
+	if filters.Watching {
+		userID, _ := currentUser(c)
+		if userID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code":"UNAUTHORIZED","message":"sign in to list watched tickets"}})
+		}
+		filters.Watcher = userID
+	}
+
+	// Keyset mode: any cursor parameter, empty for the first page, replaces
+	// page numbers and skips the total count
+	if c.Context().QueryArgs().Has("cursor") {
+		cur, err := queryCursor(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"invalid cursor"}})
+		}
+		if filters.Sort != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"sort is not supported with cursor pagination"}})
+		}
+		items, cursors, err := h.repo.Tickets.ListByCursor(ctx, filters, cur, pageSize)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to list"}})
+		}
+		h.prepareListItems(ctx, items)
+		return c.JSON(fiber.Map{
+			"data": items,
+			"pageSize": pageSize,
+			"nextCursor": cursors.NextCursor,
+			"prevCursor": cursors.PrevCursor,
+		})
+	}
+
 	items, total, err := h.repo.Tickets.List(ctx, filters, offset, pageSize)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to list"}})
 	}
-	
-	// Convert profile picture paths to URLs for all assignees
-	for i := range items {
-		for j := range items[i].Assignees {
-			items[i].Assignees[j].ProfilePicture = h.convertProfilePictureToURL(items[i].Assignees[j].ProfilePicture)
-		}
-	}
+	h.prepareListItems(ctx, items)
 	
 	return c.JSON(fiber.Map{
 		"data": items,
@@ -375,6 +483,31 @@ func (h *Handlers) TicketsList(c *fiber.Ctx) error {
 	})
 }
 
+// prepareListItems turns assignee picture paths into URLs and fills in SLA state
+func (h *Handlers) prepareListItems(ctx context.Context, items []models.Ticket) {
+	now := time.Now()
+	cal := h.businessCalendar(ctx)
+	for i := range items {
+		for j := range items[i].Assignees {
+			items[i].Assignees[j].ProfilePicture = h.convertProfilePictureToURL(items[i].Assignees[j].ProfilePicture)
+		}
+		items[i].SLA = sla.Evaluate(cal, items[i], now)
+	}
+}
+
+// queryCursor decodes the cursor query parameter; empty means the first page
+func queryCursor(c *fiber.Ctx) (*repositories.Cursor, error) {
+	token := c.Query("cursor")
+	if token == "" {
+		return nil, nil
+	}
+	cur, err := repositories.DecodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	return &cur, nil
+}
+
 func (h *Handlers) TicketsDetail(c *fiber.Ctx) error {
 	id := c.Params("id")
 	ctx := context.Background()
@@ -387,11 +520,23 @@ func (h *Handlers) TicketsDetail(c *fiber.Ctx) error {
 	for i := range t.Assignees {
 		t.Assignees[i].ProfilePicture = h.convertProfilePictureToURL(t.Assignees[i].ProfilePicture)
 	}
+	t.SLA = sla.Evaluate(h.businessCalendar(ctx), t, time.Now())
+
+	// Status changes the caller may make from here
+	transitions := []models.TicketStatus{}
+	canReopen := false
+	if userID, role, ok := middleware.GetUserFromContext(c); ok {
+		facts := workflowFacts(t, t.Assignees, userID)
+		transitions = workflow.Default.Available(t.Status, models.Role(role), facts)
+		canReopen = len(workflow.Reopen.Available(t.Status, models.Role(role), facts)) > 0
+	}
 	
 	return c.JSON(h.envelope(fiber.Map{
 		"ticket": t,
 		"comments": comments,
 		"attachments": atts,
+		"availableTransitions": transitions,
+		"canReopen": canReopen,
 	}))
 }
 
@@ -468,20 +613,30 @@ func (h *Handlers) TicketsUpdate(c *fiber.Ctx) error {
 		changes = append(changes, "Description was updated")
 	}
 	
-	if err := h.repo.Tickets.Update(ctx, id, body.Title, body.Description, body.Details); err != nil {
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.Update(ctx, id, body.Title, body.Description, body.Details); err != nil {
+			return err
+		}
+		
+		// Add automatic comment if there were changes
+		if len(changes) > 0 {
+			commentBody := fmt.Sprintf("Ticket updated by %s:\n\n%s", role, strings.Join(changes, "\n"))
+			if err := tx.Tickets.AddComment(ctx, id, &userID, commentBody); err != nil {
+				return err
+			}
+			// If any ticket content changed, recompute priority on server side if we had inputs stored
+			// Note: Priority still uses existing fields (impact/urgency/red flags) which are updated via dedicated endpoints.
+			// We ensure user scores use Effort only, handled elsewhere.
+		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "update_ticket", nil, body)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
 	}
-	
-    // Add automatic comment if there were changes
-	if len(changes) > 0 {
-		commentBody := fmt.Sprintf("Ticket updated by %s:\n\n%s", role, strings.Join(changes, "\n"))
-		h.repo.Tickets.AddComment(ctx, id, &userID, commentBody)
-        // If any ticket content changed, recompute priority on server side if we had inputs stored
-        // Note: Priority still uses existing fields (impact/urgency/red flags) which are updated via dedicated endpoints.
-        // We ensure user scores use Effort only, handled elsewhere.
-	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "update_ticket", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id}))
 }
 
@@ -532,7 +687,9 @@ func (h *Handlers) TicketsUpdateFields(c *fiber.Ctx) error {
 	}
 	
 	// Process priority input if provided
+	var redFlagsData, impactAssessmentData, urgencyTimelineData map[string]any
 	if body.PriorityInput != nil {
+		applyDeadline(h.businessCalendar(ctx), body.PriorityInput, time.Now())
 		p := priority.Compute(*body.PriorityInput)
 		// Override computed values
 		body.Priority = (*models.TicketPriority)(&p.Priority)
@@ -545,31 +702,23 @@ func (h *Handlers) TicketsUpdateFields(c *fiber.Ctx) error {
 		body.RedFlag = &p.RedFlag
 		
 		// Store the priority input data in the database
-		redFlagsData := map[string]any{
+		redFlagsData = map[string]any{
 			"criticalIssues": body.PriorityInput.RedFlags,
 		}
-		impactAssessmentData := map[string]any{
+		impactAssessmentData = map[string]any{
 			"impacts": body.PriorityInput.Impact,
 		}
-		urgencyTimelineData := map[string]any{
+		urgencyTimelineData = map[string]any{
 			"timeline": body.PriorityInput.Urgency,
 		}
-		
-		// Get user name for comment
-		userClaims, _ := c.Locals("user").(jwt.MapClaims)
-		userName, _ := userClaims["name"].(string)
-		if userName == "" { userName = role }
-		
-		if err := h.repo.Tickets.UpdateRedFlags(ctx, id, redFlagsData, userName); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"red flags update failed"}})
-		}
-		if err := h.repo.Tickets.UpdateImpactAssessment(ctx, id, impactAssessmentData, userName); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"impact assessment update failed"}})
-		}
-		if err := h.repo.Tickets.UpdateUrgencyTimeline(ctx, id, urgencyTimelineData, userName); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"urgency timeline update failed"}})
+		if body.PriorityInput.Deadline != nil {
+			urgencyTimelineData["deadline"] = body.PriorityInput.Deadline.Format(time.RFC3339)
 		}
 	}
+	
+	// Get user name for comments
+	userName, _ := userClaims["name"].(string)
+	if userName == "" { userName = role }
 
 	// Track changes for automatic comment generation
 	var changes []string
@@ -603,56 +752,80 @@ func (h *Handlers) TicketsUpdateFields(c *fiber.Ctx) error {
 		}
 	}
 	
-    if err := h.repo.Tickets.UpdateTicketFields(ctx, id, body.InitialType, body.ResolvedType, body.Priority, body.ImpactScore, body.UrgencyScore, body.FinalScore, body.RedFlag); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
-	}
-
-    // Optional effort direct update (admin fields)
-    if body.EffortScore != nil || body.EffortData != nil {
-        // Re-read ticket to merge existing
-        current, _ := h.repo.Tickets.GetByID(ctx, id)
-        newData := current.EffortData
-        if newData == nil { newData = map[string]any{} }
-        if body.EffortData != nil {
-            newData = body.EffortData
-        }
-        newScore := current.EffortScore
-        if body.EffortScore != nil { newScore = *body.EffortScore }
-        // Get user name for comment
-        userClaims, _ := c.Locals("user").(jwt.MapClaims)
-        userName, _ := userClaims["name"].(string)
-        if userName == "" { _, role, _ := middleware.GetUserFromContext(c); userName = role }
-        if err := h.repo.Tickets.UpdateEffort(ctx, id, newData, newScore, userName); err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"effort update failed"}})
-        }
-    }
-	
-	// Add automatic comment if there were changes
-	if len(changes) > 0 {
-		commentBody := fmt.Sprintf("⚙️ Ticket fields updated by %s:\n\n%s", role, strings.Join(changes, "\n"))
-		h.repo.Tickets.AddComment(ctx, id, &userID, commentBody)
+	// All field changes, their comments and any point redistribution commit together
+	failed := "update failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if body.PriorityInput != nil {
+			if err := tx.Tickets.UpdateRedFlags(ctx, id, redFlagsData, userName); err != nil {
+				failed = "red flags update failed"
+				return err
+			}
+			if err := tx.Tickets.UpdateImpactAssessment(ctx, id, impactAssessmentData, userName); err != nil {
+				failed = "impact assessment update failed"
+				return err
+			}
+			if err := tx.Tickets.UpdateUrgencyTimeline(ctx, id, urgencyTimelineData, userName); err != nil {
+				failed = "urgency timeline update failed"
+				return err
+			}
+		}
+		
+		if err := tx.Tickets.UpdateTicketFields(ctx, id, body.InitialType, body.ResolvedType, body.Priority, body.ImpactScore, body.UrgencyScore, body.FinalScore, body.RedFlag); err != nil {
+			return err
+		}
+		
+		// SLA targets depend on priority and ticket type
+		if (body.Priority != nil && *body.Priority != ticket.Priority) || (body.InitialType != nil && *body.InitialType != ticket.InitialType) {
+			if err := h.recalculateSLA(ctx, tx, id); err != nil {
+				failed = "sla recalculation failed"
+				return err
+			}
+		}
 		
-        // Recalculate score distribution if effort changed or final score changed for completed tickets
-        if ticket.Status == models.StatusCompleted && (body.FinalScore != nil || body.EffortScore != nil || body.EffortData != nil) {
-			assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-			if err == nil && len(assignees) > 0 {
-				// Extract assignee IDs
-				assigneeIDs := make([]string, len(assignees))
-				for i, assignee := range assignees {
-					assigneeIDs[i] = assignee.ID
+		// Optional effort direct update (admin fields)
+		if body.EffortScore != nil || body.EffortData != nil {
+			// Re-read ticket to merge existing
+			current, err := tx.Tickets.GetByID(ctx, id)
+			if err != nil {
+				failed = "effort update failed"
+				return err
+			}
+			newData := current.EffortData
+			if newData == nil { newData = map[string]any{} }
+			if body.EffortData != nil {
+				newData = body.EffortData
+			}
+			newScore := current.EffortScore
+			if body.EffortScore != nil { newScore = *body.EffortScore }
+			if err := tx.Tickets.UpdateEffort(ctx, id, newData, newScore, userName); err != nil {
+				failed = "effort update failed"
+				return err
+			}
+		}
+		
+		// Add automatic comment if there were changes
+		if len(changes) > 0 {
+			commentBody := fmt.Sprintf("⚙️ Ticket fields updated by %s:\n\n%s", role, strings.Join(changes, "\n"))
+			if err := tx.Tickets.AddComment(ctx, id, &userID, commentBody); err != nil {
+				return err
+			}
+			
+			// Recalculate score distribution if effort changed or final score changed for completed tickets
+			if ticket.Status == models.StatusCompleted && (body.FinalScore != nil || body.EffortScore != nil || body.EffortData != nil) {
+				if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+					return err
 				}
-                // Redistribute points with new Effort (preferred)
-                updated, _ := h.repo.Tickets.GetByID(ctx, id)
-                total := float64(updated.EffortScore)
-                if len(assignees) > 0 {
-                    total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees))
-                }
-                h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
 			}
 		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "update_ticket_fields", nil, body)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "update_ticket_fields", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id}))
 }
 
@@ -708,55 +881,68 @@ func (h *Handlers) TicketsAssign(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"no assignees specified"}})
 	}
 	
-	// Assign users
-	if err := h.repo.Tickets.AssignUsers(ctx, id, assigneeIDs, &userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"assign failed"}})
-	}
-	
-	// Get updated assignees and user names for comment
-	newAssignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get updated assignees"}})
-	}
-	
-	// Generate assignment comment
-	var assignmentChanges []string
-	
-	// Find newly assigned users
-	currentAssigneeMap := make(map[string]bool)
-	for _, assignee := range currentAssignees {
-		currentAssigneeMap[assignee.ID] = true
-	}
-	
-	for _, assignee := range newAssignees {
-		if !currentAssigneeMap[assignee.ID] {
-			assignmentChanges = append(assignmentChanges, fmt.Sprintf("✅ Assigned to %s (%s)", assignee.Name, assignee.Role))
+	// Assign users, comment and rebalance points together
+	var newAssignees []models.User
+	failed := "assign failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.AssignUsers(ctx, id, assigneeIDs, &userID); err != nil {
+			return err
 		}
-	}
-	
-	// Add automatic comment if there were changes
-	if len(assignmentChanges) > 0 {
-		commentBody := fmt.Sprintf("Assignment updated by %s:\n\n%s", role, strings.Join(assignmentChanges, "\n"))
-		h.repo.Tickets.AddComment(ctx, id, &userID, commentBody)
 		
-        // Recalculate score distribution if ticket is completed
-		ticket, err := h.repo.Tickets.GetByID(ctx, id)
-		if err == nil && ticket.Status == models.StatusCompleted && len(newAssignees) > 0 {
-			// Extract new assignee IDs
-			assigneeIDs := make([]string, len(newAssignees))
-			for i, assignee := range newAssignees {
-				assigneeIDs[i] = assignee.ID
+		// Get updated assignees and user names for comment
+		var err error
+		if newAssignees, err = tx.Tickets.GetAssignees(ctx, id); err != nil {
+			failed = "failed to get updated assignees"
+			return err
+		}
+		
+		// Generate assignment comment
+		var assignmentChanges []string
+		
+		// Find newly assigned users
+		currentAssigneeMap := make(map[string]bool)
+		for _, assignee := range currentAssignees {
+			currentAssigneeMap[assignee.ID] = true
+		}
+		
+		var added []string
+		for _, assignee := range newAssignees {
+			if !currentAssigneeMap[assignee.ID] {
+				assignmentChanges = append(assignmentChanges, fmt.Sprintf("✅ Assigned to %s (%s)", assignee.Name, assignee.Role))
+				added = append(added, assignee.ID)
 			}
-            // Redistribute using Effort Score + collaboration
-            total := float64(ticket.EffortScore)
-            if len(newAssignees) > 0 {
-                total += float64(effort.CollaborationExtraPerPerson(len(newAssignees)) * len(newAssignees))
-            }
-            h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
 		}
+		if err := h.notify(ctx, tx, notifications.KindAssigned, id, &userID, added, notifications.Data{}); err != nil {
+			return err
+		}
+		
+		// Add automatic comment if there were changes
+		if len(assignmentChanges) > 0 {
+			commentBody := fmt.Sprintf("Assignment updated by %s:\n\n%s", role, strings.Join(assignmentChanges, "\n"))
+			if err := tx.Tickets.AddComment(ctx, id, &userID, commentBody); err != nil {
+				return err
+			}
+			
+			// Recalculate score distribution if ticket is completed
+			ticket, err := tx.Tickets.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if ticket.Status == models.StatusCompleted {
+				if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+					return err
+				}
+			}
+		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketAssigned, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "assign", nil, body)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "assign", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id, "assignees": newAssignees}))
 }
 
@@ -799,47 +985,54 @@ func (h *Handlers) TicketsUnassign(c *fiber.Ctx) error {
 		}
 	}
 	
-	// Unassign users
-	if err := h.repo.Tickets.UnassignUsers(ctx, id, body.AssigneeIDs); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"unassign failed"}})
-	}
-	
-	// Get updated assignees
-	newAssignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get updated assignees"}})
-	}
-	
-	// Add automatic comment if there were changes
-	if len(unassignmentChanges) > 0 {
-		commentBody := fmt.Sprintf("Assignment updated by %s:\n\n%s", role, strings.Join(unassignmentChanges, "\n"))
-		h.repo.Tickets.AddComment(ctx, id, &userID, commentBody)
+	// Unassign users, comment and rebalance points together
+	var newAssignees []models.User
+	failed := "unassign failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.UnassignUsers(ctx, id, body.AssigneeIDs); err != nil {
+			return err
+		}
 		
-        // Recalculate score distribution if ticket is completed
-		ticket, err := h.repo.Tickets.GetByID(ctx, id)
-		if err == nil && ticket.Status == models.StatusCompleted {
-			if len(newAssignees) > 0 {
-				// Redistribute points among remaining assignees
-				assigneeIDs := make([]string, len(newAssignees))
-				for i, assignee := range newAssignees {
-					assigneeIDs[i] = assignee.ID
+		// Get updated assignees
+		var err error
+		if newAssignees, err = tx.Tickets.GetAssignees(ctx, id); err != nil {
+			failed = "failed to get updated assignees"
+			return err
+		}
+		
+		// Add automatic comment if there were changes
+		if len(unassignmentChanges) > 0 {
+			commentBody := fmt.Sprintf("Assignment updated by %s:\n\n%s", role, strings.Join(unassignmentChanges, "\n"))
+			if err := tx.Tickets.AddComment(ctx, id, &userID, commentBody); err != nil {
+				return err
+			}
+			
+			// Recalculate score distribution if ticket is completed; with no assignees left the points are removed
+			ticket, err := tx.Tickets.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if ticket.Status == models.StatusCompleted {
+				if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+					return err
 				}
-                total := float64(ticket.EffortScore)
-                total += float64(effort.CollaborationExtraPerPerson(len(newAssignees)) * len(newAssignees))
-                h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
-			} else {
-				// No assignees left - remove all points for this ticket
-				h.repo.UserScores.RemoveAllPointsForTicket(ctx, id)
 			}
 		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUnassigned, TicketID: id, ActorID: &userID, Assignees: body.AssigneeIDs}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "unassign", nil, body)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "unassign", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id, "assignees": newAssignees}))
 }
 
 type StatusReq struct {
 	Status models.TicketStatus `json:"status"`
+	Reason string              `json:"reason"`
 }
 
 func (h *Handlers) TicketsStatus(c *fiber.Ctx) error {
@@ -863,53 +1056,117 @@ func (h *Handlers) TicketsStatus(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
 	}
 	
-	// Check ownership for cancellation: Users can only cancel their own tickets
-	if body.Status == models.StatusCanceled && role == "User" {
-		if ticket.CreatedBy == nil || *ticket.CreatedBy != userID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code":"FORBIDDEN","message":"can only cancel your own tickets"}})
+	if !workflow.Default.IsStatus(body.Status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"invalid status"}})
+	}
+	if body.Status == ticket.Status {
+		return c.JSON(h.envelope(fiber.Map{"id": id, "status": body.Status}))
+	}
+
+	// Enforce the workflow: allowed edges, roles and guards (creator, assignee, ...)
+	assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to check assignment"}})
+	}
+	if err := workflow.Default.Check(ticket.Status, body.Status, models.Role(role), workflowFacts(ticket, assignees, userID)); err != nil {
+		var te *workflow.TransitionError
+		if errors.As(err, &te) {
+			return c.Status(fiber.StatusConflict).JSON(transitionConflict(te))
 		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":err.Error()}})
+	}
+	
+	// Pausing a ticket needs an explanation for the requester
+	body.Reason = strings.TrimSpace(body.Reason)
+	if workflow.RequiresReason(body.Status) && body.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"REASON_REQUIRED","message":"a reason is required for this status"}})
 	}
 	
     // Track status change for automatic comment generation
-	var statusChangeComment string
-	if body.Status != ticket.Status {
-		statusChangeComment = fmt.Sprintf("Status changed from \"%s\" to \"%s\" by %s", ticket.Status, body.Status, role)
+	statusChangeComment := fmt.Sprintf("Status changed from \"%s\" to \"%s\" by %s", ticket.Status, body.Status, role)
+	if body.Reason != "" {
+		statusChangeComment += "\n\nReason: " + body.Reason
 	}
 	
-	if err := h.repo.Tickets.ChangeStatus(ctx, id, body.Status); err != nil {
+	if err := h.applyStatusChange(ctx, h.repo, ticket, body.Status, &userID, statusChangeComment); err != nil {
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			return c.Status(fiber.StatusConflict).JSON(statusChangedConflict())
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":err.Error()}})
 	}
-	
-    // Handle score distribution for completed tickets (Effort-based)
-	if body.Status == models.StatusCompleted {
-		assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-		if err == nil && len(assignees) > 0 {
-			// Get ticket details for final score
-			ticket, err := h.repo.Tickets.GetByID(ctx, id)
-			if err == nil {
-				// Extract assignee IDs
-				assigneeIDs := make([]string, len(assignees))
-				for i, assignee := range assignees {
-					assigneeIDs[i] = assignee.ID
-				}
-                // Distribute Effort points among assignees (base/avg + collaboration per person)
-                total := float64(ticket.EffortScore)
-                if len(assignees) > 0 { total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees)) }
-                h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
-			}
+	return c.JSON(h.envelope(fiber.Map{"id": id, "status": body.Status}))
+}
+
+// redistributeEffortPoints splits the Effort points of a completed ticket among its current
+// assignees (base + collaboration per person), or takes them back when nobody is assigned.
+func redistributeEffortPoints(ctx context.Context, r *repositories.Repo, id string) error {
+	assignees, err := r.Tickets.GetAssignees(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(assignees) == 0 {
+		return r.UserScores.RemoveAllPointsForTicket(ctx, id)
+	}
+	ticket, err := r.Tickets.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	assigneeIDs := make([]string, len(assignees))
+	for i, assignee := range assignees {
+		assigneeIDs[i] = assignee.ID
+	}
+	total := float64(ticket.EffortScore)
+	total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees))
+	return r.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
+}
+
+// applyStatusChange stores a status change the workflow has already allowed from
+// ticket.Status, then records it (see recordStatusChange), all in one transaction on r.
+// It returns repositories.ErrStatusChanged, writing nothing, when the ticket's status
+// moved on after it was read.
+func (h *Handlers) applyStatusChange(ctx context.Context, r *repositories.Repo, ticket models.Ticket, status models.TicketStatus, actorID *string, comment string) error {
+	return r.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.ChangeStatus(ctx, ticket.ID, ticket.Status, status); err != nil {
+			return err
+		}
+		return h.recordStatusChange(ctx, tx, ticket, status, actorID, comment)
+	})
+}
+
+// recordStatusChange does everything that follows a stored status change: it restarts
+// paused SLA clocks, awards points on completion and records the change as a comment,
+// notification, event and audit entry. Points are only taken back by the reopen flow
+// (see TicketsReopen).
+func (h *Handlers) recordStatusChange(ctx context.Context, tx *repositories.Repo, ticket models.Ticket, status models.TicketStatus, actorID *string, comment string) error {
+	id := ticket.ID
+	if workflow.IsPaused(ticket.Status) && !workflow.IsPaused(status) {
+		if err := h.resumeSLA(ctx, tx, ticket, time.Now()); err != nil {
+			return err
 		}
-	} else if body.Status == models.StatusPending || body.Status == models.StatusInProgress {
-		// Remove points if ticket is reopened
-		h.repo.UserScores.RemoveAllPointsForTicket(ctx, id)
 	}
-	
-	// Add automatic comment if status changed
-	if statusChangeComment != "" {
-		h.repo.Tickets.AddComment(ctx, id, &userID, statusChangeComment)
+
+	// Distribute Effort points among assignees of completed tickets
+	if status == models.StatusCompleted {
+		if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+			return err
+		}
 	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "status_change", nil, body.Status)
-	return c.JSON(h.envelope(fiber.Map{"id": id, "status": body.Status}))
+
+	// Add automatic comment for the status change
+	if err := tx.Tickets.AddComment(ctx, id, actorID, comment); err != nil {
+		return err
+	}
+	watchers, err := tx.Watchers.List(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := h.notify(ctx, tx, notifications.KindStatusChanged, id, actorID, watchers, notifications.Data{FromStatus: ticket.Status, ToStatus: status}); err != nil {
+		return err
+	}
+	if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketStatusChanged, TicketID: id, ActorID: actorID, Data: map[string]any{"from": ticket.Status, "status": status}}); err != nil {
+		return err
+	}
+	return tx.Audits.Insert(ctx, id, actorID, "status_change", nil, status)
 }
 
 type CommentReq struct {
@@ -928,11 +1185,40 @@ func (h *Handlers) TicketsAddComment(c *fiber.Ctx) error {
 		if sid, ok := userClaims["sub"].(string); ok { userID = &sid }
 	}
 	ctx := context.Background()
-	commentID, err := h.repo.Tickets.AddCommentWithID(ctx, id, userID, body.Body)
+	var commentID string
+	err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		var err error
+		if commentID, err = tx.Tickets.AddCommentWithID(ctx, id, userID, body.Body); err != nil {
+			return err
+		}
+		if err := tx.Audits.Insert(ctx, id, userID, "add_comment", nil, body.Body); err != nil {
+			return err
+		}
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.CommentCreated, TicketID: id, ActorID: userID, Data: map[string]any{"commentId": commentID}}); err != nil {
+			return err
+		}
+		mentioned, err := h.recordMentions(ctx, tx, id, commentID, userID, body.Body)
+		if err != nil {
+			return err
+		}
+		watchers, err := tx.Watchers.List(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Mentioned watchers already have the mention email
+		watchers = withoutIDs(watchers, mentioned)
+		if err := h.notify(ctx, tx, notifications.KindComment, id, userID, watchers, notifications.Data{Comment: notifications.Excerpt(body.Body, commentExcerptLength)}); err != nil {
+			return err
+		}
+		// A reply from the requester picks a paused ticket back up
+		if userID != nil {
+			return h.resumeOnRequesterReply(ctx, tx, id, *userID)
+		}
+		return nil
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"add comment failed"}})
 	}
-	h.repo.Audits.Insert(ctx, id, userID, "add_comment", nil, body.Body)
 	return c.Status(fiber.StatusCreated).JSON(h.envelope(fiber.Map{"commentId": commentID}))
 }
 
@@ -944,6 +1230,28 @@ func (h *Handlers) TicketsGetComments(c *fiber.Ctx) error {
 	if pageSize > 50 { pageSize = 50 }
 	
 	ctx := context.Background()
+
+	if c.Context().QueryArgs().Has("cursor") {
+		cur, err := queryCursor(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"invalid cursor"}})
+		}
+		comments, cursors, err := h.repo.Tickets.GetCommentsByCursor(ctx, id, cur, pageSize)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get comments"}})
+		}
+		return c.JSON(h.envelope(fiber.Map{
+			"comments": comments,
+			"pagination": fiber.Map{
+				"pageSize": pageSize,
+				"nextCursor": cursors.NextCursor,
+				"prevCursor": cursors.PrevCursor,
+				"hasNext": cursors.NextCursor != nil,
+				"hasPrev": cursors.PrevCursor != nil,
+			},
+		}))
+	}
+
 	comments, total, err := h.repo.Tickets.GetCommentsPaginated(ctx, id, page, pageSize)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get comments"}})
@@ -982,26 +1290,40 @@ func (h *Handlers) TicketsUploadAttachments(c *fiber.Ctx) error {
 	if len(files) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"no files"}})
 	}
-	ctx := context.Background()
-	res := []any{}
 	for _, fh := range files {
 		if fh.Size > maxUploadSize {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"file too large"}})
 		}
-		// Rely on client-provided content-type, better to sniff in prod
-		mime := fh.Header.Get("Content-Type")
-		// Allow all file types as requested - no restrictions
-		if mime == "" {
-			mime = "application/octet-stream" // Default for unknown types
-		}
-		path, err := h.saveUpload(fh)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"save failed"}})
-		}
-		if err := h.repo.Tickets.AddAttachment(ctx, id, fh.Filename, mime, fh.Size, path); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"db failed"}})
+	}
+	ctx := context.Background()
+	res := []any{}
+	var saved []string
+	failed := "db failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		for _, fh := range files {
+			// Rely on client-provided content-type, better to sniff in prod
+			mime := fh.Header.Get("Content-Type")
+			// Allow all file types as requested - no restrictions
+			if mime == "" {
+				mime = "application/octet-stream" // Default for unknown types
+			}
+			path, err := h.saveUpload(fh)
+			if err != nil {
+				failed = "save failed"
+				return err
+			}
+			saved = append(saved, path)
+			if err := tx.Tickets.AddAttachment(ctx, id, fh.Filename, mime, fh.Size, path); err != nil {
+				return err
+			}
+			res = append(res, fiber.Map{"filename": fh.Filename})
 		}
-		res = append(res, fiber.Map{"filename": fh.Filename})
+		return nil
+	})
+	if err != nil {
+		// Nothing was recorded, so drop the files written so far
+		removeUploads(saved)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
 	return c.Status(fiber.StatusCreated).JSON(h.envelope(res))
 }
@@ -1018,25 +1340,39 @@ func (h *Handlers) CommentsUploadAttachments(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"no files"}})
 	}
 	
-	ctx := context.Background()
-	res := []any{}
 	for _, fh := range files {
 		if fh.Size > maxUploadSize {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code":"BAD_REQUEST","message":"file too large"}})
 		}
-		mime := fh.Header.Get("Content-Type")
-		if mime == "" {
-			mime = "application/octet-stream"
-		}
-		
-		path, err := h.saveUpload(fh)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"save failed"}})
-		}
-		if err := h.repo.Tickets.AddCommentAttachment(ctx, commentID, fh.Filename, mime, fh.Size, path); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"db failed"}})
+	}
+	
+	ctx := context.Background()
+	res := []any{}
+	var saved []string
+	failed := "db failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		for _, fh := range files {
+			mime := fh.Header.Get("Content-Type")
+			if mime == "" {
+				mime = "application/octet-stream"
+			}
+			
+			path, err := h.saveUpload(fh)
+			if err != nil {
+				failed = "save failed"
+				return err
+			}
+			saved = append(saved, path)
+			if err := tx.Tickets.AddCommentAttachment(ctx, commentID, fh.Filename, mime, fh.Size, path); err != nil {
+				return err
+			}
+			res = append(res, fiber.Map{"filename": fh.Filename})
 		}
-		res = append(res, fiber.Map{"filename": fh.Filename})
+		return nil
+	})
+	if err != nil {
+		removeUploads(saved)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
 	return c.Status(fiber.StatusCreated).JSON(h.envelope(res))
 }
@@ -1083,17 +1419,29 @@ func (h *Handlers) saveUpload(fh *multipart.FileHeader) (string, error) {
 	f, err := fh.Open()
 	if err != nil { return "", err }
 	defer f.Close()
+	return h.saveFile(fh.Filename, f)
+}
+
+// saveFile writes an attachment into the upload dir and returns its path
+func (h *Handlers) saveFile(filename string, r io.Reader) (string, error) {
 	// naive secure filename
-	name := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(fh.Filename))
+	name := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(filename))
 	dst := filepath.Join(h.cfg.UploadDir, name)
 	os.MkdirAll(h.cfg.UploadDir, 0o755)
 	out, err := os.Create(dst)
 	if err != nil { return "", err }
 	defer out.Close()
-	if _, err := io.Copy(out, f); err != nil { return "", err }
+	if _, err := io.Copy(out, r); err != nil { return "", err }
 	return dst, nil
 }
 
+// removeUploads deletes files saved for a request whose database writes were rolled back
+func removeUploads(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
+
 // Signed URL (HMAC) generator
 func (h *Handlers) signPath(p string, exp time.Time) string {
 	mac := hmac.New(sha256.New, []byte(h.cfg.JWTSecret))
@@ -1103,6 +1451,14 @@ func (h *Handlers) signPath(p string, exp time.Time) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// verifyPath checks a signature from signPath and that exp has not passed
+func (h *Handlers) verifyPath(p string, exp time.Time, sig string) bool {
+	if !time.Now().Before(exp) {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(h.signPath(p, exp)))
+}
+
 // -------------------- Profile --------------------
 
 type ProfileUpdateReq struct {
@@ -1229,24 +1585,60 @@ func (h *Handlers) TicketsClassify(c *fiber.Ctx) error {
 	}
 	
 	if body.Reject != nil && *body.Reject {
-		// Handle rejection by setting status to canceled
-		if err := h.repo.Tickets.RejectIssueReport(ctx, id); err != nil {
-			if errors.Is(err, repositories.ErrNotFound) {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
+		// Rejecting cancels the ticket, so it takes the workflow's cancel edge
+		ticket, err := h.repo.Tickets.GetByID(ctx, id)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
+		}
+		actorID, role, _ := middleware.GetUserFromContext(c)
+		assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to check assignment"}})
+		}
+		if err := workflow.Default.Check(ticket.Status, models.StatusCanceled, models.Role(role), workflowFacts(ticket, assignees, actorID)); err != nil {
+			var te *workflow.TransitionError
+			if errors.As(err, &te) {
+				return c.Status(fiber.StatusConflict).JSON(transitionConflict(te))
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":err.Error()}})
+		}
+		comment := fmt.Sprintf("Status changed from \"%s\" to \"%s\" by %s\n\nReason: issue report rejected", ticket.Status, models.StatusCanceled, role)
+		err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+			if err := tx.Tickets.RejectIssueReport(ctx, id, ticket.Status); err != nil {
+				return err
+			}
+			if err := h.recordStatusChange(ctx, tx, ticket, models.StatusCanceled, userID, comment); err != nil {
+				return err
 			}
+			return tx.Audits.Insert(ctx, id, userID, "issue_report_rejected", nil, models.StatusCanceled)
+		})
+		if errors.Is(err, repositories.ErrStatusChanged) {
+			return c.Status(fiber.StatusConflict).JSON(statusChangedConflict())
+		}
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
+		}
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"reject failed"}})
 		}
-		h.repo.Audits.Insert(ctx, id, userID, "issue_report_rejected", nil, models.StatusCanceled)
 		return c.JSON(h.envelope(fiber.Map{"id": id, "status": "rejected"}))
 	} else {
 		// Handle normal classification
-		if err := h.repo.Tickets.Classify(ctx, id, *body.ResolvedType); err != nil {
+		err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+			if err := tx.Tickets.Classify(ctx, id, *body.ResolvedType); err != nil {
+				return err
+			}
+			if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketClassified, TicketID: id, ActorID: userID, Data: map[string]any{"resolvedType": *body.ResolvedType}}); err != nil {
+				return err
+			}
+			return tx.Audits.Insert(ctx, id, userID, "classified", nil, *body.ResolvedType)
+		})
+		if err != nil {
 			if errors.Is(err, repositories.ErrNotFound) {
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
 			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"classify failed"}})
 		}
-		h.repo.Audits.Insert(ctx, id, userID, "classified", nil, *body.ResolvedType)
 		return c.JSON(h.envelope(fiber.Map{"id": id, "resolvedType": *body.ResolvedType}))
 	}
 }
@@ -1298,24 +1690,28 @@ func (h *Handlers) TicketsUpdateEffort(c *fiber.Ctx) error {
     ctx := context.Background()
     userName, _ := userClaims["name"].(string)
     if userName == "" { userName = role }
-    if err := h.repo.Tickets.UpdateEffort(ctx, id, data, int32(score), userName); err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
-    }
-
-    // If completed, redistribute points using effort
-    ticket, _ := h.repo.Tickets.GetByID(ctx, id)
-    if ticket.Status == models.StatusCompleted {
-        assignees, _ := h.repo.Tickets.GetAssignees(ctx, id)
-        if len(assignees) > 0 {
-            ids := make([]string, len(assignees))
-            for i, a := range assignees { ids[i] = a.ID }
-            total := float64(score)
-            total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees))
-            h.repo.UserScores.DistributePoints(ctx, id, total, ids)
+    err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+        if err := tx.Tickets.UpdateEffort(ctx, id, data, int32(score), userName); err != nil {
+            return err
+        }
+        // If completed, redistribute points using effort
+        ticket, err := tx.Tickets.GetByID(ctx, id)
+        if err != nil {
+            return err
+        }
+        if ticket.Status == models.StatusCompleted {
+            if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+                return err
+            }
         }
+        if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+            return err
+        }
+        return tx.Audits.Insert(ctx, id, &userID, "update_effort", nil, body)
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
     }
-
-    h.repo.Audits.Insert(ctx, id, &userID, "update_effort", nil, body)
     return c.JSON(h.envelope(fiber.Map{"id": id}))
 }
 
@@ -1362,28 +1758,65 @@ func (h *Handlers) TicketsUpdateRedFlags(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get ticket"}})
 	}
 	
-	if err := h.repo.Tickets.UpdateRedFlags(ctx, id, body.RedFlagsData, userName); err != nil {
+	// Red flags drive priority: re-derive it from the stored scores and re-stamp SLA deadlines if it moved
+	p := priority.FromScores(int(ticket.ImpactScore), int(ticket.UrgencyScore), hasCriticalIssue(body.RedFlagsData))
+	newPriority := models.TicketPriority(p.Priority)
+	
+	failed := "update failed"
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.UpdateRedFlags(ctx, id, body.RedFlagsData, userName); err != nil {
+			return err
+		}
+		
+		if newPriority != ticket.Priority || p.RedFlag != ticket.RedFlag {
+			finalScore := int32(p.Final)
+			if err := tx.Tickets.UpdateTicketFields(ctx, id, nil, nil, &newPriority, nil, nil, &finalScore, &p.RedFlag); err != nil {
+				failed = "priority update failed"
+				return err
+			}
+			if newPriority != ticket.Priority {
+				if err := tx.Tickets.AddSystemComment(ctx, id, fmt.Sprintf("Priority changed from \"%s\" to \"%s\" after red flags update", ticket.Priority, newPriority)); err != nil {
+					failed = "priority update failed"
+					return err
+				}
+				if err := h.recalculateSLA(ctx, tx, id); err != nil {
+					failed = "sla recalculation failed"
+					return err
+				}
+			}
+		}
+		
+		// Trigger recalculation if ticket is completed
+		if ticket.Status == models.StatusCompleted {
+			if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "update_red_flags", nil, body)
+	})
+	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":failed}})
 	}
-	
-    // Trigger recalculation if ticket is completed
-    if ticket.Status == models.StatusCompleted {
-		assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-		if err == nil && len(assignees) > 0 {
-            assigneeIDs := make([]string, len(assignees))
-            for i, assignee := range assignees { assigneeIDs[i] = assignee.ID }
-            refreshed, _ := h.repo.Tickets.GetByID(ctx, id)
-            total := float64(refreshed.EffortScore)
-            if len(assignees) > 0 { total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees)) }
-            h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
+	return c.JSON(h.envelope(fiber.Map{"id": id}))
+}
+
+// hasCriticalIssue reports whether any critical issue is checked in red flags data
+// of the form {"criticalIssues": {"outage": true, ...}}
+func hasCriticalIssue(redFlagsData map[string]any) bool {
+	issues, _ := redFlagsData["criticalIssues"].(map[string]any)
+	for _, v := range issues {
+		if b, ok := v.(bool); ok && b {
+			return true
 		}
 	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "update_red_flags", nil, body)
-	return c.JSON(h.envelope(fiber.Map{"id": id}))
+	return false
 }
 
 type UpdateImpactAssessmentReq struct {
@@ -1429,27 +1862,29 @@ func (h *Handlers) TicketsUpdateImpactAssessment(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get ticket"}})
 	}
 	
-	if err := h.repo.Tickets.UpdateImpactAssessment(ctx, id, body.ImpactAssessmentData, userName); err != nil {
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.UpdateImpactAssessment(ctx, id, body.ImpactAssessmentData, userName); err != nil {
+			return err
+		}
+		
+		// Trigger recalculation if ticket is completed
+		if ticket.Status == models.StatusCompleted {
+			if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "update_impact_assessment", nil, body)
+	})
+	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
 	}
-	
-    // Trigger recalculation if ticket is completed
-    if ticket.Status == models.StatusCompleted {
-		assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-		if err == nil && len(assignees) > 0 {
-            assigneeIDs := make([]string, len(assignees))
-            for i, assignee := range assignees { assigneeIDs[i] = assignee.ID }
-            refreshed, _ := h.repo.Tickets.GetByID(ctx, id)
-            total := float64(refreshed.EffortScore)
-            if len(assignees) > 0 { total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees)) }
-            h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
-		}
-	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "update_impact_assessment", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id}))
 }
 
@@ -1496,27 +1931,29 @@ func (h *Handlers) TicketsUpdateUrgencyTimeline(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"failed to get ticket"}})
 	}
 	
-	if err := h.repo.Tickets.UpdateUrgencyTimeline(ctx, id, body.UrgencyTimelineData, userName); err != nil {
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Tickets.UpdateUrgencyTimeline(ctx, id, body.UrgencyTimelineData, userName); err != nil {
+			return err
+		}
+		
+		// Trigger recalculation if ticket is completed
+		if ticket.Status == models.StatusCompleted {
+			if err := redistributeEffortPoints(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketUpdated, TicketID: id, ActorID: &userID}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "update_urgency_timeline", nil, body)
+	})
+	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code":"NOT_FOUND","message":"ticket not found"}})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code":"SERVER_ERROR","message":"update failed"}})
 	}
-	
-    // Trigger recalculation if ticket is completed
-    if ticket.Status == models.StatusCompleted {
-		assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
-		if err == nil && len(assignees) > 0 {
-            assigneeIDs := make([]string, len(assignees))
-            for i, assignee := range assignees { assigneeIDs[i] = assignee.ID }
-            refreshed, _ := h.repo.Tickets.GetByID(ctx, id)
-            total := float64(refreshed.EffortScore)
-            if len(assignees) > 0 { total += float64(effort.CollaborationExtraPerPerson(len(assignees)) * len(assignees)) }
-            h.repo.UserScores.DistributePoints(ctx, id, total, assigneeIDs)
-		}
-	}
-	
-	h.repo.Audits.Insert(ctx, id, &userID, "update_urgency_timeline", nil, body)
 	return c.JSON(h.envelope(fiber.Map{"id": id}))
 }
 
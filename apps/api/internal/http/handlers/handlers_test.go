@@ -27,13 +27,13 @@ func setupTestApp() (*fiber.App, *Handlers) {
 	h := New(pool, cfg)
 
 	app := fiber.New()
-	app.Use(middleware.AuthOptional(cfg.JWTSecret))
+	app.Use(middleware.AuthOptional(cfg.JWTSecret, nil))
 	
 	// Test routes
 	app.Post("/api/v1/tickets", h.TicketsCreate)
-	app.Patch("/api/v1/tickets/:id", middleware.AuthRequired(cfg.JWTSecret), h.TicketsUpdate)
-	app.Patch("/api/v1/tickets/:id/fields", middleware.AuthRequired(cfg.JWTSecret), h.TicketsUpdateFields)
-	app.Post("/api/v1/tickets/:id/status", middleware.AuthRequired(cfg.JWTSecret), h.TicketsStatus)
+	app.Patch("/api/v1/tickets/:id", middleware.AuthRequired(cfg.JWTSecret, nil), h.TicketsUpdate)
+	app.Patch("/api/v1/tickets/:id/fields", middleware.AuthRequired(cfg.JWTSecret, nil), h.TicketsUpdateFields)
+	app.Post("/api/v1/tickets/:id/status", middleware.AuthRequired(cfg.JWTSecret, nil), h.TicketsStatus)
 
 	return app, h
 }
@@ -170,7 +170,7 @@ func TestRBAC_Middleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			app := fiber.New()
-			app.Use(middleware.RequireAnyRole(cfg.JWTSecret, tt.roles))
+			app.Use(middleware.RequireAnyRole(cfg.JWTSecret, nil, tt.roles))
 			app.Get("/test", func(c *fiber.Ctx) error {
 				return c.JSON(map[string]string{"status": "ok"})
 			})
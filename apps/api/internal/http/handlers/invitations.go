@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Invitations --------------------
+
+// InvitationCreateReq invites an email address to sign up with a role
+type InvitationCreateReq struct {
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+// InvitationAcceptReq redeems an invitation token for an account
+type InvitationAcceptReq struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// invitationLink is an invitation with the link that redeems it, which is
+// only shown to the Manager who sends it
+type invitationLink struct {
+	repositories.Invitation
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// invitationPath is what an invitation token signs, with its expiry
+func invitationPath(id string) string {
+	return "/invitations/" + id
+}
+
+// invitationToken is "<id>.<expiry unix>.<signature>": nothing about it is
+// stored, and the invitation row decides whether it can still be used
+func (h *Handlers) invitationToken(inv repositories.Invitation) string {
+	return inv.ID + "." + strconv.FormatInt(inv.ExpiresAt.Unix(), 10) + "." + h.signPath(invitationPath(inv.ID), inv.ExpiresAt)
+}
+
+// invitationID returns the invitation a token was signed for, if this
+// server signed it and it has not expired
+func (h *Handlers) invitationID(token string) (string, bool) {
+	id, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", false
+	}
+	exp, sig, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || !h.verifyPath(invitationPath(id), time.Unix(unix, 0), sig) {
+		return "", false
+	}
+	return id, true
+}
+
+// withLink adds the accept link to an invitation
+func (h *Handlers) withLink(inv repositories.Invitation) invitationLink {
+	token := h.invitationToken(inv)
+	return invitationLink{Invitation: inv, Token: token, URL: h.webURL("/accept-invite?token=" + url.QueryEscape(token))}
+}
+
+// InvitationsList lists invitations newest first (status=pending|accepted|
+// revoked|expired, page, pageSize default 20, at most 100)
+func (h *Handlers) InvitationsList(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	pageSize = min(pageSize, 100)
+
+	status := repositories.InvitationStatus(c.Query("status"))
+	if status != "" && !status.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "status must be pending, accepted, revoked or expired"}})
+	}
+	invitations, total, err := h.repo.Invitations.List(context.Background(), status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list invitations"}})
+	}
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return c.JSON(h.envelope(fiber.Map{
+		"invitations": invitations,
+		"pagination": fiber.Map{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": totalPages,
+			"hasNext":    page < totalPages,
+			"hasPrev":    page > 1,
+		},
+	}))
+}
+
+// InvitationsCreate invites an email address with a role, replacing any
+// open invitation for it. The link is emailed when SMTP is configured and
+// always returned, so it can be passed on by hand.
+func (h *Handlers) InvitationsCreate(c *fiber.Ctx) error {
+	var req InvitationCreateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if err := validateEmail(req.Email); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if req.Role == "" {
+		req.Role = models.RoleUser
+	}
+	if !assignableRole(req.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "role must be User, Supervisor or Manager"}})
+	}
+
+	actorID, _ := currentUser(c)
+	ctx := context.Background()
+	var link invitationLink
+	err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if _, err := tx.Users.GetByEmail(ctx, req.Email); err == nil {
+			return errEmailTaken
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		inv, err := tx.Invitations.Create(ctx, req.Email, req.Role, &actorID, h.cfg.InviteTTL)
+		if err != nil {
+			return err
+		}
+		link = h.withLink(inv)
+		return h.mailInvitation(ctx, tx, link, actorID)
+	})
+	if errors.Is(err, errEmailTaken) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "CONFLICT", "message": "email already has an account"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to create invitation"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(link))
+}
+
+// mailInvitation queues the invitation email in tx; it does nothing while
+// SMTP_HOST is unset
+func (h *Handlers) mailInvitation(ctx context.Context, tx *repositories.Repo, link invitationLink, inviterID string) error {
+	if h.cfg.SMTPHost == "" {
+		return nil
+	}
+	inviter, err := tx.Users.GetByID(ctx, inviterID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	to := (&mail.Address{Address: link.Email}).String()
+	msg, err := notifications.RenderInvitation(h.cfg.MailLocale, to, calendar.LoadLocation(h.cfg.BusinessTimezone), notifications.Invitation{
+		InviterName: inviter.Name,
+		Role:        link.Role,
+		URL:         link.URL,
+		ExpiresAt:   link.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Notifications.Enqueue(ctx, nil, nil, notifications.KindInvitation, msg, nil)
+}
+
+// InvitationsRevoke withdraws an invitation that has not been accepted, so
+// its link stops working
+func (h *Handlers) InvitationsRevoke(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ctx := context.Background()
+	revoked, err := h.repo.Invitations.Revoke(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to revoke invitation"}})
+	}
+	inv, err := h.repo.Invitations.Get(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "invitation not found"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load invitation"}})
+	}
+	if !revoked {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "CONFLICT", "message": "invitation was already " + string(inv.Status)}})
+	}
+	return c.JSON(h.envelope(inv))
+}
+
+// AuthInvitation shows the invitation behind a token (?token=) so the
+// invitee can see the address and role before accepting
+func (h *Handlers) AuthInvitation(c *fiber.Ctx) error {
+	inv, ok, err := h.pendingInvitation(context.Background(), c, c.Query("token"))
+	if !ok {
+		return err
+	}
+	return c.JSON(h.envelope(fiber.Map{"email": inv.Email, "role": inv.Role, "expiresAt": inv.ExpiresAt}))
+}
+
+// AuthInvitationAccept redeems an invitation: it opens the account with the
+// invited email and role and the chosen name and password, and signs it in.
+// Each token works once.
+func (h *Handlers) AuthInvitationAccept(c *fiber.Ctx) error {
+	var req InvitationAcceptReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateName(req.Name); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	ctx := context.Background()
+	inv, ok, err := h.pendingInvitation(ctx, c, req.Token)
+	if !ok {
+		return err
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to hash password"}})
+	}
+
+	var user models.User
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if _, err := tx.Users.GetByEmail(ctx, inv.Email); err == nil {
+			return errEmailTaken
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		if err := tx.Users.Create(ctx, models.User{Name: req.Name, Email: inv.Email, Role: inv.Role, PasswordHash: hash}); err != nil {
+			return err
+		}
+		if user, err = tx.Users.GetByEmail(ctx, inv.Email); err != nil {
+			return err
+		}
+		accepted, err := tx.Invitations.Accept(ctx, inv.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return errInvitationUsed
+		}
+		// The Manager who invited them is who granted the role
+		after := auditedUser(user)
+		after["invitationId"] = inv.ID
+		return tx.Audits.InsertUser(ctx, user.ID, inv.InvitedBy, "accept_invitation", nil, after)
+	})
+	if errors.Is(err, errEmailTaken) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "CONFLICT", "message": "email already has an account"}})
+	}
+	if errors.Is(err, errInvitationUsed) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid or expired invitation"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to accept invitation"}})
+	}
+	tokens, err := h.startSession(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to sign token"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(tokens))
+}
+
+// errInvitationUsed rolls back an account whose invitation was redeemed,
+// revoked or expired while it was being created
+var errInvitationUsed = errors.New("invitation is no longer pending")
+
+// pendingInvitation loads the pending invitation a token was signed for;
+// when it returns false the response has been written and err is what the
+// handler returns. Bad, expired, used and revoked tokens all get the same
+// answer.
+func (h *Handlers) pendingInvitation(ctx context.Context, c *fiber.Ctx, token string) (repositories.Invitation, bool, error) {
+	invalid := func() error {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid or expired invitation"}})
+	}
+	id, ok := h.invitationID(token)
+	if !ok {
+		return repositories.Invitation{}, false, invalid()
+	}
+	inv, err := h.repo.Invitations.Get(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return inv, false, invalid()
+	}
+	if err != nil {
+		return inv, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load invitation"}})
+	}
+	if inv.Status != repositories.InvitationPending {
+		return inv, false, invalid()
+	}
+	return inv, true, nil
+}
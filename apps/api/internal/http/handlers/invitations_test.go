@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestInvitationRoutes_ManagerOnly(t *testing.T) {
+	app, h, reached := managerApp(t, func(r fiber.Router, h *Handlers) {
+		r.Post("/invitations", h.InvitationsCreate)
+		r.Delete("/invitations/:id", h.InvitationsRevoke)
+	})
+
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		// A User who could invite would hand out the Manager role
+		{"create", "POST", "/api/v1/invitations", `{"email":"new@example.com","role":"Manager"}`},
+		{"revoke", "DELETE", "/api/v1/invitations/0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a6b", ""},
+	}
+	for _, role := range []models.Role{models.RoleUser, models.RoleSupervisor} {
+		for _, rq := range requests {
+			t.Run(string(role)+" "+rq.name, func(t *testing.T) {
+				*reached = 0
+				req := httptest.NewRequest(rq.method, rq.path, bytes.NewBufferString(rq.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+roleToken(t, h, role))
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+				assert.Zero(t, *reached, "no handler ran, so nothing was written")
+			})
+		}
+	}
+
+	// A Manager gets through to the handlers, which refuse these before the database
+	manager := roleToken(t, h, models.RoleManager)
+	controls := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/api/v1/invitations", `{"email":"not an email"}`, fiber.StatusBadRequest},
+		{"DELETE", "/api/v1/invitations/42", "", fiber.StatusNotFound},
+	}
+	for _, rq := range controls {
+		*reached = 0
+		req := httptest.NewRequest(rq.method, rq.path, bytes.NewBufferString(rq.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+manager)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, rq.status, resp.StatusCode, rq.path)
+		assert.Equal(t, 1, *reached, rq.path)
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"github.com/it-tms/apps/api/internal/events"
+	"github.com/it-tms/apps/api/internal/mailin"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Inbound email --------------------
+
+// mailTitleLength caps the ticket title taken from a subject
+const mailTitleLength = 200
+
+// errDuplicateMail rolls back a message whose Message-ID was handled before
+var errDuplicateMail = errors.New("message already handled")
+
+// MailInbound takes one raw RFC 5322 message, as piped in by the mail
+// server. A reply quoting a ticket code ("[IT-TMS #42]") from someone taking
+// part in that ticket becomes a comment; anything else opens an issue
+// report. Auto-replies, bounces and repeated Message-IDs are ignored.
+func (h *Handlers) MailInbound(c *fiber.Ctx) error {
+	if h.cfg.MailInboundToken == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "inbound email is disabled"}})
+	}
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.MailInboundToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "invalid token"}})
+	}
+	msg, err := mailin.Parse(bytes.NewReader(c.Body()))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	// Automatic mail includes our own notifications bouncing back
+	if msg.Automatic {
+		return h.mailIgnored(c, "automatic")
+	}
+
+	ctx := context.Background()
+	var sender *models.User
+	if u, err := h.repo.Users.GetByEmail(ctx, msg.From.Address); err == nil {
+		sender = &u
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to look up sender"}})
+	}
+
+	var res fiber.Map
+	var saved []string
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		ticketID, err := replyTicket(ctx, tx, msg, sender)
+		if err != nil {
+			return err
+		}
+		var commentID *string
+		if ticketID != "" {
+			id, paths, err := h.mailComment(ctx, tx, ticketID, msg, sender)
+			saved = paths
+			if err != nil || id == "" {
+				return err
+			}
+			commentID = &id
+			res = fiber.Map{"action": "commented", "ticketId": ticketID, "commentId": id}
+		} else {
+			t, paths, err := h.mailTicket(ctx, tx, msg, sender)
+			saved = paths
+			if err != nil {
+				return err
+			}
+			ticketID = t.ID
+			res = fiber.Map{"action": "created", "ticketId": t.ID, "code": t.Code}
+		}
+		// Without a Message-ID there is nothing to recognise a repeat by
+		if msg.MessageID == "" {
+			return nil
+		}
+		recorded, err := tx.InboundEmails.Record(ctx, msg.MessageID, msg.From.Address, ticketID, commentID)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			return errDuplicateMail
+		}
+		return nil
+	})
+	if err != nil {
+		// Nothing was recorded, so drop the attachments written so far
+		removeUploads(saved)
+		if errors.Is(err, errDuplicateMail) {
+			return h.mailIgnored(c, "duplicate")
+		}
+		log.Error().Err(err).Str("messageId", msg.MessageID).Msg("inbound email failed")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to process message"}})
+	}
+	if res == nil {
+		return h.mailIgnored(c, "empty")
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(res))
+}
+
+// mailIgnored accepts a message without acting on it, so the mail server
+// does not retry
+func (h *Handlers) mailIgnored(c *fiber.Ctx, reason string) error {
+	return c.Status(fiber.StatusAccepted).JSON(h.envelope(fiber.Map{"action": "ignored", "reason": reason}))
+}
+
+// replyTicket returns the ticket a message replies to, or "" for a new
+// request: the subject must quote the ticket's code and the sender must
+// watch it, be the address that opened it by email, or be staff.
+func replyTicket(ctx context.Context, tx *repositories.Repo, msg *mailin.Message, sender *models.User) (string, error) {
+	code, ok := mailin.TicketCode(msg.Subject)
+	if !ok {
+		return "", nil
+	}
+	id, err := tx.Tickets.IDByCode(ctx, code)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if sender != nil && (sender.Role == models.RoleSupervisor || sender.Role == models.RoleManager) {
+		return id, nil
+	}
+	ticket, err := tx.Tickets.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if email, _ := ticket.Details["email"].(map[string]any); email != nil && email["from"] == msg.From.Address {
+		return id, nil
+	}
+	if sender == nil {
+		return "", nil
+	}
+	watching, err := tx.Watchers.IsWatching(ctx, id, sender.ID)
+	if err != nil || !watching {
+		return "", err
+	}
+	return id, nil
+}
+
+// mailTicket opens an issue report for a message, as TicketsCreate does for
+// a form with no priority input
+func (h *Handlers) mailTicket(ctx context.Context, tx *repositories.Repo, msg *mailin.Message, sender *models.User) (models.Ticket, []string, error) {
+	var createdBy *string
+	if sender != nil {
+		createdBy = &sender.ID
+	}
+	title := msg.Subject
+	if title == "" {
+		title = "(no subject)"
+	}
+	if r := []rune(title); len(r) > mailTitleLength {
+		title = string(r[:mailTitleLength])
+	}
+	description := msg.Text
+	if description == "" {
+		description = title
+	}
+	t := models.Ticket{
+		CreatedBy:   createdBy,
+		InitialType: models.InitialIssueReport,
+		Status:      models.StatusPending,
+		Title:       title,
+		Description: description,
+		Details: map[string]any{
+			"source": "email",
+			"email":  map[string]any{"from": msg.From.Address, "name": msg.From.Name, "messageId": msg.MessageID},
+		},
+		Priority: models.PriorityP3,
+	}
+	due, ok, err := h.slaDueDates(ctx, t.Priority, t.InitialType, time.Now())
+	if err != nil {
+		return t, nil, err
+	}
+	if ok {
+		t.ResponseDueAt = &due.Response
+		t.ResolutionDueAt = &due.Resolution
+	}
+	if err := tx.Tickets.Create(ctx, &t); err != nil {
+		return t, nil, err
+	}
+	saved, err := h.saveMailAttachments(msg, func(a mailin.Attachment, path string) error {
+		return tx.Tickets.AddAttachment(ctx, t.ID, a.Filename, a.ContentType, int64(len(a.Data)), path)
+	})
+	if err != nil {
+		return t, saved, err
+	}
+	if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketCreated, TicketID: t.ID, ActorID: createdBy}); err != nil {
+		return t, saved, err
+	}
+	return t, saved, tx.Audits.Insert(ctx, t.ID, createdBy, "create_ticket", nil, t)
+}
+
+// mailComment adds a reply, without the message it quotes, as a comment the
+// way TicketsAddComment does. It returns "" when the reply says nothing.
+func (h *Handlers) mailComment(ctx context.Context, tx *repositories.Repo, ticketID string, msg *mailin.Message, sender *models.User) (string, []string, error) {
+	body := mailin.StripQuoted(msg.Text)
+	if body == "" && len(msg.Attachments) == 0 {
+		return "", nil, nil
+	}
+	if body == "" {
+		names := make([]string, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			names[i] = a.Filename
+		}
+		body = strings.Join(names, ", ")
+	}
+	// Mail from an address without an account is credited to no one
+	var authorID *string
+	if sender != nil {
+		authorID = &sender.ID
+	}
+	commentID, err := tx.Tickets.AddCommentWithID(ctx, ticketID, authorID, body)
+	if err != nil {
+		return "", nil, err
+	}
+	saved, err := h.saveMailAttachments(msg, func(a mailin.Attachment, path string) error {
+		return tx.Tickets.AddCommentAttachment(ctx, commentID, a.Filename, a.ContentType, int64(len(a.Data)), path)
+	})
+	if err != nil {
+		return "", saved, err
+	}
+	if err := tx.Audits.Insert(ctx, ticketID, authorID, "add_comment", nil, body); err != nil {
+		return "", saved, err
+	}
+	if err := tx.Events.Publish(ctx, events.Event{Type: events.CommentCreated, TicketID: ticketID, ActorID: authorID, Data: map[string]any{"commentId": commentID}}); err != nil {
+		return "", saved, err
+	}
+	mentioned, err := h.recordMentions(ctx, tx, ticketID, commentID, authorID, body)
+	if err != nil {
+		return "", saved, err
+	}
+	watchers, err := tx.Watchers.List(ctx, ticketID)
+	if err != nil {
+		return "", saved, err
+	}
+	watchers = withoutIDs(watchers, mentioned)
+	if err := h.notify(ctx, tx, notifications.KindComment, ticketID, authorID, watchers, notifications.Data{Comment: notifications.Excerpt(body, commentExcerptLength)}); err != nil {
+		return "", saved, err
+	}
+	if authorID != nil {
+		if err := h.resumeOnRequesterReply(ctx, tx, ticketID, *authorID); err != nil {
+			return "", saved, err
+		}
+	}
+	return commentID, saved, nil
+}
+
+// saveMailAttachments writes a message's attachments to the upload dir,
+// skipping any over maxUploadSize, and records each with add. It returns
+// the paths written so far so the caller can remove them on rollback.
+func (h *Handlers) saveMailAttachments(msg *mailin.Message, add func(a mailin.Attachment, path string) error) ([]string, error) {
+	var saved []string
+	for _, a := range msg.Attachments {
+		if len(a.Data) > maxUploadSize {
+			log.Warn().Str("messageId", msg.MessageID).Str("filename", a.Filename).Int("size", len(a.Data)).Msg("skipped oversized email attachment")
+			continue
+		}
+		if a.ContentType == "" {
+			a.ContentType = "application/octet-stream"
+		}
+		path, err := h.saveFile(a.Filename, bytes.NewReader(a.Data))
+		if err != nil {
+			return saved, err
+		}
+		saved = append(saved, path)
+		if err := add(a, path); err != nil {
+			return saved, err
+		}
+	}
+	return saved, nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/mentions"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Mentions --------------------
+
+// mentionSearchLimit is how many users an "@email" search looks through
+// for the exact address
+const mentionSearchLimit = 50
+
+// recordMentions stores the people a new comment mentions, makes them
+// watchers unless MENTION_WATCH is off and notifies them. It returns their
+// IDs so they are not also sent the plain comment notification.
+func (h *Handlers) recordMentions(ctx context.Context, tx *repositories.Repo, ticketID, commentID string, authorID *string, body string) ([]string, error) {
+	m := mentions.Parse(body)
+	if m.Empty() {
+		return nil, nil
+	}
+	users, err := resolveMentions(ctx, tx, m)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, u := range users {
+		// Mentioning yourself is not worth a list entry or an email
+		if authorID != nil && u.ID == *authorID {
+			continue
+		}
+		if err := tx.Mentions.Add(ctx, commentID, ticketID, u.ID, authorID, h.cfg.MentionWatch); err != nil {
+			return nil, err
+		}
+		ids = append(ids, u.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, h.notify(ctx, tx, notifications.KindMention, ticketID, authorID, ids, notifications.Data{Comment: notifications.Excerpt(body, commentExcerptLength)})
+}
+
+// resolveMentions finds the users behind "@email" and "@user id" handles,
+// once each; handles that match no one are dropped
+func resolveMentions(ctx context.Context, tx *repositories.Repo, m mentions.Mentions) ([]models.User, error) {
+	var users []models.User
+	add := func(u models.User) {
+		if !slices.ContainsFunc(users, func(v models.User) bool { return v.ID == u.ID }) {
+			users = append(users, u)
+		}
+	}
+	for _, email := range m.Emails {
+		found, err := tx.Users.Search(ctx, email, nil, mentionSearchLimit)
+		if err != nil {
+			return nil, err
+		}
+		// Search matches substrings; a mention names one exact address
+		if i := slices.IndexFunc(found, func(u models.User) bool { return strings.EqualFold(u.Email, email) }); i >= 0 {
+			add(found[i])
+		}
+	}
+	for _, id := range m.UserIDs {
+		u, err := tx.Users.GetByID(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		add(u)
+	}
+	return users, nil
+}
+
+// withoutIDs drops the IDs in exclude from ids
+func withoutIDs(ids, exclude []string) []string {
+	return slices.DeleteFunc(ids, func(id string) bool { return slices.Contains(exclude, id) })
+}
+
+// MeMentions lists the comments that mention the caller, newest first
+// (page, pageSize default 20, at most 50)
+func (h *Handlers) MeMentions(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	pageSize = min(pageSize, 50)
+
+	userID, _ := currentUser(c)
+	items, total, err := h.repo.Mentions.List(context.Background(), userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list mentions"}})
+	}
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return c.JSON(h.envelope(fiber.Map{
+		"mentions": items,
+		"pagination": fiber.Map{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": totalPages,
+			"hasNext":    page < totalPages,
+			"hasPrev":    page > 1,
+		},
+	}))
+}
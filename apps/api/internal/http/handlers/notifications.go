@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Notifications --------------------
+
+// commentExcerptLength bounds the comment text quoted in an email
+const commentExcerptLength = 500
+
+// RunNotifications sends queued notification emails every interval until
+// ctx is done. It does nothing while SMTP_HOST is unset.
+func (h *Handlers) RunNotifications(ctx context.Context, interval time.Duration) {
+	if h.cfg.SMTPHost == "" {
+		return
+	}
+	w := &notifications.Worker{
+		Outbox: h.repo.Notifications,
+		Sender: notifications.SMTPSender{
+			Host:     h.cfg.SMTPHost,
+			Port:     h.cfg.SMTPPort,
+			Username: h.cfg.SMTPUsername,
+			Password: h.cfg.SMTPPassword,
+			From:     h.cfg.MailFrom,
+		},
+	}
+	w.Run(ctx, interval)
+}
+
+// notify queues a kind email about a ticket for each recipient in tx, so it
+// is only sent if the change commits. The actor, deactivated users, users
+// without an email and users whose preferences exclude kind or who take daily
+// digests are skipped; mail in a recipient's quiet hours waits for them to end. Ticket
+// and actor details are filled into data.
+func (h *Handlers) notify(ctx context.Context, tx *repositories.Repo, kind notifications.Kind, ticketID string, actorID *string, recipientIDs []string, data notifications.Data) error {
+	if h.cfg.SMTPHost == "" {
+		return nil
+	}
+	ticket, err := tx.Tickets.GetByID(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	data.TicketCode, data.TicketTitle = ticket.Code, ticket.Title
+	data.TicketURL = h.ticketURL(ticketID)
+	if actorID != nil {
+		actor, err := tx.Users.GetByID(ctx, *actorID)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		data.ActorName = actor.Name
+	}
+
+	now, loc := time.Now(), calendar.LoadLocation(h.cfg.BusinessTimezone)
+	seen := map[string]bool{}
+	for _, id := range recipientIDs {
+		if id == "" || seen[id] || (actorID != nil && id == *actorID) {
+			continue
+		}
+		seen[id] = true
+		user, err := tx.Users.GetByID(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if user.Email == "" || user.DeactivatedAt != nil {
+			continue
+		}
+		prefs, err := tx.Notifications.Preferences(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !prefs.Wants(kind) || prefs.Delivery == notifications.DeliveryDigest {
+			continue
+		}
+		d := data
+		d.RecipientName = user.Name
+		to := (&mail.Address{Name: user.Name, Address: user.Email}).String()
+		msg, err := notifications.Render(h.cfg.MailLocale, kind, to, d)
+		if err != nil {
+			return err
+		}
+		if err := tx.Notifications.Enqueue(ctx, &user.ID, &ticketID, kind, msg, quietUntil(prefs, now, loc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quietUntil is when mail to a user may go out, or nil for now
+func quietUntil(p notifications.Preferences, now time.Time, loc *time.Location) *time.Time {
+	if t := p.QuietUntil(now, loc); !t.IsZero() {
+		return &t
+	}
+	return nil
+}
+
+// RunDigests sends the daily digests once DIGEST_TIME has passed, checking
+// every interval until ctx is done. It does nothing while SMTP_HOST is unset.
+func (h *Handlers) RunDigests(ctx context.Context, interval time.Duration) {
+	if h.cfg.SMTPHost == "" {
+		return
+	}
+	at, err := calendar.ParseTimeOfDay(h.cfg.DigestTime)
+	if err != nil || at >= 24*time.Hour {
+		log.Error().Str("digestTime", h.cfg.DigestTime).Msg("invalid DIGEST_TIME, want HH:MM; daily digests are off")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if queued, err := h.queueDigests(ctx, time.Now(), at); err != nil {
+			log.Error().Err(err).Msg("daily digests failed")
+		} else if queued > 0 {
+			log.Info().Int("queued", queued).Msg("queued daily digests")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueDigests queues the latest daily digest (at is its time of day) for
+// every digest user who has not had it, covering at most the day before it.
+// Users with no activity get no mail. It returns how many were queued.
+func (h *Handlers) queueDigests(ctx context.Context, now time.Time, at time.Duration) (int, error) {
+	loc := calendar.LoadLocation(h.cfg.BusinessTimezone)
+	due := notifications.LastDigestTime(now, at, loc)
+	recipients, err := h.repo.Notifications.DigestRecipients(ctx, due)
+	if err != nil {
+		return 0, err
+	}
+	queued := 0
+	for _, r := range recipients {
+		since := due.AddDate(0, 0, -1)
+		if r.LastDigestAt != nil && r.LastDigestAt.After(since) {
+			since = *r.LastDigestAt
+		}
+		sent := false
+		err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+			// Claiming first keeps replicas from sending the same digest
+			if claimed, err := tx.Notifications.ClaimDigest(ctx, r.UserID, due); err != nil || !claimed {
+				return err
+			}
+			items, err := tx.Notifications.DigestActivity(ctx, r.UserID, since, due)
+			if err != nil {
+				return err
+			}
+			wanted := []notifications.DigestItem{}
+			for _, item := range items {
+				if r.Preferences.Wants(item.Kind) {
+					item.TicketURL = h.ticketURL(item.TicketID)
+					wanted = append(wanted, item)
+				}
+			}
+			if len(wanted) == 0 || r.Email == "" {
+				return nil
+			}
+			to := (&mail.Address{Name: r.Name, Address: r.Email}).String()
+			msg, err := notifications.RenderDigest(h.cfg.MailLocale, to, loc, notifications.Digest{RecipientName: r.Name, Since: since}, wanted)
+			if err != nil {
+				return err
+			}
+			sent = true
+			return tx.Notifications.Enqueue(ctx, &r.UserID, nil, notifications.KindDigest, msg, quietUntil(r.Preferences, now, loc))
+		})
+		if err != nil {
+			return queued, err
+		}
+		if sent {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// ticketURL links to a ticket in the web app, in the mail locale
+func (h *Handlers) ticketURL(ticketID string) string {
+	return h.webURL("/tickets/" + ticketID)
+}
+
+// webURL links to a page of the web app, in the mail locale
+func (h *Handlers) webURL(path string) string {
+	locale := h.cfg.MailLocale
+	if locale != notifications.LocaleThai && locale != notifications.LocaleEnglish {
+		locale = notifications.DefaultLocale
+	}
+	return strings.TrimRight(h.cfg.WebAppURL, "/") + "/" + locale + path
+}
+
+// -------------------- Notification Preferences --------------------
+
+// NotificationPrefsReq patches a user's notification preferences: omitted
+// fields keep their value, and quiet hours of "" turn them off.
+type NotificationPrefsReq struct {
+	Channel         *notifications.Channel  `json:"channel"`
+	EventTypes      []notifications.Kind    `json:"eventTypes"`
+	Delivery        *notifications.Delivery `json:"delivery"`
+	QuietHoursStart *string                 `json:"quietHoursStart"`
+	QuietHoursEnd   *string                 `json:"quietHoursEnd"`
+}
+
+// apply copies the fields set in the request onto p, validating the result
+func (r NotificationPrefsReq) apply(p *notifications.Preferences) error {
+	if r.Channel != nil {
+		p.Channel = *r.Channel
+	}
+	if r.EventTypes != nil {
+		p.EventTypes = []notifications.Kind{}
+		for _, k := range r.EventTypes {
+			if !slices.Contains(p.EventTypes, k) {
+				p.EventTypes = append(p.EventTypes, k)
+			}
+		}
+	}
+	if r.Delivery != nil {
+		p.Delivery = *r.Delivery
+	}
+	for _, f := range []struct{ req, pref **string }{
+		{&r.QuietHoursStart, &p.QuietHoursStart},
+		{&r.QuietHoursEnd, &p.QuietHoursEnd},
+	} {
+		switch {
+		case *f.req == nil:
+		case **f.req == "":
+			*f.pref = nil
+		default:
+			v := **f.req
+			*f.pref = &v
+		}
+	}
+	return p.Validate()
+}
+
+func (h *Handlers) ProfileNotificationsGet(c *fiber.Ctx) error {
+	userID, _ := currentUser(c)
+	prefs, err := h.repo.Notifications.Preferences(context.Background(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load notification preferences"}})
+	}
+	return c.JSON(h.envelope(prefs))
+}
+
+func (h *Handlers) ProfileNotificationsUpdate(c *fiber.Ctx) error {
+	userID, _ := currentUser(c)
+	var body NotificationPrefsReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	ctx := context.Background()
+	prefs, err := h.repo.Notifications.Preferences(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load notification preferences"}})
+	}
+	if err := body.apply(&prefs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := h.repo.Notifications.SavePreferences(ctx, userID, prefs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save notification preferences"}})
+	}
+	return c.JSON(h.envelope(prefs))
+}
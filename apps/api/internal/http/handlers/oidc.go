@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/oidc"
+	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/pkg/config"
+)
+
+// -------------------- Single sign-on --------------------
+
+const (
+	// oidcCookie carries the state, nonce and code verifier of a sign-in
+	// from the login route to the callback, signed so it cannot be forged
+	oidcCookie     = "oidc_login"
+	oidcCookiePath = "/api/v1/auth/oidc"
+	// oidcLoginTTL is how long a sign-in at the provider may take
+	oidcLoginTTL = 10 * time.Minute
+	// oidcAudience keeps the login cookie from passing for an access token
+	oidcAudience = "oidc-login"
+)
+
+// Reasons the callback sends the browser back to the sign-in page with
+const (
+	ssoFailed   = "sso_failed"
+	ssoDenied   = "sso_denied"
+	ssoConflict = "sso_conflict"
+)
+
+// ssoSettings is the single sign-on provider and how its groups map to
+// roles; nil while OIDC_ISSUER_URL is unset
+type ssoSettings struct {
+	client      *oidc.Client
+	rules       []oidc.RoleRule
+	defaultRole models.Role
+}
+
+// newSSO reads the single sign-on settings. Invalid role rules turn single
+// sign-on off rather than let someone in with the wrong role.
+func newSSO(cfg config.Config) *ssoSettings {
+	if cfg.OIDCIssuerURL == "" {
+		return nil
+	}
+	rules, err := oidc.ParseRoleRules(cfg.OIDCRoleRules)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid OIDC_ROLE_RULES; single sign-on is off")
+		return nil
+	}
+	def, err := oidc.ParseRole(cfg.OIDCDefaultRole)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid OIDC_DEFAULT_ROLE; single sign-on is off")
+		return nil
+	}
+	client := oidc.New(oidc.Config{
+		IssuerURL:    cfg.OIDCIssuerURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       strings.Fields(cfg.OIDCScopes),
+		GroupsClaim:  cfg.OIDCGroupsClaim,
+	}, nil)
+	return &ssoSettings{client: client, rules: rules, defaultRole: def}
+}
+
+// AuthOIDCLogin sends the browser to the single sign-on provider. The
+// optional redirect query is the web app path to land on afterwards.
+func (h *Handlers) AuthOIDCLogin(c *fiber.Ctx) error {
+	if h.sso == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "single sign-on is disabled"}})
+	}
+	state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.RandomString()
+	authURL, err := h.sso.client.AuthURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		log.Error().Err(err).Msg("single sign-on provider unavailable")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_GATEWAY", "message": "single sign-on provider unavailable"}})
+	}
+	exp := time.Now().Add(oidcLoginTTL)
+	cookie, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":      oidcAudience,
+		"exp":      exp.Unix(),
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"redirect": safeRedirect(c.Query("redirect")),
+	}).SignedString([]byte(h.cfg.JWTSecret))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to start sign-in"}})
+	}
+	// Lax, as the provider sends the browser back with a cross-site GET
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcCookie,
+		Value:    cookie,
+		HTTPOnly: true,
+		Secure:   h.cfg.SecureCookies,
+		Path:     oidcCookiePath,
+		SameSite: "Lax",
+		Expires:  exp,
+	})
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// AuthOIDCCallback finishes a sign-in at the provider. The user is found by
+// their provider subject, or else by an email the provider says it verified
+// and linked, or else created; their role follows OIDC_ROLE_RULES on every
+// sign-in. It starts a session
+// like SignIn and sends the browser to the web app, or back to its sign-in
+// page with an error query when the sign-in is refused.
+func (h *Handlers) AuthOIDCCallback(c *fiber.Ctx) error {
+	if h.sso == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "single sign-on is disabled"}})
+	}
+	login := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(c.Cookies(oidcCookie), login, func(t *jwt.Token) (any, error) {
+		return []byte(h.cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(oidcAudience), jwt.WithExpirationRequired())
+	// Each login cookie is good for one callback
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcCookie,
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   h.cfg.SecureCookies,
+		Path:     oidcCookiePath,
+		SameSite: "Lax",
+		MaxAge:   -1,
+	})
+	state, _ := login["state"].(string)
+	if err != nil || state == "" || c.Query("state") != state {
+		return c.Redirect(h.signInURL(ssoFailed), fiber.StatusFound)
+	}
+	if e := c.Query("error"); e != "" {
+		log.Warn().Str("error", e).Str("description", c.Query("error_description")).Msg("single sign-on refused by provider")
+		return c.Redirect(h.signInURL(ssoDenied), fiber.StatusFound)
+	}
+	nonce, _ := login["nonce"].(string)
+	verifier, _ := login["verifier"].(string)
+	redirect, _ := login["redirect"].(string)
+
+	ctx := context.Background()
+	id, err := h.sso.client.Exchange(ctx, c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Error().Err(err).Msg("single sign-on code exchange failed")
+		return c.Redirect(h.signInURL(ssoFailed), fiber.StatusFound)
+	}
+	if id.Email == "" || validateEmail(id.Email) != nil {
+		log.Warn().Str("subject", id.Subject).Msg("single sign-on without an email")
+		return c.Redirect(h.signInURL(ssoDenied), fiber.StatusFound)
+	}
+	role := oidc.MapRole(h.sso.rules, id.Groups, h.sso.defaultRole)
+	if role == "" {
+		log.Info().Str("email", id.Email).Strs("groups", id.Groups).Msg("single sign-on: no role for groups")
+		return c.Redirect(h.signInURL(ssoDenied), fiber.StatusFound)
+	}
+
+	user, err := h.ssoUser(ctx, id, role)
+	switch {
+	case errors.Is(err, errSSODeactivated):
+		return c.Redirect(h.signInURL(ssoDenied), fiber.StatusFound)
+	case errors.Is(err, errSSOLinkedElsewhere), errors.Is(err, errSSOUnverifiedEmail):
+		return c.Redirect(h.signInURL(ssoConflict), fiber.StatusFound)
+	case err != nil:
+		log.Error().Err(err).Msg("single sign-on user provisioning failed")
+		return c.Redirect(h.signInURL(ssoFailed), fiber.StatusFound)
+	}
+	if _, err := h.startSession(c, user); err != nil {
+		return c.Redirect(h.signInURL(ssoFailed), fiber.StatusFound)
+	}
+	return c.Redirect(strings.TrimRight(h.cfg.WebAppURL, "/")+redirect, fiber.StatusFound)
+}
+
+var (
+	errSSODeactivated     = errors.New("account is deactivated")
+	errSSOLinkedElsewhere = errors.New("account is linked to another single sign-on identity")
+	errSSOUnverifiedEmail = errors.New("email is not verified by the provider")
+)
+
+// ssoUser finds, links or provisions the user signing in as id, giving them
+// role. The provider is who grants it, so it is audited without an actor.
+func (h *Handlers) ssoUser(ctx context.Context, id oidc.Identity, role models.Role) (models.User, error) {
+	var user models.User
+	err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		var err error
+		user, err = tx.Users.GetByOIDCSubject(ctx, id.Subject)
+		if errors.Is(err, repositories.ErrNotFound) {
+			user, err = tx.Users.GetByEmail(ctx, id.Email)
+			switch {
+			case errors.Is(err, repositories.ErrNotFound):
+				name := strings.TrimSpace(id.Name)
+				if validateName(name) != nil {
+					name, _, _ = strings.Cut(id.Email, "@")
+				}
+				if user, err = tx.Users.CreateOIDC(ctx, models.User{Name: name, Email: id.Email, Role: role}, id.Subject); err != nil {
+					return err
+				}
+				return tx.Audits.InsertUser(ctx, user.ID, nil, "sso_provision", nil, auditedUser(user))
+			case err != nil:
+				return err
+			case user.OIDCSubject != nil:
+				return errSSOLinkedElsewhere
+			case !id.EmailVerified:
+				// Linking hands the account, its role included, to whoever
+				// the provider vouches for; an address it has not verified
+				// may belong to someone else
+				log.Warn().Str("subject", id.Subject).Str("email", id.Email).Msg("single sign-on: not linking an account by an unverified email")
+				return errSSOUnverifiedEmail
+			}
+			linked, err := tx.Users.LinkOIDC(ctx, user.ID, id.Subject)
+			if err != nil {
+				return err
+			}
+			if !linked {
+				return errSSOLinkedElsewhere
+			}
+			if err := tx.Audits.InsertUser(ctx, user.ID, nil, "sso_link", nil, fiber.Map{"email": id.Email}); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		if user.DeactivatedAt != nil {
+			return errSSODeactivated
+		}
+		if user.Role == role {
+			return nil
+		}
+		before := user.Role
+		user.Role = role
+		if err := tx.Users.UpdateRole(ctx, user.ID, role); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, user.ID, nil, "update_role", fiber.Map{"role": before}, fiber.Map{"role": role})
+	})
+	return user, err
+}
+
+// signInURL is the web app sign-in page showing reason
+func (h *Handlers) signInURL(reason string) string {
+	return h.webURL("/sign-in?error=" + url.QueryEscape(reason))
+}
+
+// safeRedirect keeps a post-sign-in redirect to a path in the web app, so
+// the login route cannot be used to send people elsewhere
+func safeRedirect(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.ContainsAny(p, "\\\r\n") {
+		return "/dashboard"
+	}
+	return p
+}
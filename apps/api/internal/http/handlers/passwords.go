@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Passwords --------------------
+
+// bcryptCost is the work factor of every stored password hash
+const bcryptCost = 12
+
+// hashPassword hashes a password for storing
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	return string(hash), err
+}
+
+// PasswordForgotReq asks for a reset link
+type PasswordForgotReq struct {
+	Email string `json:"email"`
+}
+
+// PasswordResetReq sets a new password with a reset token
+type PasswordResetReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// PasswordChangeReq changes the caller's password
+type PasswordChangeReq struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// AuthPasswordForgot emails a password reset link to an active account
+// that does not sign in through single sign-on, replacing any earlier link.
+// It answers the same whether or not the address has such an account, so it
+// cannot be used to find one. It is off while SMTP_HOST is unset, as there
+// is no other way to deliver the link.
+func (h *Handlers) AuthPasswordForgot(c *fiber.Ctx) error {
+	if h.cfg.SMTPHost == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "password reset by email is disabled"}})
+	}
+	var req PasswordForgotReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if err := validateEmail(req.Email); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	ctx := context.Background()
+	err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		user, err := tx.Users.GetByEmail(ctx, req.Email)
+		// Single sign-on accounts have no password here to reset
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && (user.DeactivatedAt != nil || user.OIDCSubject != nil)) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		token, err := tx.PasswordResets.Create(ctx, user.ID, h.cfg.PasswordResetTTL)
+		if err != nil {
+			return err
+		}
+		return h.mailPasswordReset(ctx, tx, user, token)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to send reset link"}})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(h.envelope(fiber.Map{"message": "if the address has an account, a reset link is on its way"}))
+}
+
+// mailPasswordReset queues the reset link email in tx
+func (h *Handlers) mailPasswordReset(ctx context.Context, tx *repositories.Repo, user models.User, token string) error {
+	to := (&mail.Address{Name: user.Name, Address: user.Email}).String()
+	msg, err := notifications.RenderPasswordReset(h.cfg.MailLocale, to, calendar.LoadLocation(h.cfg.BusinessTimezone), notifications.PasswordReset{
+		RecipientName: user.Name,
+		URL:           h.webURL("/reset-password?token=" + url.QueryEscape(token)),
+		ExpiresAt:     time.Now().Add(h.cfg.PasswordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Notifications.Enqueue(ctx, &user.ID, nil, notifications.KindPasswordReset, msg, nil)
+}
+
+// AuthPasswordReset sets a new password with a reset token, signs the user
+// out of every session and signs them in on a new one. Each token works
+// once.
+func (h *Handlers) AuthPasswordReset(c *fiber.Ctx) error {
+	var req PasswordResetReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid or expired reset token"}})
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to hash password"}})
+	}
+
+	ctx := context.Background()
+	var user models.User
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		userID, err := tx.PasswordResets.Use(ctx, req.Token)
+		if err != nil {
+			return err
+		}
+		if user, err = tx.Users.GetByID(ctx, userID); err != nil {
+			return err
+		}
+		// A link sent before the account was switched off does not reopen it
+		if user.DeactivatedAt != nil {
+			return repositories.ErrNotFound
+		}
+		if err := tx.Users.SetPassword(ctx, user.ID, hash, nil); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, user.ID, &user.ID, "reset_password", nil, nil)
+	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid or expired reset token"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to reset password"}})
+	}
+	tokens, err := h.startSession(c, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to sign token"}})
+	}
+	return c.JSON(h.envelope(tokens))
+}
+
+// ProfilePassword changes the caller's password once they confirm the
+// current one, and signs out their other sessions. Single sign-on accounts
+// change theirs at the provider.
+func (h *Handlers) ProfilePassword(c *fiber.Ctx) error {
+	var req PasswordChangeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	userID, _ := currentUser(c)
+	ctx := context.Background()
+	user, err := h.repo.Users.GetByID(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load user"}})
+	}
+	if user.OIDCSubject != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "CONFLICT", "message": "password is managed by single sign-on"}})
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "current password is incorrect"}})
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to hash password"}})
+	}
+	var keep *string
+	if id := currentSessionID(c); id != "" {
+		keep = &id
+	}
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Users.SetPassword(ctx, userID, hash, keep); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, userID, &userID, "change_password", nil, nil)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to change password"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"changed": true}))
+}
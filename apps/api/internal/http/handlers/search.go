@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// -------------------- Search --------------------
+
+// Search finds tickets (title, description, details) and comments matching
+// every term of q, ranked by relevance, with highlighted snippets.
+func (h *Handlers) Search(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "q is required"}})
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	res, err := h.repo.Search.Search(context.Background(), q, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "search failed"}})
+	}
+	return c.JSON(h.envelope(res))
+}
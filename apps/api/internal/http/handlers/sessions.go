@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/it-tms/apps/api/internal/http/middleware"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Sessions --------------------
+
+// refreshCookie holds the refresh token. It is only sent to the auth
+// routes, which are the only ones that read it.
+const (
+	refreshCookie     = "refresh_token"
+	refreshCookiePath = "/api/v1/auth"
+)
+
+// Sessions is what the auth middleware checks tokens against
+func (h *Handlers) Sessions() middleware.SessionChecker {
+	return h.repo.Sessions
+}
+
+// startSession signs user in on a new session
+func (h *Handlers) startSession(c *fiber.Ctx, user models.User) (fiber.Map, error) {
+	s, refresh, err := h.repo.Sessions.Create(context.Background(), user.ID, c.Get(fiber.HeaderUserAgent), c.IP(), h.cfg.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return h.issueTokens(c, user, s, refresh)
+}
+
+// issueTokens sets the access and refresh token cookies for a session and
+// returns both tokens for clients that send them as headers
+func (h *Handlers) issueTokens(c *fiber.Ctx, user models.User, s repositories.Session, refresh string) (fiber.Map, error) {
+	tok, err := h.issueJWT(user, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	h.setAuthCookie(c, tok)
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshCookie,
+		Value:    refresh,
+		HTTPOnly: true,
+		Secure:   h.cfg.SecureCookies,
+		Path:     refreshCookiePath,
+		SameSite: "Strict",
+		Expires:  s.ExpiresAt,
+	})
+	return fiber.Map{
+		"token":        tok,
+		"refreshToken": refresh,
+		"expiresIn":    int(h.cfg.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// clearAuthCookie drops the access token from the browser
+func (h *Handlers) clearAuthCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "token",
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   h.cfg.SecureCookies,
+		Path:     "/",
+		SameSite: "Lax",
+		MaxAge:   -1,
+	})
+}
+
+// clearRefreshCookie drops the refresh token from the browser
+func (h *Handlers) clearRefreshCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshCookie,
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   h.cfg.SecureCookies,
+		Path:     refreshCookiePath,
+		SameSite: "Strict",
+		MaxAge:   -1,
+	})
+}
+
+// AuthRefresh trades a refresh token, from its cookie or the
+// {"refreshToken"} body, for a new access token and refresh token. Each
+// refresh token works once, and the access token carries the user's
+// current role.
+func (h *Handlers) AuthRefresh(c *fiber.Ctx) error {
+	token := c.Cookies(refreshCookie)
+	if token == "" {
+		var body struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		// An empty body just means there is no token
+		_ = c.BodyParser(&body)
+		token = body.RefreshToken
+	}
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "missing refresh token"}})
+	}
+	ctx := context.Background()
+	s, refresh, err := h.repo.Sessions.Rotate(ctx, token, h.cfg.RefreshTokenTTL)
+	if errors.Is(err, repositories.ErrNotFound) {
+		h.clearRefreshCookie(c)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "invalid refresh token"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to refresh session"}})
+	}
+	user, err := h.repo.Users.GetByID(ctx, s.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load user"}})
+	}
+	tokens, err := h.issueTokens(c, user, s, refresh)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to sign token"}})
+	}
+	return c.JSON(h.envelope(tokens))
+}
+
+// endSession revokes the session behind the request's refresh token and
+// access token, even an expired one, and clears the refresh cookie
+func (h *Handlers) endSession(c *fiber.Ctx) error {
+	ctx := context.Background()
+	if token := c.Cookies(refreshCookie); token != "" {
+		if err := h.repo.Sessions.RevokeByRefreshToken(ctx, token); err != nil {
+			return err
+		}
+	}
+	if claims := h.signedClaims(middleware.TokenFromRequest(c)); claims != nil {
+		sub, _ := claims["sub"].(string)
+		jti, _ := claims["jti"].(string)
+		if sub != "" && jti != "" {
+			if _, err := h.repo.Sessions.Revoke(ctx, jti, sub); err != nil {
+				return err
+			}
+		}
+	}
+	h.clearRefreshCookie(c)
+	return nil
+}
+
+// signedClaims returns the claims of a token this server signed, expired or
+// not, and nil for anything else
+func (h *Handlers) signedClaims(tok string) jwt.MapClaims {
+	if tok == "" {
+		return nil
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.NewParser(jwt.WithoutClaimsValidation(), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})).
+		ParseWithClaims(tok, claims, func(*jwt.Token) (any, error) { return []byte(h.cfg.JWTSecret), nil })
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// currentSessionID returns the session of the caller's access token
+func currentSessionID(c *fiber.Ctx) string {
+	claims, _ := c.Locals("user").(jwt.MapClaims)
+	jti, _ := claims["jti"].(string)
+	return jti
+}
+
+// sessionView marks which of the listed sessions made the request
+type sessionView struct {
+	repositories.Session
+	Current bool `json:"current"`
+}
+
+// MeSessions lists the caller's signed-in sessions, most recently used first
+func (h *Handlers) MeSessions(c *fiber.Ctx) error {
+	userID, _ := currentUser(c)
+	sessions, err := h.repo.Sessions.List(context.Background(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list sessions"}})
+	}
+	current := currentSessionID(c)
+	views := make([]sessionView, len(sessions))
+	for i, s := range sessions {
+		views[i] = sessionView{Session: s, Current: s.ID == current}
+	}
+	return c.JSON(h.envelope(fiber.Map{"sessions": views}))
+}
+
+// MeSessionsRevokeOthers signs the caller out everywhere but this session
+func (h *Handlers) MeSessionsRevokeOthers(c *fiber.Ctx) error {
+	userID, _ := currentUser(c)
+	var except *string
+	if id := currentSessionID(c); id != "" {
+		except = &id
+	}
+	n, err := h.repo.Sessions.RevokeAll(context.Background(), userID, except)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to revoke sessions"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"revoked": n}))
+}
+
+// MeSessionsRevoke signs one of the caller's sessions out; revoking the
+// current one also clears its cookies
+func (h *Handlers) MeSessionsRevoke(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userID, _ := currentUser(c)
+	revoked, err := h.repo.Sessions.Revoke(context.Background(), id, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to revoke session"}})
+	}
+	if !revoked {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "session not found"}})
+	}
+	if id == currentSessionID(c) {
+		h.clearAuthCookie(c)
+		h.clearRefreshCookie(c)
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id, "revoked": true}))
+}
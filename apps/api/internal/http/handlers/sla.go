@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/internal/sla"
+)
+
+// -------------------- SLA --------------------
+
+// slaPolicy resolves the policy for a ticket. When no policy is configured for its
+// priority the built-in defaults apply.
+func (h *Handlers) slaPolicy(ctx context.Context, prio models.TicketPriority, initialType models.TicketInitialType) (models.SLAPolicy, bool, error) {
+	policies, err := h.repo.SLA.ListPolicies(ctx)
+	if err != nil {
+		return models.SLAPolicy{}, false, err
+	}
+	policy, ok := sla.Resolve(policies, prio, initialType)
+	return policy, ok, nil
+}
+
+// slaDueDates resolves the policy for a ticket and computes its deadlines from start,
+// counting business hours only. Failing to load the policies is an error rather than
+// a reason to stamp the built-in defaults.
+func (h *Handlers) slaDueDates(ctx context.Context, prio models.TicketPriority, initialType models.TicketInitialType, start time.Time) (sla.Due, bool, error) {
+	policy, ok, err := h.slaPolicy(ctx, prio, initialType)
+	if err != nil || !ok {
+		return sla.Due{}, false, err
+	}
+	return sla.DueDates(h.businessCalendar(ctx), start, policy), true, nil
+}
+
+// recalculateSLA re-stamps the deadlines of a ticket after its priority or type changed.
+// Deadlines are measured from ticket creation, or from its last reopen, and pushed back by
+// the business time the ticket has spent paused since. The new deadlines are written through r.
+func (h *Handlers) recalculateSLA(ctx context.Context, r *repositories.Repo, id string) error {
+	ticket, err := r.Tickets.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	policy, ok, err := h.slaPolicy(ctx, ticket.Priority, ticket.InitialType)
+	if err != nil || !ok {
+		return err
+	}
+	start := ticket.CreatedAt
+	if ticket.LastReopenedAt != nil {
+		start = *ticket.LastReopenedAt
+	}
+	cal := h.businessCalendar(ctx)
+	due := sla.DueDates(cal, start, policy).Extend(cal, ticket.SLAPaused)
+	return r.Tickets.UpdateSLADueDates(ctx, id, due.Response, due.Resolution)
+}
+
+// resumeSLA pushes the deadlines of a paused ticket back by the business time it spent paused,
+// and adds that time to the ticket's paused total for later recalculations
+func (h *Handlers) resumeSLA(ctx context.Context, r *repositories.Repo, ticket models.Ticket, now time.Time) error {
+	if ticket.PausedAt == nil {
+		return nil
+	}
+	cal := h.businessCalendar(ctx)
+	if err := r.Tickets.AddSLAPausedTime(ctx, ticket.ID, cal.Between(*ticket.PausedAt, now)); err != nil {
+		return err
+	}
+	if ticket.ResponseDueAt == nil || ticket.ResolutionDueAt == nil {
+		return nil
+	}
+	response := sla.Resume(cal, *ticket.ResponseDueAt, *ticket.PausedAt, now)
+	resolution := sla.Resume(cal, *ticket.ResolutionDueAt, *ticket.PausedAt, now)
+	return r.Tickets.UpdateSLADueDates(ctx, ticket.ID, response, resolution)
+}
+
+type SLAPolicyReq struct {
+	Priority          models.TicketPriority     `json:"priority"`
+	InitialType       *models.TicketInitialType `json:"initialType"`
+	ResponseMinutes   int32                     `json:"responseMinutes"`
+	ResolutionMinutes int32                     `json:"resolutionMinutes"`
+}
+
+func (h *Handlers) SLAPoliciesList(c *fiber.Ctx) error {
+	ctx := context.Background()
+	policies, err := h.repo.SLA.ListPolicies(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list sla policies"}})
+	}
+	return c.JSON(h.envelope(policies))
+}
+
+func (h *Handlers) SLAPoliciesUpsert(c *fiber.Ctx) error {
+	var body SLAPolicyReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	switch body.Priority {
+	case models.PriorityP0, models.PriorityP1, models.PriorityP2, models.PriorityP3:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid priority"}})
+	}
+	if body.InitialType != nil {
+		switch *body.InitialType {
+		case models.InitialIssueReport, models.InitialChangeRequestNormal, models.InitialServiceDataCorrection,
+			models.InitialServiceDataExtraction, models.InitialServiceAdvisory, models.InitialServiceGeneral:
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid initialType"}})
+		}
+	}
+	if body.ResponseMinutes <= 0 || body.ResolutionMinutes <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "responseMinutes and resolutionMinutes must be positive"}})
+	}
+	if body.ResponseMinutes > body.ResolutionMinutes {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "response target cannot exceed resolution target"}})
+	}
+
+	ctx := context.Background()
+	policy, err := h.repo.SLA.UpsertPolicy(ctx, models.SLAPolicy{
+		Priority:          body.Priority,
+		InitialType:       body.InitialType,
+		ResponseMinutes:   body.ResponseMinutes,
+		ResolutionMinutes: body.ResolutionMinutes,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save sla policy"}})
+	}
+	return c.JSON(h.envelope(policy))
+}
+
+func (h *Handlers) SLAPoliciesDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ctx := context.Background()
+	if err := h.repo.SLA.DeletePolicy(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "sla policy not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to delete sla policy"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id}))
+}
+
+func (h *Handlers) MetricsSLA(c *fiber.Ctx) error {
+	ctx := context.Background()
+
+	// Year defaults to the current year; month narrows the report to a single month
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil || y < 2000 || y > 2100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_PARAMETERS", "message": "invalid year"}})
+		}
+		year = y
+	}
+	var month *int
+	if monthStr := c.Query("month"); monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_PARAMETERS", "message": "invalid month"}})
+		}
+		month = &m
+	}
+
+	data, err := h.repo.Metrics.SLACompliance(ctx, year, month)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "sla metrics failed"}})
+	}
+	return c.JSON(h.envelope(data))
+}
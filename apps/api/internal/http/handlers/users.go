@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- User admin --------------------
+
+// minPasswordLength is the shortest password accepted for a new account
+const minPasswordLength = 8
+
+// UserCreateReq is a Manager opening an account for someone
+type UserCreateReq struct {
+	Name     string      `json:"name"`
+	Email    string      `json:"email"`
+	Password string      `json:"password"`
+	Role     models.Role `json:"role"`
+}
+
+// validate trims the request and checks every field
+func (r *UserCreateReq) validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Email = strings.TrimSpace(r.Email)
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if err := validateEmail(r.Email); err != nil {
+		return err
+	}
+	if err := validatePassword(r.Password); err != nil {
+		return err
+	}
+	if r.Role == "" {
+		r.Role = models.RoleUser
+	}
+	if !assignableRole(r.Role) {
+		return errors.New("role must be User, Supervisor or Manager")
+	}
+	return nil
+}
+
+// UserUpdateReq changes a user's role
+type UserUpdateReq struct {
+	Role models.Role `json:"role"`
+}
+
+// validateName accepts a display name of 1 to 100 characters
+func validateName(name string) error {
+	if name == "" || len([]rune(name)) > 100 {
+		return errors.New("name must be 1 to 100 characters")
+	}
+	return nil
+}
+
+// validatePassword enforces minPasswordLength
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// validateEmail accepts a bare address such as somchai@example.com
+func validateEmail(email string) error {
+	a, err := mail.ParseAddress(email)
+	if err != nil || a.Address != email {
+		return errors.New("invalid email address")
+	}
+	return nil
+}
+
+// assignableRole reports whether a role can be given to an account
+func assignableRole(r models.Role) bool {
+	return r == models.RoleUser || r == models.RoleSupervisor || r == models.RoleManager
+}
+
+// auditedUser is what the audit log keeps of an account
+func auditedUser(u models.User) fiber.Map {
+	return fiber.Map{"id": u.ID, "name": u.Name, "email": u.Email, "role": u.Role, "deactivatedAt": u.DeactivatedAt}
+}
+
+// UsersList lists accounts by name (q, role, status=active|deactivated,
+// page, pageSize default 20, at most 100)
+func (h *Handlers) UsersList(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	pageSize = min(pageSize, 100)
+
+	f := repositories.UserFilters{Query: strings.TrimSpace(c.Query("q")), Role: models.Role(c.Query("role"))}
+	if f.Role != "" && !assignableRole(f.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid role"}})
+	}
+	switch status := c.Query("status"); status {
+	case "":
+	case "active", "deactivated":
+		active := status == "active"
+		f.Active = &active
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "status must be active or deactivated"}})
+	}
+
+	users, total, err := h.repo.Users.List(context.Background(), f, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list users"}})
+	}
+	for i := range users {
+		users[i].ProfilePicture = h.convertProfilePictureToURL(users[i].ProfilePicture)
+	}
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return c.JSON(h.envelope(fiber.Map{
+		"users": users,
+		"pagination": fiber.Map{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": totalPages,
+			"hasNext":    page < totalPages,
+			"hasPrev":    page > 1,
+		},
+	}))
+}
+
+// UsersCreate opens an account with a role and an initial password
+func (h *Handlers) UsersCreate(c *fiber.Ctx) error {
+	var req UserCreateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	if err := req.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to hash password"}})
+	}
+
+	actorID, _ := currentUser(c)
+	ctx := context.Background()
+	var user models.User
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if _, err := tx.Users.GetByEmail(ctx, req.Email); err == nil {
+			return errEmailTaken
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		if err := tx.Users.Create(ctx, models.User{Name: req.Name, Email: req.Email, Role: req.Role, PasswordHash: hash}); err != nil {
+			return err
+		}
+		if user, err = tx.Users.GetByEmail(ctx, req.Email); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, user.ID, &actorID, "create_user", nil, auditedUser(user))
+	})
+	if errors.Is(err, errEmailTaken) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "CONFLICT", "message": "email already exists"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to create user"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(user))
+}
+
+// errEmailTaken rolls back an account whose email is already in use
+var errEmailTaken = errors.New("email already exists")
+
+// UsersUpdate changes a user's role, which signs them out everywhere so
+// their next token carries it. Managers cannot change their own role.
+func (h *Handlers) UsersUpdate(c *fiber.Ctx) error {
+	var req UserUpdateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	if !assignableRole(req.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "role must be User, Supervisor or Manager"}})
+	}
+	id := c.Params("id")
+	actorID, _ := currentUser(c)
+	if id == actorID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "you cannot change your own role"}})
+	}
+	ctx := context.Background()
+	user, ok, err := h.adminTarget(ctx, c, id)
+	if !ok {
+		return err
+	}
+	if user.Role == req.Role {
+		return c.JSON(h.envelope(user))
+	}
+	before := user.Role
+	user.Role = req.Role
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		if err := tx.Users.UpdateRole(ctx, id, req.Role); err != nil {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, id, &actorID, "update_role", fiber.Map{"role": before}, fiber.Map{"role": req.Role})
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to update user"}})
+	}
+	return c.JSON(h.envelope(user))
+}
+
+// UsersDeactivate switches an account off and signs it out everywhere
+func (h *Handlers) UsersDeactivate(c *fiber.Ctx) error {
+	return h.setDeactivated(c, true)
+}
+
+// UsersReactivate lets a deactivated account sign in again
+func (h *Handlers) UsersReactivate(c *fiber.Ctx) error {
+	return h.setDeactivated(c, false)
+}
+
+func (h *Handlers) setDeactivated(c *fiber.Ctx, deactivated bool) error {
+	id := c.Params("id")
+	actorID, _ := currentUser(c)
+	if deactivated && id == actorID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "you cannot deactivate your own account"}})
+	}
+	ctx := context.Background()
+	if _, ok, err := h.adminTarget(ctx, c, id); !ok {
+		return err
+	}
+	action := "reactivate_user"
+	if deactivated {
+		action = "deactivate_user"
+	}
+	var user models.User
+	err := h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		changed, err := tx.Users.SetDeactivated(ctx, id, deactivated)
+		if err != nil {
+			return err
+		}
+		if user, err = tx.Users.GetByID(ctx, id); err != nil || !changed {
+			return err
+		}
+		return tx.Audits.InsertUser(ctx, id, &actorID, action, nil, auditedUser(user))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to update user"}})
+	}
+	return c.JSON(h.envelope(user))
+}
+
+// adminTarget loads the user an admin route acts on; when it returns false
+// the response has been written and err is what the handler returns
+func (h *Handlers) adminTarget(ctx context.Context, c *fiber.Ctx, id string) (models.User, bool, error) {
+	user, err := h.repo.Users.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return user, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "user not found"}})
+	}
+	if err != nil {
+		return user, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load user"}})
+	}
+	return user, true, nil
+}
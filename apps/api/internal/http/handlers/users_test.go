@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestUserAdminRoutes_MalformedIDIsNotFound(t *testing.T) {
+	app, h, _ := managerApp(t, func(r fiber.Router, h *Handlers) {
+		r.Patch("/users/:id", h.UsersUpdate)
+		r.Post("/users/:id/deactivate", h.UsersDeactivate)
+		r.Post("/users/:id/reactivate", h.UsersReactivate)
+	})
+	manager := roleToken(t, h, models.RoleManager)
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{"PATCH", "/api/v1/users/42", `{"role":"Supervisor"}`},
+		{"POST", "/api/v1/users/42/deactivate", ""},
+		{"POST", "/api/v1/users/not-a-uuid/reactivate", ""},
+	}
+	for _, rq := range requests {
+		req := httptest.NewRequest(rq.method, rq.path, bytes.NewBufferString(rq.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+manager)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, rq.path)
+	}
+}
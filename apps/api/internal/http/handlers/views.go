@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Saved Views --------------------
+
+// SavedViewReq creates a view, or patches one: omitted fields keep their
+// value, and a sharedRole of "" stops sharing.
+type SavedViewReq struct {
+	Name       *string                     `json:"name"`
+	Filters    *repositories.TicketFilters `json:"filters"`
+	Sort       *string                     `json:"sort"`
+	Columns    []string                    `json:"columns"`
+	SharedRole *string                     `json:"sharedRole"`
+}
+
+// apply copies the fields set in the request onto v, validating them
+func (r SavedViewReq) apply(v *repositories.SavedView) error {
+	if r.Name != nil {
+		v.Name = strings.TrimSpace(*r.Name)
+	}
+	if v.Name == "" || len([]rune(v.Name)) > 100 {
+		return errors.New("name must be 1 to 100 characters")
+	}
+	if r.Filters != nil {
+		v.Filters = *r.Filters
+		v.Filters.Sort = ""
+	}
+	if r.Sort != nil {
+		v.Sort = *r.Sort
+	}
+	if err := v.TicketFilters().Validate(); err != nil {
+		return err
+	}
+	if r.Columns != nil {
+		if len(r.Columns) > 50 {
+			return errors.New("too many columns")
+		}
+		v.Columns = []string{}
+		for _, col := range r.Columns {
+			if col = strings.TrimSpace(col); col == "" {
+				return errors.New("columns must not be empty")
+			}
+			v.Columns = append(v.Columns, col)
+		}
+	}
+	if r.SharedRole != nil {
+		switch role := models.Role(*r.SharedRole); role {
+		case "":
+			v.SharedRole = nil
+		case models.RoleUser, models.RoleSupervisor, models.RoleManager:
+			v.SharedRole = &role
+		default:
+			return fmt.Errorf("invalid sharedRole %q", *r.SharedRole)
+		}
+	}
+	return nil
+}
+
+// currentUser returns the signed-in user's id and role, or "" and Anonymous
+func currentUser(c *fiber.Ctx) (string, models.Role) {
+	claims, _ := c.Locals("user").(jwt.MapClaims)
+	if claims == nil {
+		return "", models.RoleAnonymous
+	}
+	id, _ := claims["sub"].(string)
+	role, _ := claims["role"].(string)
+	return id, models.Role(role)
+}
+
+// visibleView loads a view the current user may see; ErrNotFound otherwise,
+// so that private views do not reveal they exist.
+func (h *Handlers) visibleView(ctx context.Context, c *fiber.Ctx, id string) (repositories.SavedView, error) {
+	userID, role := currentUser(c)
+	if userID == "" {
+		return repositories.SavedView{}, repositories.ErrNotFound
+	}
+	v, err := h.repo.SavedViews.GetByID(ctx, id)
+	if err != nil {
+		return v, err
+	}
+	if !v.VisibleTo(userID, role) {
+		return repositories.SavedView{}, repositories.ErrNotFound
+	}
+	return v, nil
+}
+
+// ViewsList returns the caller's own views and those shared with their role
+func (h *Handlers) ViewsList(c *fiber.Ctx) error {
+	userID, role := currentUser(c)
+	views, err := h.repo.SavedViews.List(context.Background(), userID, role)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list views"}})
+	}
+	return c.JSON(h.envelope(views))
+}
+
+// ViewsCounts returns how many tickets each visible view matches, for badges
+func (h *Handlers) ViewsCounts(c *fiber.Ctx) error {
+	userID, role := currentUser(c)
+	ctx := context.Background()
+	views, err := h.repo.SavedViews.List(ctx, userID, role)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list views"}})
+	}
+	counts, err := h.repo.SavedViews.Counts(ctx, userID, views)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to count tickets"}})
+	}
+	return c.JSON(h.envelope(counts))
+}
+
+func (h *Handlers) ViewsGet(c *fiber.Ctx) error {
+	v, err := h.visibleView(context.Background(), c, c.Params("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "view not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load view"}})
+	}
+	return c.JSON(h.envelope(v))
+}
+
+func (h *Handlers) ViewsCreate(c *fiber.Ctx) error {
+	var body SavedViewReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	userID, _ := currentUser(c)
+	v := repositories.SavedView{OwnerID: userID, Columns: []string{}}
+	if err := body.apply(&v); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := h.repo.SavedViews.Create(context.Background(), &v); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save view"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(v))
+}
+
+// ViewsUpdate changes a view; only its owner can
+func (h *Handlers) ViewsUpdate(c *fiber.Ctx) error {
+	var body SavedViewReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	ctx := context.Background()
+	userID, _ := currentUser(c)
+	v, err := h.visibleView(ctx, c, c.Params("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "view not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load view"}})
+	}
+	if v.OwnerID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "only the owner can change a view"}})
+	}
+	if err := body.apply(&v); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := h.repo.SavedViews.Update(ctx, &v); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "view not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save view"}})
+	}
+	return c.JSON(h.envelope(v))
+}
+
+// ViewsDelete removes one of the caller's own views
+func (h *Handlers) ViewsDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userID, _ := currentUser(c)
+	if err := h.repo.SavedViews.Delete(context.Background(), id, userID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "view not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to delete view"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id}))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/repositories"
+)
+
+// -------------------- Watchers --------------------
+
+// TicketsWatch makes the caller follow a ticket, so they hear about its
+// comments and status changes; watching it again is a no-op
+func (h *Handlers) TicketsWatch(c *fiber.Ctx) error {
+	return h.setWatching(c, true)
+}
+
+// TicketsUnwatch stops the caller following a ticket. Commenting on it or
+// being assigned to it again makes them a watcher again.
+func (h *Handlers) TicketsUnwatch(c *fiber.Ctx) error {
+	return h.setWatching(c, false)
+}
+
+func (h *Handlers) setWatching(c *fiber.Ctx, watching bool) error {
+	id := c.Params("id")
+	userID, _ := currentUser(c)
+	ctx := context.Background()
+	if _, err := h.repo.Tickets.GetByID(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "ticket not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load ticket"}})
+	}
+	var err error
+	if watching {
+		err = h.repo.Watchers.Watch(ctx, id, userID)
+	} else {
+		_, err = h.repo.Watchers.Unwatch(ctx, id, userID)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to update watching"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"ticketId": id, "watching": watching}))
+}
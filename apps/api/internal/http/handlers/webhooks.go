@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/internal/webhooks"
+)
+
+// -------------------- Webhooks --------------------
+
+// RunWebhooks delivers queued webhook events every interval until ctx is done
+func (h *Handlers) RunWebhooks(ctx context.Context, interval time.Duration) {
+	w := &webhooks.Worker{
+		Outbox: h.repo.Webhooks,
+		Client: &http.Client{Timeout: webhooks.DefaultTimeout},
+	}
+	w.Run(ctx, interval)
+}
+
+// WebhookReq creates a subscription, or patches one: omitted fields keep
+// their value. A new secret is generated when none is given on create.
+type WebhookReq struct {
+	URL         *string  `json:"url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events"`
+	Secret      *string  `json:"secret"`
+	Active      *bool    `json:"active"`
+}
+
+// apply copies the fields set in the request onto w, validating them
+func (r WebhookReq) apply(w *repositories.Webhook) error {
+	if r.URL != nil {
+		w.URL = strings.TrimSpace(*r.URL)
+	}
+	if err := webhooks.ValidateURL(w.URL); err != nil {
+		return err
+	}
+	if r.Description != nil {
+		w.Description = strings.TrimSpace(*r.Description)
+	}
+	if len([]rune(w.Description)) > 200 {
+		return errors.New("description must be at most 200 characters")
+	}
+	if r.Events != nil {
+		if err := webhooks.ValidateEvents(r.Events); err != nil {
+			return err
+		}
+		w.Events = r.Events
+	}
+	if r.Secret != nil {
+		if err := webhooks.ValidateSecret(*r.Secret); err != nil {
+			return err
+		}
+		w.Secret = *r.Secret
+	}
+	if r.Active != nil {
+		w.Active = *r.Active
+	}
+	return nil
+}
+
+// webhookWithSecret shows a subscription's secret, which is only returned
+// when it is set
+type webhookWithSecret struct {
+	repositories.Webhook
+	Secret string `json:"secret"`
+}
+
+func webhookNotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "webhook not found"}})
+}
+
+func (h *Handlers) WebhooksList(c *fiber.Ctx) error {
+	hooks, err := h.repo.Webhooks.List(context.Background())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list webhooks"}})
+	}
+	return c.JSON(h.envelope(hooks))
+}
+
+func (h *Handlers) WebhooksGet(c *fiber.Ctx) error {
+	w, err := h.repo.Webhooks.GetByID(context.Background(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return webhookNotFound(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load webhook"}})
+	}
+	return c.JSON(h.envelope(w))
+}
+
+// WebhooksCreate subscribes a URL; the response is the only time the
+// secret is shown
+func (h *Handlers) WebhooksCreate(c *fiber.Ctx) error {
+	var body WebhookReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	userID, _ := currentUser(c)
+	w := repositories.Webhook{Events: []string{}, Secret: webhooks.NewSecret(), Active: true, CreatedBy: &userID}
+	if err := body.apply(&w); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := h.repo.Webhooks.Create(context.Background(), &w); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save webhook"}})
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.envelope(webhookWithSecret{w, w.Secret}))
+}
+
+// WebhooksUpdate changes a subscription; a new secret is echoed back once
+func (h *Handlers) WebhooksUpdate(c *fiber.Ctx) error {
+	var body WebhookReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	ctx := context.Background()
+	w, err := h.repo.Webhooks.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return webhookNotFound(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load webhook"}})
+	}
+	if err := body.apply(&w); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": err.Error()}})
+	}
+	if err := h.repo.Webhooks.Update(ctx, &w); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return webhookNotFound(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to save webhook"}})
+	}
+	if body.Secret != nil {
+		return c.JSON(h.envelope(webhookWithSecret{w, w.Secret}))
+	}
+	return c.JSON(h.envelope(w))
+}
+
+// WebhooksDelete removes a subscription together with its delivery log
+func (h *Handlers) WebhooksDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.repo.Webhooks.Delete(context.Background(), id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return webhookNotFound(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to delete webhook"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id}))
+}
+
+// WebhookDeliveriesList returns a subscription's delivery log, newest first
+// (limit, default 50, at most 200)
+func (h *Handlers) WebhookDeliveriesList(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "limit must be a positive number"}})
+	}
+	ctx := context.Background()
+	id := c.Params("id")
+	if _, err := h.repo.Webhooks.GetByID(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return webhookNotFound(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to load webhook"}})
+	}
+	deliveries, err := h.repo.Webhooks.Deliveries(ctx, id, min(limit, 200))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to list deliveries"}})
+	}
+	return c.JSON(h.envelope(deliveries))
+}
+
+// WebhookRedeliver queues a past delivery's payload again, as a new delivery
+func (h *Handlers) WebhookRedeliver(c *fiber.Ctx) error {
+	d, err := h.repo.Webhooks.Redeliver(context.Background(), c.Params("id"), c.Params("deliveryId"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "delivery not found"}})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "redeliver failed"}})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(h.envelope(d))
+}
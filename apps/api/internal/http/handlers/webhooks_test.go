@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/http/middleware"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/pkg/config"
+)
+
+// managerApp mounts routes behind the Manager-only middleware cmd/server uses.
+// The pool never connects, so the handlers can only answer requests that stop
+// before the database; reached counts the requests the middleware let through.
+func managerApp(t *testing.T, mount func(r fiber.Router, h *Handlers)) (app *fiber.App, h *Handlers, reached *int) {
+	t.Helper()
+	cfg := config.Config{JWTSecret: "test-secret", AccessTokenTTL: time.Minute}
+	h = New(&pgxpool.Pool{}, cfg)
+	reached = new(int)
+	app = fiber.New()
+	manager := app.Group("/api/v1", middleware.RequireRole(cfg.JWTSecret, nil, "Manager"), func(c *fiber.Ctx) error {
+		*reached++
+		return c.Next()
+	})
+	mount(manager, h)
+	return app, h, reached
+}
+
+func roleToken(t *testing.T, h *Handlers, role models.Role) string {
+	t.Helper()
+	tok, err := h.issueJWT(models.User{ID: "11111111-1111-1111-1111-111111111111", Email: "someone@example.com", Role: role}, "")
+	require.NoError(t, err)
+	return tok
+}
+
+func TestWebhookRoutes_ManagerOnly(t *testing.T) {
+	app, h, reached := managerApp(t, func(r fiber.Router, h *Handlers) {
+		r.Post("/webhooks", h.WebhooksCreate)
+		r.Delete("/webhooks/:id", h.WebhooksDelete)
+		r.Post("/webhooks/:id/deliveries/:deliveryId/redeliver", h.WebhookRedeliver)
+	})
+
+	const hook = "0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a6b"
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"create", "POST", "/api/v1/webhooks", `{"url":"https://example.com/hook","events":["ticket.created"]}`},
+		{"delete", "DELETE", "/api/v1/webhooks/" + hook, ""},
+		{"redeliver", "POST", "/api/v1/webhooks/" + hook + "/deliveries/" + hook + "/redeliver", ""},
+	}
+	for _, role := range []models.Role{models.RoleUser, models.RoleSupervisor} {
+		for _, rq := range requests {
+			t.Run(string(role)+" "+rq.name, func(t *testing.T) {
+				*reached = 0
+				req := httptest.NewRequest(rq.method, rq.path, bytes.NewBufferString(rq.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+roleToken(t, h, role))
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+				assert.Zero(t, *reached, "no handler ran, so nothing was written")
+			})
+		}
+	}
+
+	// A Manager gets through to the handlers, which refuse these before the database
+	manager := roleToken(t, h, models.RoleManager)
+	controls := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/api/v1/webhooks", `{"url":"not a url"}`, fiber.StatusBadRequest},
+		{"DELETE", "/api/v1/webhooks/42", "", fiber.StatusNotFound},
+		{"POST", "/api/v1/webhooks/42/deliveries/42/redeliver", "", fiber.StatusNotFound},
+	}
+	for _, rq := range controls {
+		*reached = 0
+		req := httptest.NewRequest(rq.method, rq.path, bytes.NewBufferString(rq.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+manager)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, rq.status, resp.StatusCode, rq.path)
+		assert.Equal(t, 1, *reached, rq.path)
+	}
+}
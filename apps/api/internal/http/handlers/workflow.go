@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+
+	"github.com/it-tms/apps/api/internal/events"
+	"github.com/it-tms/apps/api/internal/http/middleware"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/repositories"
+	"github.com/it-tms/apps/api/internal/workflow"
+)
+
+// -------------------- Workflow --------------------
+
+// workflowFacts describes a ticket and caller for the workflow guards
+func workflowFacts(ticket models.Ticket, assignees []models.User, userID string) workflow.Facts {
+	facts := workflow.Facts{
+		HasAssignee: len(assignees) > 0,
+		IsCreator:   userID != "" && ticket.CreatedBy != nil && *ticket.CreatedBy == userID,
+	}
+	for _, a := range assignees {
+		if userID != "" && a.ID == userID {
+			facts.IsAssignee = true
+			break
+		}
+	}
+	return facts
+}
+
+// transitionConflict is the 409 response for a refused status change
+func transitionConflict(err *workflow.TransitionError) fiber.Map {
+	return fiber.Map{"error": fiber.Map{
+		"code":    "INVALID_TRANSITION",
+		"message": err.Error(),
+		"details": fiber.Map{
+			"from":    err.From,
+			"to":      err.To,
+			"allowed": err.Allowed,
+		},
+	}}
+}
+
+// statusChangedConflict is the 409 response for a status change that lost a race:
+// the ticket moved on after its transition was checked
+func statusChangedConflict() fiber.Map {
+	return fiber.Map{"error": fiber.Map{
+		"code":    "INVALID_TRANSITION",
+		"message": "ticket status changed, reload and try again",
+	}}
+}
+
+// resumeOnRequesterReply moves a paused ticket back to in_progress when its requester comments
+func (h *Handlers) resumeOnRequesterReply(ctx context.Context, r *repositories.Repo, ticketID, authorID string) error {
+	ticket, err := r.Tickets.GetByID(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if !workflow.IsPaused(ticket.Status) || ticket.CreatedBy == nil || *ticket.CreatedBy != authorID {
+		return nil
+	}
+	comment := fmt.Sprintf("Status changed from \"%s\" to \"%s\" automatically after the requester replied", ticket.Status, models.StatusInProgress)
+	err = h.applyStatusChange(ctx, r, ticket, models.StatusInProgress, nil, comment)
+	if errors.Is(err, repositories.ErrStatusChanged) {
+		// Someone moved the ticket on meanwhile; the reply is still stored
+		return nil
+	}
+	return err
+}
+
+// RunAutoClose closes paused tickets that have been idle for AUTO_CLOSE_IDLE_DAYS,
+// checking every interval until ctx is done. It does nothing when the setting is 0.
+func (h *Handlers) RunAutoClose(ctx context.Context, interval time.Duration) {
+	if h.cfg.AutoCloseIdleDays <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if closed, err := h.autoCloseIdleTickets(ctx, time.Now()); err != nil {
+			log.Error().Err(err).Msg("auto-close of idle tickets failed")
+		} else if closed > 0 {
+			log.Info().Int("closed", closed).Msg("auto-closed idle tickets")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// autoCloseIdleTickets cancels idle paused tickets. They are never completed, as that
+// would award points for work nobody finished. It returns how many tickets were closed.
+func (h *Handlers) autoCloseIdleTickets(ctx context.Context, now time.Time) (int, error) {
+	ids, err := h.repo.Tickets.ListIdlePaused(ctx, now.AddDate(0, 0, -h.cfg.AutoCloseIdleDays))
+	if err != nil {
+		return 0, err
+	}
+	closed := 0
+	for _, id := range ids {
+		ticket, err := h.repo.Tickets.GetByID(ctx, id)
+		if err != nil || !workflow.IsPaused(ticket.Status) {
+			continue
+		}
+		status := models.StatusCanceled
+		if err := workflow.Default.Check(ticket.Status, status, workflow.SystemRole, workflow.Facts{}); err != nil {
+			log.Error().Err(err).Str("ticket", id).Msg("auto-close not allowed")
+			continue
+		}
+		comment := fmt.Sprintf("Status changed from \"%s\" to \"%s\" automatically after %d idle days", ticket.Status, status, h.cfg.AutoCloseIdleDays)
+		if err := h.applyStatusChange(ctx, h.repo, ticket, status, nil, comment); err != nil {
+			log.Error().Err(err).Str("ticket", id).Msg("auto-close failed")
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+type ReopenReq struct {
+	Reason string `json:"reason"`
+}
+
+// TicketsReopen brings a completed ticket back to in_progress. The reopen is counted on the
+// ticket, its SLA deadlines start again from now and the points awarded on completion are
+// taken back, all in the same transaction.
+func (h *Handlers) TicketsReopen(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var body ReopenReq
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "BAD_REQUEST", "message": "invalid payload"}})
+	}
+	body.Reason = strings.TrimSpace(body.Reason)
+	if body.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fiber.Map{"code": "REASON_REQUIRED", "message": "a reason is required to reopen a ticket"}})
+	}
+
+	userID, role, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "authentication required"}})
+	}
+
+	ctx := context.Background()
+	ticket, err := h.repo.Tickets.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "ticket not found"}})
+	}
+	assignees, err := h.repo.Tickets.GetAssignees(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "failed to check assignment"}})
+	}
+	if err := workflow.Reopen.Check(ticket.Status, models.StatusInProgress, models.Role(role), workflowFacts(ticket, assignees, userID)); err != nil {
+		var te *workflow.TransitionError
+		if errors.As(err, &te) {
+			return c.Status(fiber.StatusConflict).JSON(transitionConflict(te))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": err.Error()}})
+	}
+
+	comment := fmt.Sprintf("Ticket reopened by %s\n\nReason: %s", role, body.Reason)
+	var reopenCount int32
+	err = h.repo.WithTx(ctx, func(tx *repositories.Repo) error {
+		var err error
+		if reopenCount, err = tx.Tickets.Reopen(ctx, id); err != nil {
+			return err
+		}
+		// The old deadlines belong to the first round of work and have usually passed
+		due, ok, err := h.slaDueDates(ctx, ticket.Priority, ticket.InitialType, time.Now())
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := tx.Tickets.UpdateSLADueDates(ctx, id, due.Response, due.Resolution); err != nil {
+				return err
+			}
+		}
+		if err := tx.UserScores.RemoveAllPointsForTicket(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.Tickets.AddComment(ctx, id, &userID, comment); err != nil {
+			return err
+		}
+		if err := tx.Events.Publish(ctx, events.Event{Type: events.TicketReopened, TicketID: id, ActorID: &userID, Data: map[string]any{"reopenCount": reopenCount}}); err != nil {
+			return err
+		}
+		return tx.Audits.Insert(ctx, id, &userID, "reopen", nil, fiber.Map{"reason": body.Reason, "reopenCount": reopenCount})
+	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		// Someone else changed the ticket since we checked it
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_TRANSITION", "message": "ticket is no longer completed"}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fiber.Map{"code": "SERVER_ERROR", "message": "reopen failed"}})
+	}
+	return c.JSON(h.envelope(fiber.Map{"id": id, "status": models.StatusInProgress, "reopenCount": reopenCount}))
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func getTokenFromReq(c *fiber.Ctx) string {
+// TokenFromRequest returns the JWT from the token cookie or, failing that,
+// the Authorization header
+func TokenFromRequest(c *fiber.Ctx) string {
 	// Cookie first
 	if tok := c.Cookies("token"); tok != "" {
 		return tok
@@ -22,9 +25,28 @@ func getTokenFromReq(c *fiber.Ctx) string {
 	return ""
 }
 
-func AuthOptional(secret string) fiber.Handler {
+// SessionChecker reports whether the session a token was issued for, named
+// by its jti claim, is still signed in
+type SessionChecker interface {
+	Active(ctx context.Context, jti string) (bool, error)
+}
+
+// sessionActive checks the token's session; with no checker every valid
+// token is accepted
+func sessionActive(sessions SessionChecker, claims jwt.MapClaims) (bool, error) {
+	if sessions == nil {
+		return true, nil
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return false, nil
+	}
+	return sessions.Active(context.Background(), jti)
+}
+
+func AuthOptional(secret string, sessions SessionChecker) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		tok := getTokenFromReq(c)
+		tok := TokenFromRequest(c)
 		if tok == "" {
 			return c.Next()
 		}
@@ -33,31 +55,62 @@ func AuthOptional(secret string) fiber.Handler {
 			return []byte(secret), nil
 		})
 		if err == nil && parsed.Valid {
-			c.Locals("user", claims)
+			// A signed-out session browses anonymously
+			if ok, err := sessionActive(sessions, claims); err == nil && ok {
+				c.Locals("user", claims)
+			}
 		}
 		return c.Next()
 	}
 }
 
-func AuthRequired(secret string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		tok := getTokenFromReq(c)
-		if tok == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "missing token"}})
-		}
-		claims := jwt.MapClaims{}
-		parsed, err := jwt.ParseWithClaims(tok, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !parsed.Valid {
-			log.Warn().Err(err).Msg("invalid token")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "invalid token"}})
+// checkToken validates the request's token and the session it belongs to.
+// It only inspects the request: it writes no response and never calls
+// c.Next, so callers can check more before letting the request through.
+func checkToken(c *fiber.Ctx, secret string, sessions SessionChecker) (jwt.MapClaims, *fiber.Error) {
+	tok := TokenFromRequest(c)
+	if tok == "" {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "missing token")
+	}
+	claims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(tok, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil || !parsed.Valid {
+		log.Warn().Err(err).Msg("invalid token")
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
+	// exp check
+	if exp, ok := claims["exp"].(float64); ok {
+		if time.Now().After(time.Unix(int64(exp), 0)) {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "token expired")
 		}
-		// exp check
-		if exp, ok := claims["exp"].(float64); ok {
-			if time.Now().After(time.Unix(int64(exp), 0)) {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "token expired"}})
-			}
+	}
+	active, err := sessionActive(sessions, claims)
+	if err != nil {
+		log.Error().Err(err).Msg("session check failed")
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to check session")
+	}
+	if !active {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "session revoked")
+	}
+	return claims, nil
+}
+
+// deny answers a request checkToken refused
+func deny(c *fiber.Ctx, e *fiber.Error) error {
+	code := "UNAUTHORIZED"
+	if e.Code == fiber.StatusInternalServerError {
+		code = "SERVER_ERROR"
+	}
+	return c.Status(e.Code).JSON(fiber.Map{"error": fiber.Map{"code": code, "message": e.Message}})
+}
+
+func AuthRequired(secret string, sessions SessionChecker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, denied := checkToken(c, secret, sessions)
+		if denied != nil {
+			return deny(c, denied)
 		}
 		c.Locals("user", claims)
 		return c.Next()
@@ -65,86 +118,53 @@ func AuthRequired(secret string) fiber.Handler {
 }
 
 // AuthRequiredWithRedirect checks for authentication and redirects browser requests to sign-in page
-func AuthRequiredWithRedirect(secret string, signInURL string) fiber.Handler {
+func AuthRequiredWithRedirect(secret string, sessions SessionChecker, signInURL string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		tok := getTokenFromReq(c)
-		if tok == "" {
-			// Check if this is a browser request by looking at Accept header
-			accept := c.Get("Accept")
-			if strings.Contains(accept, "text/html") {
-				// Browser request - redirect to sign-in
+		claims, denied := checkToken(c, secret, sessions)
+		if denied != nil {
+			// Browser requests go to sign-in; API requests get the JSON error
+			if denied.Code == fiber.StatusUnauthorized && strings.Contains(c.Get("Accept"), "text/html") {
 				return c.Redirect(signInURL)
 			}
-			// API request - return JSON error
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "missing token"}})
-		}
-		claims := jwt.MapClaims{}
-		parsed, err := jwt.ParseWithClaims(tok, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !parsed.Valid {
-			log.Warn().Err(err).Msg("invalid token")
-			// Check if this is a browser request
-			accept := c.Get("Accept")
-			if strings.Contains(accept, "text/html") {
-				// Browser request - redirect to sign-in
-				return c.Redirect(signInURL)
-			}
-			// API request - return JSON error
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "invalid token"}})
-		}
-		// exp check
-		if exp, ok := claims["exp"].(float64); ok {
-			if time.Now().After(time.Unix(int64(exp), 0)) {
-				// Check if this is a browser request
-				accept := c.Get("Accept")
-				if strings.Contains(accept, "text/html") {
-					// Browser request - redirect to sign-in
-					return c.Redirect(signInURL)
-				}
-				// API request - return JSON error
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code": "UNAUTHORIZED", "message": "token expired"}})
-			}
+			return deny(c, denied)
 		}
 		c.Locals("user", claims)
 		return c.Next()
 	}
 }
 
-func RequireAnyRole(secret string, roles []string) fiber.Handler {
+// RequireAnyRole lets a request through only with a valid token for one of
+// roles. The route's handler runs once, after both checks have passed.
+func RequireAnyRole(secret string, sessions SessionChecker, roles []string) fiber.Handler {
 	roleSet := map[string]struct{}{}
 	for _, r := range roles { roleSet[r] = struct{}{} }
 	return func(c *fiber.Ctx) error {
-		// This includes AuthRequired behavior
-		if err := AuthRequired(secret)(c); err != nil {
-			return err
+		claims, denied := checkToken(c, secret, sessions)
+		if denied != nil {
+			return deny(c, denied)
 		}
-		val := c.Locals("user")
-		if val == nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiber.Map{"code":"UNAUTHORIZED","message":"no user"}})
-		}
-		claims := val.(jwt.MapClaims)
 		role, _ := claims["role"].(string)
 		if _, ok := roleSet[role]; !ok {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{"code":"FORBIDDEN","message":"insufficient role"}})
 		}
+		c.Locals("user", claims)
 		return c.Next()
 	}
 }
 
 // RequireRole checks for a specific role
-func RequireRole(secret string, role string) fiber.Handler {
-	return RequireAnyRole(secret, []string{role})
+func RequireRole(secret string, sessions SessionChecker, role string) fiber.Handler {
+	return RequireAnyRole(secret, sessions, []string{role})
 }
 
 // RequireSupervisorOrManager checks for Supervisor or Manager roles
-func RequireSupervisorOrManager(secret string) fiber.Handler {
-	return RequireAnyRole(secret, []string{"Supervisor", "Manager"})
+func RequireSupervisorOrManager(secret string, sessions SessionChecker) fiber.Handler {
+	return RequireAnyRole(secret, sessions, []string{"Supervisor", "Manager"})
 }
 
 // RequireAuthenticatedUser checks for any authenticated user (not Anonymous)
-func RequireAuthenticatedUser(secret string) fiber.Handler {
-	return RequireAnyRole(secret, []string{"User", "Supervisor", "Manager"})
+func RequireAuthenticatedUser(secret string, sessions SessionChecker) fiber.Handler {
+	return RequireAnyRole(secret, sessions, []string{"User", "Supervisor", "Manager"})
 }
 
 // GetUserFromContext extracts user information from context
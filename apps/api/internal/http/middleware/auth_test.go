@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+// revokedSessions treats every session as signed out but "live"
+type revokedSessions struct{}
+
+func (revokedSessions) Active(_ context.Context, jti string) (bool, error) {
+	return jti == "live", nil
+}
+
+func signed(t *testing.T, claims jwt.MapClaims) string {
+	claims["sub"] = "user-1"
+	if _, ok := claims["role"]; !ok {
+		claims["role"] = "Manager"
+	}
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return tok
+}
+
+func TestAuthRequired_Sessions(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", AuthRequired(testSecret, revokedSessions{}), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"live session", signed(t, jwt.MapClaims{"jti": "live"}), fiber.StatusOK},
+		{"revoked session", signed(t, jwt.MapClaims{"jti": "revoked"}), fiber.StatusUnauthorized},
+		{"token without a session", signed(t, jwt.MapClaims{}), fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestAuthOptional_RevokedSessionIsAnonymous(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", AuthOptional(testSecret, revokedSessions{}), func(c *fiber.Ctx) error {
+		userID, _, ok := GetUserFromContext(c)
+		if !ok {
+			return c.SendString("anonymous")
+		}
+		return c.SendString(userID)
+	})
+
+	for jti, want := range map[string]string{"live": "user-1", "revoked": "anonymous"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signed(t, jwt.MapClaims{"jti": jti}))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body := make([]byte, 32)
+		n, _ := resp.Body.Read(body)
+		assert.Equal(t, want, string(body[:n]), jti)
+	}
+}
+
+func TestRequireAnyRole_RevokedSession(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", RequireAnyRole(testSecret, revokedSessions{}, []string{"Manager"}), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed(t, jwt.MapClaims{"jti": "revoked"}))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRequireAnyRole_WrongRoleNeverReachesHandler(t *testing.T) {
+	ran := 0
+	app := fiber.New()
+	app.Post("/users", RequireRole(testSecret, nil, "Manager"), func(c *fiber.Ctx) error {
+		ran++
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	tests := []struct {
+		role   string
+		status int
+		ran    int
+	}{
+		{"User", fiber.StatusForbidden, 0},
+		{"Supervisor", fiber.StatusForbidden, 0},
+		{"Manager", fiber.StatusCreated, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ran = 0
+			req := httptest.NewRequest("POST", "/users", nil)
+			req.Header.Set("Authorization", "Bearer "+signed(t, jwt.MapClaims{"role": tt.role}))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.ran, ran, "handler runs")
+		})
+	}
+}
+
+func TestRequireAnyRole_SessionAndRoleBothChecked(t *testing.T) {
+	ran := 0
+	app := fiber.New()
+	app.Delete("/sessions/:id", RequireAnyRole(testSecret, revokedSessions{}, []string{"Supervisor", "Manager"}), func(c *fiber.Ctx) error {
+		ran++
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		jti    string
+		role   string
+		status int
+		ran    int
+	}{
+		{"live session, wrong role", "live", "User", fiber.StatusForbidden, 0},
+		{"revoked session, right role", "gone", "Manager", fiber.StatusUnauthorized, 0},
+		{"revoked session, wrong role", "gone", "User", fiber.StatusUnauthorized, 0},
+		{"live session, right role", "live", "Supervisor", fiber.StatusNoContent, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = 0
+			req := httptest.NewRequest("DELETE", "/sessions/s-1", nil)
+			req.Header.Set("Authorization", "Bearer "+signed(t, jwt.MapClaims{"jti": tt.jti, "role": tt.role}))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.ran, ran, "handler runs")
+		})
+	}
+}
+
+func TestAuthRequiredWithRedirect_RevokedSession(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", AuthRequiredWithRedirect(testSecret, revokedSessions{}, "/signin"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	tok := signed(t, jwt.MapClaims{"jti": "gone"})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Accept", "text/html")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusFound, resp.StatusCode)
+	assert.Equal(t, "/signin", resp.Header.Get("Location"))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tok)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,274 @@
+// Package mailin parses inbound RFC 5322 messages for the email-to-ticket
+// gateway: sender, subject, the readable body and attachments, decoding
+// transfer encodings and legacy charsets such as TIS-620, which Thai mail
+// clients still send.
+package mailin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// Message is the part of an email the gateway uses
+type Message struct {
+	From      *mail.Address
+	Subject   string
+	MessageID string
+	// Text is the plain-text body, from the HTML part when there is no
+	// text part
+	Text        string
+	Attachments []Attachment
+	// Automatic is set for auto-replies, bounces and bulk mail, including
+	// IT-TMS's own notifications, which must not become tickets
+	Automatic bool
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// maxDepth bounds nested multiparts
+const maxDepth = 10
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// Parse reads one message
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	h := raw.Header
+
+	from, err := mail.ParseAddress(decodeHeader(h.Get("From")))
+	if err != nil {
+		// Fall back to the bare address for display names net/mail rejects
+		addrs, err2 := (&mail.AddressParser{WordDecoder: wordDecoder}).ParseList(h.Get("From"))
+		if err2 != nil || len(addrs) == 0 {
+			return nil, fmt.Errorf("invalid From: %w", err)
+		}
+		from = addrs[0]
+	}
+	from.Address = strings.ToLower(from.Address)
+
+	m := &Message{
+		From:      from,
+		Subject:   strings.Join(strings.Fields(decodeHeader(h.Get("Subject"))), " "),
+		MessageID: strings.Trim(strings.TrimSpace(h.Get("Message-Id")), "<>"),
+		Automatic: isAutomatic(textproto.MIMEHeader(h)),
+	}
+	var htmlBody string
+	if err := m.walk(textproto.MIMEHeader(h), raw.Body, 0, &htmlBody); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(m.Text) == "" && htmlBody != "" {
+		m.Text = htmlToText(htmlBody)
+	}
+	m.Text = strings.TrimSpace(normalizeNewlines(m.Text))
+	return m, nil
+}
+
+// walk collects the first text and HTML bodies and every attachment
+func (m *Message) walk(h textproto.MIMEHeader, body io.Reader, depth int, htmlBody *string) error {
+	if depth > maxDepth {
+		return errors.New("message nests too deeply")
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if err := m.walk(part.Header, part, depth+1, htmlBody); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("invalid %s part: %w", mediaType, err)
+	}
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	switch {
+	case disposition != "attachment" && filename == "" && mediaType == "text/plain" && m.Text == "":
+		m.Text = decodeCharset(params["charset"], data)
+	case disposition != "attachment" && filename == "" && mediaType == "text/html" && *htmlBody == "":
+		*htmlBody = decodeCharset(params["charset"], data)
+	case disposition == "attachment" || filename != "" || !strings.HasPrefix(mediaType, "text/"):
+		m.Attachments = append(m.Attachments, Attachment{
+			Filename:    attachmentName(filename, len(m.Attachments)+1),
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// newlineStripper drops the line breaks base64 bodies are wrapped with
+type newlineStripper struct{ r io.Reader }
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
+
+// charsetReader decodes any charset the WHATWG encoding index knows,
+// mapping TIS-620 and ISO-8859-11 to windows-874
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "":
+		return input, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+func decodeCharset(charset string, data []byte) string {
+	r, err := charsetReader(charset, bytes.NewReader(data))
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "�")
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "�")
+	}
+	return strings.ToValidUTF8(string(out), "�")
+}
+
+func decodeHeader(s string) string {
+	if d, err := wordDecoder.DecodeHeader(s); err == nil {
+		return d
+	}
+	return s
+}
+
+func attachmentName(name string, n int) string {
+	name = filepath.Base(strings.ReplaceAll(decodeHeader(name), `\`, "/"))
+	if name == "" || name == "." || name == "/" {
+		return "attachment-" + strconv.Itoa(n)
+	}
+	return name
+}
+
+func isAutomatic(h textproto.MIMEHeader) bool {
+	if v := strings.ToLower(h.Get("Auto-Submitted")); v != "" && v != "no" {
+		return true
+	}
+	switch strings.ToLower(h.Get("Precedence")) {
+	case "bulk", "junk", "list", "auto_reply":
+		return true
+	}
+	return h.Get("X-Autoreply") != "" || h.Get("X-Autorespond") != "" ||
+		strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "multipart/report")
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>|</h[1-6]>`)
+	htmlDrop   = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText keeps the readable text of an HTML body
+func htmlToText(s string) string {
+	s = htmlDrop.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTags.ReplaceAllString(s, ""))
+	lines := strings.Split(normalizeNewlines(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+var ticketCode = regexp.MustCompile(`(?i)\bIT-TMS\s*#(\d{1,9})\b`)
+
+// TicketCode finds a ticket code quoted in a subject, as in the
+// "[IT-TMS #42]" prefix of notification emails
+func TicketCode(subject string) (int32, bool) {
+	m := ticketCode.FindStringSubmatch(subject)
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.ParseInt(m[1], 10, 32)
+	return int32(code), err == nil
+}
+
+// replyHeader matches the line mail clients put above a quoted message, in
+// English and Thai
+var replyHeader = regexp.MustCompile(`(?i)^(on\b.*\bwrote:|เมื่อ.*เขียนว่า:|-{2,}\s*original message\s*-{2,}|-{2,}\s*ข้อความต้นฉบับ\s*-{2,}|from:\s.*|จาก:\s.*)$`)
+
+// StripQuoted removes the quoted message from a reply: everything from the
+// client's "On ... wrote:" line, and any other lines starting with ">"
+func StripQuoted(text string) string {
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if replyHeader.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,155 @@
+package mailin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crlf(s string) string { return strings.ReplaceAll(s, "\n", "\r\n") }
+
+func TestParse_MultipartWithAttachment(t *testing.T) {
+	raw := crlf(`From: =?UTF-8?B?4Liq4Lih4LiK4Liy4Lii?= <Somchai@Example.com>
+To: support@example.com
+Subject: =?UTF-8?Q?Printer_=E0=B9=80=E0=B8=AA=E0=B8=B5=E0=B8=A2?=
+  on floor 3
+Message-ID: <abc123@mail.example.com>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+The printer jams on every page.=0AIt started today.
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>The printer jams on every page.</p>
+--inner--
+--outer
+Content-Type: image/png; name="jam.png"
+Content-Disposition: attachment; filename="jam.png"
+Content-Transfer-Encoding: base64
+
+iVBORw0K
+GgoAAAA=
+--outer--
+`)
+	m, err := Parse(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	assert.Equal(t, "somchai@example.com", m.From.Address)
+	assert.Equal(t, "สมชาย", m.From.Name)
+	assert.Equal(t, "Printer เสีย on floor 3", m.Subject)
+	assert.Equal(t, "abc123@mail.example.com", m.MessageID)
+	assert.Equal(t, "The printer jams on every page.\nIt started today.", m.Text)
+	assert.False(t, m.Automatic)
+
+	require.Len(t, m.Attachments, 1)
+	assert.Equal(t, "jam.png", m.Attachments[0].Filename)
+	assert.Equal(t, "image/png", m.Attachments[0].ContentType)
+	assert.Equal(t, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00"), m.Attachments[0].Data)
+}
+
+func TestParse_TIS620AndHTMLOnly(t *testing.T) {
+	// "สวัสดี" in TIS-620
+	raw := crlf("From: user@example.com\nSubject: =?TIS-620?B?ysfRyrTV?=\nContent-Type: text/html; charset=tis-620\n\n<html><head><style>p{}</style></head><body><p>\xca\xc7\xd1\xca\xb4\xd5</p><p>A &amp; B</p></body></html>\n")
+	m, err := Parse(strings.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "สวัสดี", m.Subject)
+	assert.Equal(t, "สวัสดี\nA & B", m.Text)
+	assert.Empty(t, m.Attachments)
+}
+
+func TestParse_AttachmentNamesAreSanitised(t *testing.T) {
+	raw := crlf(`From: user@example.com
+Content-Type: multipart/mixed; boundary=b
+
+--b
+Content-Type: text/plain
+
+see attached
+--b
+Content-Type: application/pdf; name="..\..\etc\passwd"
+
+%PDF
+--b
+Content-Type: application/octet-stream
+
+data
+--b--
+`)
+	m, err := Parse(strings.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "see attached", m.Text)
+	require.Len(t, m.Attachments, 2)
+	assert.Equal(t, "passwd", m.Attachments[0].Filename)
+	assert.Equal(t, "attachment-2", m.Attachments[1].Filename)
+}
+
+func TestParse_Automatic(t *testing.T) {
+	for _, header := range []string{
+		"Auto-Submitted: auto-generated",
+		"Auto-Submitted: auto-replied",
+		"Precedence: bulk",
+		"X-Autoreply: yes",
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=x",
+	} {
+		raw := crlf("From: user@example.com\n" + header + "\n\n--x\n\nbody\n--x--\n")
+		m, err := Parse(strings.NewReader(raw))
+		require.NoError(t, err, header)
+		assert.True(t, m.Automatic, header)
+	}
+
+	m, err := Parse(strings.NewReader(crlf("From: user@example.com\nAuto-Submitted: no\n\nbody\n")))
+	require.NoError(t, err)
+	assert.False(t, m.Automatic)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("not a message"))
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader(crlf("Subject: no sender\n\nbody\n")))
+	assert.Error(t, err)
+}
+
+func TestTicketCode(t *testing.T) {
+	for subject, want := range map[string]int32{
+		"Re: [IT-TMS #42] New comment: Printer": 42,
+		"RE: FW: [it-tms #7] status":            7,
+		"ตอบกลับ: [IT-TMS#1005] ความเห็นใหม่":   1005,
+	} {
+		code, ok := TicketCode(subject)
+		assert.True(t, ok, subject)
+		assert.Equal(t, want, code, subject)
+	}
+
+	for _, subject := range []string{"Printer broken", "IT-TMS #", "[IT-TMS] Daily summary", "IT-TMS #99999999999"} {
+		_, ok := TicketCode(subject)
+		assert.False(t, ok, subject)
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	assert.Equal(t, "Still broken.\n\nThanks", StripQuoted(`Still broken.
+
+Thanks
+
+On Mon, 3 Mar 2025 at 09:00, IT-TMS <tms@example.com> wrote:
+> New comment on ticket #42
+> ...`))
+
+	assert.Equal(t, "ยังใช้ไม่ได้ครับ", StripQuoted(`ยังใช้ไม่ได้ครับ
+เมื่อ จ. 3 มี.ค. 2568 เวลา 09:00 IT-TMS <tms@example.com> เขียนว่า:
+> ความเห็นใหม่`))
+
+	assert.Equal(t, "Done", StripQuoted("Done\r\n-----Original Message-----\r\nFrom: IT-TMS"))
+	assert.Equal(t, "top\nbottom", StripQuoted("top\n> quoted\nbottom"))
+}
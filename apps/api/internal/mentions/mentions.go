@@ -0,0 +1,47 @@
+// Package mentions finds the people a comment mentions, written as
+// "@somchai@example.com" or "@<user id>".
+package mentions
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxPerComment bounds how many people one comment can notify
+const MaxPerComment = 20
+
+// Mentions are the handles a comment mentions, lower-cased and without
+// repeats, in order of first appearance
+type Mentions struct {
+	Emails  []string
+	UserIDs []string
+}
+
+// Empty reports whether nothing was mentioned
+func (m Mentions) Empty() bool { return len(m.Emails) == 0 && len(m.UserIDs) == 0 }
+
+// A mention starts a word, so addresses like "name@example.com" in running
+// text are not read as mentions of "example.com"
+var mention = regexp.MustCompile(`(?:^|[^\w.@/])@((?:[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,})|[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12})\b`)
+
+// Parse returns the mentions in a comment body, at most MaxPerComment
+func Parse(body string) Mentions {
+	var m Mentions
+	seen := map[string]bool{}
+	for _, match := range mention.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if seen[handle] {
+			continue
+		}
+		if len(seen) == MaxPerComment {
+			break
+		}
+		seen[handle] = true
+		if strings.Contains(handle, "@") {
+			m.Emails = append(m.Emails, handle)
+		} else {
+			m.UserIDs = append(m.UserIDs, handle)
+		}
+	}
+	return m
+}
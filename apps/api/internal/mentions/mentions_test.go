@@ -0,0 +1,39 @@
+package mentions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	body := `@Somchai@Example.com please check, cc @0b6f5c4e-1d2a-4c3b-9e8f-7a6b5c4d3e2f.
+(@malee@example.co.th) ดูด้วยครับ @somchai@example.com again`
+	m := Parse(body)
+	assert.Equal(t, []string{"somchai@example.com", "malee@example.co.th"}, m.Emails)
+	assert.Equal(t, []string{"0b6f5c4e-1d2a-4c3b-9e8f-7a6b5c4d3e2f"}, m.UserIDs)
+	assert.False(t, m.Empty())
+}
+
+func TestParse_IgnoresNonMentions(t *testing.T) {
+	for _, body := range []string{
+		"mail somchai@example.com about it",
+		"see https://example.com/@handle",
+		"@ nobody",
+		"@someone without a domain",
+		"@0b6f5c4e-1d2a",
+		"",
+	} {
+		assert.True(t, Parse(body).Empty(), body)
+	}
+}
+
+func TestParse_Limit(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < MaxPerComment+5; i++ {
+		fmt.Fprintf(&b, "@user%d@example.com ", i)
+	}
+	assert.Len(t, Parse(b.String()).Emails, MaxPerComment)
+}
@@ -0,0 +1,455 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// Latest is the target version that applies every known migration.
+const Latest int64 = math.MaxInt64
+
+// lockKey serialises migrators across processes (pg_advisory_lock).
+const lockKey int64 = 0x49544d53 // "ITMS"
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the up script that was applied. Editing a migration
+// after it ran changes its checksum and stops further migrations.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Applied is a row of schema_migrations.
+type Applied struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// State of a migration as reported by Status.
+const (
+	StatePending  = "pending"
+	StateApplied  = "applied"
+	StateModified = "modified" // applied, but the script has changed since
+	StateMissing  = "missing"  // applied, but unknown to this binary
+)
+
+// Status describes one migration for `migrate status`.
+type Status struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+// Load reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir in fsys,
+// sorted by version. Every version needs an up script; down is optional.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ChecksumError reports an applied migration whose script no longer matches.
+type ChecksumError struct {
+	Version  int64
+	Name     string
+	Applied  string
+	Expected string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("migration %d_%s was changed after it was applied (checksum %s, now %s)", e.Version, e.Name, short(e.Applied), short(e.Expected))
+}
+
+func short(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+// Verify checks that every applied migration is known and unchanged.
+func Verify(migrations []Migration, applied []Applied) error {
+	known := map[int64]Migration{}
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+	for _, a := range applied {
+		m, ok := known[a.Version]
+		if !ok {
+			return fmt.Errorf("database has migration %d_%s applied, which this binary does not know about", a.Version, a.Name)
+		}
+		if sum := m.Checksum(); sum != a.Checksum {
+			return &ChecksumError{Version: a.Version, Name: m.Name, Applied: a.Checksum, Expected: sum}
+		}
+	}
+	return nil
+}
+
+// Plan works out the steps that bring the database to target: the pending
+// migrations up to target in ascending order, then the applied ones above
+// target in descending order. At most one of the two is non-empty.
+func Plan(migrations []Migration, applied []Applied, target int64) (up, down []Migration, err error) {
+	if err := Verify(migrations, applied); err != nil {
+		return nil, nil, err
+	}
+	if target != Latest && target != 0 && !hasVersion(migrations, target) {
+		return nil, nil, fmt.Errorf("unknown migration version %d", target)
+	}
+	done := map[int64]bool{}
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	for _, m := range migrations {
+		if m.Version <= target && !done[m.Version] {
+			up = append(up, m)
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > target && done[m.Version] {
+			if m.Down == "" {
+				return nil, nil, fmt.Errorf("migration %d_%s cannot be rolled back: no down script", m.Version, m.Name)
+			}
+			down = append(down, m)
+		}
+	}
+	if len(up) > 0 && len(down) > 0 {
+		return nil, nil, errors.New("database has gaps below and migrations above the target; migrate up first")
+	}
+	return up, down, nil
+}
+
+// StatusOf lists every known and applied migration with its state.
+func StatusOf(migrations []Migration, applied []Applied) []Status {
+	byVersion := map[int64]Applied{}
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+	out := []Status{}
+	for _, m := range migrations {
+		s := Status{Version: m.Version, Name: m.Name, State: StatePending}
+		if a, ok := byVersion[m.Version]; ok {
+			at := a.AppliedAt
+			s.AppliedAt = &at
+			s.State = StateApplied
+			if a.Checksum != m.Checksum() {
+				s.State = StateModified
+			}
+			delete(byVersion, m.Version)
+		}
+		out = append(out, s)
+	}
+	for _, a := range byVersion {
+		at := a.AppliedAt
+		out = append(out, Status{Version: a.Version, Name: a.Name, State: StateMissing, AppliedAt: &at})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+func hasVersion(migrations []Migration, version int64) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrator applies migrations to a database, recording them in schema_migrations.
+// Each migration runs in its own transaction together with its bookkeeping row.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New returns a Migrator for the given migrations, as returned by Load.
+func New(pool *pgxpool.Pool, migrations []Migration) *Migrator {
+	return &Migrator{pool: pool, migrations: migrations}
+}
+
+// Up applies every pending migration and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.To(ctx, Latest)
+}
+
+// Down rolls back the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, nil
+	}
+	var n int
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		target := int64(0)
+		if steps < len(applied) {
+			target = applied[len(applied)-steps-1].Version
+		}
+		n, err = m.migrate(ctx, conn, applied, target)
+		return err
+	})
+	return n, err
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 rolls back everything.
+func (m *Migrator) To(ctx context.Context, version int64) (int, error) {
+	var n int
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		n, err = m.migrate(ctx, conn, applied, version)
+		return err
+	})
+	return n, err
+}
+
+// Status reports which migrations are applied, pending, modified or missing.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var out []Status
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		out = StatusOf(m.migrations, applied)
+		return nil
+	})
+	return out, err
+}
+
+// Baseline records every migration up to version as applied without running
+// it. It is meant for databases whose schema was created before migrations
+// were versioned, and refuses to run once anything has been recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	if !hasVersion(m.migrations, version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("schema_migrations is not empty; baseline only applies to unversioned databases")
+		}
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			for _, mig := range m.migrations {
+				if mig.Version > version {
+					break
+				}
+				if err := record(ctx, tx, mig); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (m *Migrator) migrate(ctx context.Context, conn *pgxpool.Conn, applied []Applied, target int64) (int, error) {
+	up, down, err := Plan(m.migrations, applied, target)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, mig := range up {
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, mig.Up); err != nil {
+				return err
+			}
+			return record(ctx, tx, mig)
+		})
+		if err != nil {
+			return n, fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+		}
+		log.Info().Int64("version", mig.Version).Str("name", mig.Name).Msg("applied migration")
+		n++
+	}
+	for _, mig := range down {
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, mig.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version=$1`, mig.Version)
+			return err
+		})
+		if err != nil {
+			return n, fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+		}
+		log.Info().Int64("version", mig.Version).Str("name", mig.Name).Msg("rolled back migration")
+		n++
+	}
+	return n, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, after
+// making sure schema_migrations exists.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *pgxpool.Conn) error {
+	// Databases previously migrated with golang-migrate have a schema_migrations
+	// table of (version, dirty); carry their version over.
+	var legacy bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'schema_migrations' AND column_name = 'dirty'
+		)`).Scan(&legacy)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		return m.adoptLegacy(ctx, conn)
+	}
+	_, err = conn.Exec(ctx, createTable)
+	return err
+}
+
+const createTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		checksum   TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`
+
+func (m *Migrator) adoptLegacy(ctx context.Context, conn *pgxpool.Conn) error {
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("golang-migrate left migration %d dirty; fix the schema by hand before migrating", version)
+	}
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DROP TABLE schema_migrations`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, createTable); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if err := record(ctx, tx, mig); err != nil {
+				return err
+			}
+		}
+		log.Info().Int64("version", version).Msg("converted golang-migrate schema_migrations")
+		return nil
+	})
+}
+
+func listApplied(ctx context.Context, conn *pgxpool.Conn) ([]Applied, error) {
+	rows, err := conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var applied []Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+func record(ctx context.Context, tx pgx.Tx, m Migration) error {
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1,$2,$3)`, m.Version, m.Name, m.Checksum())
+	return err
+}
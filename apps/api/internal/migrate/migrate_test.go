@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/it-tms/apps/api/db"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"m/0002_indexes.up.sql":   {Data: []byte("CREATE INDEX i ON t (a);")},
+		"m/0002_indexes.down.sql": {Data: []byte("DROP INDEX i;")},
+		"m/0001_init.up.sql":      {Data: []byte("CREATE TABLE t (a int);")},
+		"m/0001_init.down.sql":    {Data: []byte("DROP TABLE t;")},
+		"m/0003_data.up.sql":      {Data: []byte("INSERT INTO t VALUES (1);")},
+		"m/README.md":             {Data: []byte("not a migration")},
+	}
+}
+
+func versions(ms []Migration) []int64 {
+	out := []int64{}
+	for _, m := range ms {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func applied(ms []Migration, upTo int64) []Applied {
+	out := []Applied{}
+	for _, m := range ms {
+		if m.Version <= upTo {
+			out = append(out, Applied{Version: m.Version, Name: m.Name, Checksum: m.Checksum()})
+		}
+	}
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	ms, err := Load(testFS(), "m")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := versions(ms); !equal(got, []int64{1, 2, 3}) {
+		t.Fatalf("expected versions 1..3 in order, got %v", got)
+	}
+	if ms[0].Name != "init" || ms[0].Down != "DROP TABLE t;" || ms[2].Down != "" {
+		t.Fatalf("unexpected migrations %+v", ms)
+	}
+
+	// A down script without its up script is refused
+	fsys := testFS()
+	fsys["m/0004_orphan.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	if _, err := Load(fsys, "m"); err == nil {
+		t.Fatalf("expected error for missing up script")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ms, _ := Load(testFS(), "m")
+
+	// Fresh database: everything runs in order
+	up, down, err := Plan(ms, nil, Latest)
+	if err != nil || !equal(versions(up), []int64{1, 2, 3}) || len(down) != 0 {
+		t.Fatalf("fresh: up=%v down=%v err=%v", versions(up), versions(down), err)
+	}
+
+	// Up to date: nothing to do
+	up, down, err = Plan(ms, applied(ms, 3), Latest)
+	if err != nil || len(up) != 0 || len(down) != 0 {
+		t.Fatalf("up to date: up=%v down=%v err=%v", versions(up), versions(down), err)
+	}
+
+	// Rolling back to 1 undoes 2 only, since 3 has not been applied
+	up, down, err = Plan(ms, applied(ms, 2), 1)
+	if err != nil || len(up) != 0 || !equal(versions(down), []int64{2}) {
+		t.Fatalf("to 1: up=%v down=%v err=%v", versions(up), versions(down), err)
+	}
+
+	// Migration 3 has no down script
+	if _, _, err := Plan(ms, applied(ms, 3), 2); err == nil {
+		t.Fatalf("expected error rolling back migration without down script")
+	}
+
+	// Unknown target
+	if _, _, err := Plan(ms, nil, 42); err == nil {
+		t.Fatalf("expected error for unknown target")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ms, _ := Load(testFS(), "m")
+
+	edited := applied(ms, 2)
+	edited[1].Checksum = "deadbeef"
+	var ce *ChecksumError
+	if _, _, err := Plan(ms, edited, Latest); !errors.As(err, &ce) || ce.Version != 2 {
+		t.Fatalf("expected checksum error for version 2, got %v", err)
+	}
+
+	unknown := append(applied(ms, 3), Applied{Version: 9, Name: "future", Checksum: "x"})
+	if err := Verify(ms, unknown); err == nil {
+		t.Fatalf("expected error for migration unknown to the binary")
+	}
+
+	st := StatusOf(ms, append(edited, Applied{Version: 9, Name: "future"}))
+	want := []string{StateApplied, StateModified, StatePending, StateMissing}
+	for i, s := range st {
+		if s.State != want[i] {
+			t.Fatalf("status %d: expected %s, got %s", s.Version, want[i], s.State)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ms, err := Load(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatalf("load embedded: %v", err)
+	}
+	if len(ms) == 0 || ms[0].Version != 1 {
+		t.Fatalf("expected embedded migrations starting at 1")
+	}
+	for i, m := range ms {
+		if m.Version != int64(i+1) {
+			t.Fatalf("expected contiguous versions, got %d at position %d", m.Version, i)
+		}
+		if m.Down == "" {
+			t.Fatalf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
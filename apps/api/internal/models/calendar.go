@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// BusinessHours is the working window for one weekday (0=Sunday .. 6=Saturday).
+// StartTime and EndTime are local times formatted as HH:MM.
+type BusinessHours struct {
+	Weekday      int    `json:"weekday"`
+	StartTime    string `json:"startTime"`
+	EndTime      string `json:"endTime"`
+	IsWorkingDay bool   `json:"isWorkingDay"`
+}
+
+// Holiday is a non-working date (YYYY-MM-DD).
+type Holiday struct {
+	ID        string    `json:"id"`
+	Date      string    `json:"date"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -14,10 +14,13 @@ const (
 )
 
 const (
-	StatusPending    TicketStatus = "pending"
-	StatusInProgress TicketStatus = "in_progress"
-	StatusCompleted  TicketStatus = "completed"
-	StatusCanceled   TicketStatus = "canceled"
+	StatusPending             TicketStatus = "pending"
+	StatusInProgress          TicketStatus = "in_progress"
+	StatusCompleted           TicketStatus = "completed"
+	StatusCanceled            TicketStatus = "canceled"
+	// Paused statuses stop SLA clocks until work resumes
+	StatusOnHold              TicketStatus = "on_hold"
+	StatusWaitingForRequester TicketStatus = "waiting_for_requester"
 )
 
 const (
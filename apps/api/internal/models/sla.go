@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type SLAState string
+
+const (
+	SLAStateOnTrack  SLAState = "on_track"
+	SLAStateAtRisk   SLAState = "at_risk"
+	SLAStateBreached SLAState = "breached"
+	SLAStateMet      SLAState = "met"
+)
+
+// SLAPolicy holds the response and resolution targets for a priority.
+// A nil InitialType is the default for the priority; a policy with an
+// InitialType overrides it for that ticket type only.
+type SLAPolicy struct {
+	ID                string             `json:"id"`
+	Priority          TicketPriority     `json:"priority"`
+	InitialType       *TicketInitialType `json:"initialType,omitempty"`
+	ResponseMinutes   int32              `json:"responseMinutes"`
+	ResolutionMinutes int32              `json:"resolutionMinutes"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
+}
+
+// SLAStatus is the computed SLA state of a ticket at a point in time.
+type SLAStatus struct {
+	State              SLAState   `json:"state"`
+	ResponseDueAt      *time.Time `json:"responseDueAt,omitempty"`
+	ResolutionDueAt    *time.Time `json:"resolutionDueAt,omitempty"`
+	ResponseBreached   bool       `json:"responseBreached"`
+	ResolutionBreached bool       `json:"resolutionBreached"`
+	AtRisk             bool       `json:"atRisk"`
+}
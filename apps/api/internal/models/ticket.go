@@ -25,6 +25,14 @@ type Ticket struct {
 	UrgencyTimelineData    map[string]any     `json:"urgencyTimelineData,omitempty"`
 	EffortData             map[string]any     `json:"effortData,omitempty"`
 	EffortScore            int32              `json:"effortScore"`
+	ResponseDueAt          *time.Time         `json:"responseDueAt,omitempty"`
+	ResolutionDueAt        *time.Time         `json:"resolutionDueAt,omitempty"`
+	FirstRespondedAt       *time.Time         `json:"firstRespondedAt,omitempty"`
+	PausedAt               *time.Time         `json:"pausedAt,omitempty"` // SLA clock stopped (on_hold / waiting_for_requester)
+	ReopenCount            int32              `json:"reopenCount"`
+	LastReopenedAt         *time.Time         `json:"lastReopenedAt,omitempty"`
+	SLAPaused              time.Duration      `json:"-"` // business time the SLA clock has been paused
+	SLA                    *SLAStatus         `json:"sla,omitempty"`
 	CreatedAt              time.Time          `json:"createdAt"`
 	UpdatedAt              time.Time          `json:"updatedAt"`
 	ClosedAt               *time.Time         `json:"closedAt,omitempty"`
@@ -11,4 +11,9 @@ type User struct {
 	PasswordHash   string    `json:"-"`
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
-}
\ No newline at end of file
+	// DeactivatedAt is set while a Manager has switched the account off
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	// OIDCSubject links the account to the single sign-on provider, which
+	// then owns its password
+	OIDCSubject *string `json:"-"`
+}
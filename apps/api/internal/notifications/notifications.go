@@ -0,0 +1,289 @@
+// Package notifications renders ticket activity emails in Thai and English
+// and delivers them from the database outbox over SMTP.
+//
+// Handlers render a Message per recipient and enqueue it in the transaction
+// that makes the change, so a rolled-back change never sends mail and a
+// committed one is not lost if the process stops. A Worker then claims due
+// outbox rows, sends them and retries failures with exponential backoff.
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+// Kind is the activity a message reports
+type Kind string
+
+const (
+	KindAssigned      Kind = "assigned"
+	KindStatusChanged Kind = "status_changed"
+	KindComment       Kind = "comment"
+	KindMention       Kind = "mention"
+	// KindDigest is the daily summary sent instead of the other kinds
+	KindDigest Kind = "digest"
+	// KindInvitation invites an email address to sign up and
+	// KindPasswordReset carries a password reset link; neither is about a
+	// ticket and both ignore preferences
+	KindInvitation    Kind = "invitation"
+	KindPasswordReset Kind = "password_reset"
+)
+
+// Locales messages can be rendered in; DefaultLocale is used for any other
+const (
+	LocaleThai    = "th"
+	LocaleEnglish = "en"
+	DefaultLocale = LocaleThai
+)
+
+// Message is one rendered email
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Data fills the templates
+type Data struct {
+	RecipientName string
+	// ActorName is who made the change; empty for automatic changes
+	ActorName   string
+	TicketCode  int32
+	TicketTitle string
+	TicketURL   string
+	FromStatus  models.TicketStatus
+	ToStatus    models.TicketStatus
+	// Comment is the comment or mention text, already shortened
+	Comment string
+}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var statusLabels = map[string]map[models.TicketStatus]string{
+	LocaleEnglish: {
+		models.StatusPending:             "Pending",
+		models.StatusInProgress:          "In Progress",
+		models.StatusOnHold:              "On Hold",
+		models.StatusWaitingForRequester: "Waiting for Requester",
+		models.StatusCompleted:           "Completed",
+		models.StatusCanceled:            "Canceled",
+	},
+	LocaleThai: {
+		models.StatusPending:             "รอดำเนินการ",
+		models.StatusInProgress:          "กำลังดำเนินการ",
+		models.StatusOnHold:              "พักไว้",
+		models.StatusWaitingForRequester: "รอผู้แจ้งตอบกลับ",
+		models.StatusCompleted:           "เสร็จสิ้น",
+		models.StatusCanceled:            "ยกเลิก",
+	},
+}
+
+var systemActor = map[string]string{LocaleEnglish: "The system", LocaleThai: "ระบบ"}
+
+var templates = map[string]*template.Template{}
+
+func init() {
+	for _, locale := range []string{LocaleEnglish, LocaleThai} {
+		funcs := template.FuncMap{
+			"status": func(s models.TicketStatus) string {
+				if label, ok := statusLabels[locale][s]; ok {
+					return label
+				}
+				return string(s)
+			},
+			"actor": func(name string) string {
+				if name == "" {
+					return systemActor[locale]
+				}
+				return name
+			},
+		}
+		templates[locale] = template.Must(template.New(locale).Funcs(funcs).ParseFS(templateFS, "templates/"+locale+".tmpl"))
+	}
+}
+
+var htmlLayout = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"></head>
+<body style="font-family: sans-serif; line-height: 1.5">
+{{range .}}<p>{{range $i, $line := .}}{{if $i}}<br>{{end}}{{if $line.Link}}<a href="{{$line.Text}}">{{$line.Text}}</a>{{else}}{{$line.Text}}{{end}}{{end}}</p>
+{{end}}</body></html>
+`))
+
+type htmlLine struct {
+	Text string
+	Link bool
+}
+
+// Render builds the message of kind for one recipient in locale, falling
+// back to DefaultLocale for locales without templates.
+func Render(locale string, kind Kind, to string, data Data) (Message, error) {
+	t, ok := templates[locale]
+	if !ok {
+		t = templates[DefaultLocale]
+	}
+	var subject, text bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, string(kind)+".subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", kind, err)
+	}
+	if err := t.ExecuteTemplate(&text, string(kind)+".text", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", kind, err)
+	}
+
+	return message(to, subject.String(), text.String(), data.TicketURL)
+}
+
+// DigestItem is one piece of activity in a daily digest
+type DigestItem struct {
+	Kind        Kind
+	TicketID    string
+	TicketCode  int32
+	TicketTitle string
+	TicketURL   string
+	ActorName   string
+	ToStatus    models.TicketStatus
+	Comment     string
+	At          time.Time
+}
+
+// Digest fills the daily digest template; Tickets is built by RenderDigest
+type Digest struct {
+	RecipientName string
+	Since         time.Time
+	Tickets       []DigestTicket
+}
+
+// DigestTicket is the activity on one ticket, oldest first
+type DigestTicket struct {
+	Code  int32
+	Title string
+	URL   string
+	Items []DigestItem
+}
+
+// RenderDigest builds one recipient's daily digest, grouping items by
+// ticket in the order the tickets first appear. Times are shown in loc.
+func RenderDigest(locale, to string, loc *time.Location, d Digest, items []DigestItem) (Message, error) {
+	t, ok := templates[locale]
+	if !ok {
+		t = templates[DefaultLocale]
+	}
+	d.Since = d.Since.In(loc)
+	d.Tickets = nil
+	byCode := map[int32]int{}
+	var links []string
+	for _, item := range items {
+		item.At = item.At.In(loc)
+		// Each item is one line of the message
+		item.Comment = Excerpt(strings.Join(strings.Fields(item.Comment), " "), digestExcerptLength)
+		i, ok := byCode[item.TicketCode]
+		if !ok {
+			i = len(d.Tickets)
+			byCode[item.TicketCode] = i
+			d.Tickets = append(d.Tickets, DigestTicket{Code: item.TicketCode, Title: item.TicketTitle, URL: item.TicketURL})
+			links = append(links, item.TicketURL)
+		}
+		d.Tickets[i].Items = append(d.Tickets[i].Items, item)
+	}
+
+	var subject, text bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "digest.subject", d); err != nil {
+		return Message{}, fmt.Errorf("render digest subject: %w", err)
+	}
+	if err := t.ExecuteTemplate(&text, "digest.text", d); err != nil {
+		return Message{}, fmt.Errorf("render digest text: %w", err)
+	}
+	return message(to, subject.String(), text.String(), links...)
+}
+
+// digestExcerptLength bounds each comment quoted in a digest
+const digestExcerptLength = 200
+
+// Invitation fills the invitation template
+type Invitation struct {
+	// InviterName is the Manager who sent it; empty if they are gone
+	InviterName string
+	Role        models.Role
+	URL         string
+	ExpiresAt   time.Time
+}
+
+// RenderInvitation builds the email inviting to to sign up. The expiry is
+// shown in loc.
+func RenderInvitation(locale, to string, loc *time.Location, inv Invitation) (Message, error) {
+	inv.ExpiresAt = inv.ExpiresAt.In(loc)
+	return renderLink(locale, KindInvitation, to, inv, inv.URL)
+}
+
+// PasswordReset fills the password reset template
+type PasswordReset struct {
+	RecipientName string
+	URL           string
+	ExpiresAt     time.Time
+}
+
+// RenderPasswordReset builds the email with a password reset link. The
+// expiry is shown in loc.
+func RenderPasswordReset(locale, to string, loc *time.Location, r PasswordReset) (Message, error) {
+	r.ExpiresAt = r.ExpiresAt.In(loc)
+	return renderLink(locale, KindPasswordReset, to, r, r.URL)
+}
+
+// renderLink renders an account email of kind whose point is one link
+func renderLink(locale string, kind Kind, to string, data any, link string) (Message, error) {
+	t, ok := templates[locale]
+	if !ok {
+		t = templates[DefaultLocale]
+	}
+	var subject, text bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, string(kind)+".subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", kind, err)
+	}
+	if err := t.ExecuteTemplate(&text, string(kind)+".text", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", kind, err)
+	}
+	return message(to, subject.String(), text.String(), link)
+}
+
+// message assembles a Message from rendered text. The HTML part is the text
+// with paragraphs kept and lines that are one of links made clickable.
+func message(to, subject, text string, links ...string) (Message, error) {
+	text = strings.TrimSpace(text)
+	var paragraphs [][]htmlLine
+	for _, p := range strings.Split(text, "\n\n") {
+		var lines []htmlLine
+		for _, line := range strings.Split(p, "\n") {
+			lines = append(lines, htmlLine{Text: line, Link: line != "" && slices.Contains(links, line)})
+		}
+		paragraphs = append(paragraphs, lines)
+	}
+	var html bytes.Buffer
+	if err := htmlLayout.Execute(&html, paragraphs); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject),
+		Text:    text + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Excerpt shortens comment text for a message body
+func Excerpt(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > max {
+		return strings.TrimSpace(string(r[:max])) + "…"
+	}
+	return s
+}
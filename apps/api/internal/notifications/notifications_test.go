@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestRender_AllKindsAndLocales(t *testing.T) {
+	data := Data{
+		RecipientName: "Somchai",
+		ActorName:     "Ploy",
+		TicketCode:    42,
+		TicketTitle:   "Printer offline",
+		TicketURL:     "https://tms.example.com/th/tickets/abc",
+		FromStatus:    models.StatusPending,
+		ToStatus:      models.StatusInProgress,
+		Comment:       "On my way <now>",
+	}
+	for _, locale := range []string{LocaleEnglish, LocaleThai} {
+		for _, kind := range []Kind{KindAssigned, KindStatusChanged, KindComment, KindMention} {
+			m, err := Render(locale, kind, "somchai@example.com", data)
+			require.NoError(t, err, "%s %s", locale, kind)
+			assert.True(t, strings.HasPrefix(m.Subject, "[IT-TMS #42] "), m.Subject)
+			assert.Contains(t, m.Text, "Printer offline")
+			assert.Contains(t, m.Text, data.TicketURL)
+			assert.Contains(t, m.HTML, `<a href="https://tms.example.com/th/tickets/abc">`)
+			assert.NotContains(t, m.HTML, "<now>", "HTML must be escaped")
+		}
+	}
+}
+
+func TestRender_Localized(t *testing.T) {
+	data := Data{RecipientName: "สมชาย", TicketCode: 7, TicketTitle: "VPN", FromStatus: models.StatusOnHold, ToStatus: models.StatusCompleted}
+
+	th, err := Render(LocaleThai, KindStatusChanged, "a@example.com", data)
+	require.NoError(t, err)
+	assert.Equal(t, "[IT-TMS #7] สถานะเปลี่ยนเป็น เสร็จสิ้น: VPN", th.Subject)
+	assert.Contains(t, th.Text, "ระบบ ได้เปลี่ยนสถานะตั๋ว #7 \"VPN\" จาก พักไว้ เป็น เสร็จสิ้น")
+
+	en, err := Render(LocaleEnglish, KindStatusChanged, "a@example.com", data)
+	require.NoError(t, err)
+	assert.Equal(t, "[IT-TMS #7] Status changed to Completed: VPN", en.Subject)
+	assert.Contains(t, en.Text, "The system changed the status of ticket #7 \"VPN\" from On Hold to Completed.")
+
+	// Unknown locales fall back to the default
+	other, err := Render("fr", KindStatusChanged, "a@example.com", data)
+	require.NoError(t, err)
+	assert.Equal(t, th.Subject, other.Subject)
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "สวัสดี", Excerpt("  สวัสดี  ", 10))
+	assert.Equal(t, "สวัส…", Excerpt("สวัสดีครับ", 4))
+}
+
+func TestRenderInvitation(t *testing.T) {
+	inv := Invitation{
+		InviterName: "Ploy",
+		Role:        models.RoleSupervisor,
+		URL:         "https://tms.example.com/accept-invite?token=abc",
+		ExpiresAt:   time.Date(2026, 10, 24, 2, 30, 0, 0, time.UTC),
+	}
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	for _, locale := range []string{LocaleEnglish, LocaleThai} {
+		m, err := RenderInvitation(locale, "new@example.com", bangkok, inv)
+		require.NoError(t, err, locale)
+		assert.True(t, strings.HasPrefix(m.Subject, "[IT-TMS] "), m.Subject)
+		assert.Contains(t, m.Text, "Ploy")
+		assert.Contains(t, m.Text, "Supervisor")
+		assert.Contains(t, m.Text, "24/10/2026 09:30")
+		assert.Contains(t, m.HTML, `<a href="https://tms.example.com/accept-invite?token=abc">`)
+	}
+
+	// An inviter who has since been removed is left out
+	inv.InviterName = ""
+	m, err := RenderInvitation(LocaleEnglish, "new@example.com", bangkok, inv)
+	require.NoError(t, err)
+	assert.Contains(t, m.Text, "You have been invited to IT-TMS as Supervisor.")
+}
+
+func TestRenderPasswordReset(t *testing.T) {
+	r := PasswordReset{
+		RecipientName: "Somchai",
+		URL:           "https://tms.example.com/reset-password?token=abc",
+		ExpiresAt:     time.Date(2026, 10, 24, 2, 30, 0, 0, time.UTC),
+	}
+	for _, locale := range []string{LocaleEnglish, LocaleThai} {
+		m, err := RenderPasswordReset(locale, "somchai@example.com", time.FixedZone("ICT", 7*60*60), r)
+		require.NoError(t, err, locale)
+		assert.True(t, strings.HasPrefix(m.Subject, "[IT-TMS] "), m.Subject)
+		assert.Contains(t, m.Text, "Somchai")
+		assert.Contains(t, m.Text, "24/10/2026 09:30")
+		assert.Contains(t, m.HTML, `<a href="https://tms.example.com/reset-password?token=abc">`)
+	}
+}
@@ -0,0 +1,120 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/calendar"
+)
+
+// Channel is how a user is told about activity
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	// ChannelNone turns notifications off
+	ChannelNone Channel = "none"
+)
+
+// Delivery is when a user is told: as it happens or once a day
+type Delivery string
+
+const (
+	DeliveryImmediate Delivery = "immediate"
+	DeliveryDigest    Delivery = "digest"
+)
+
+// Kinds are the activity kinds a user can choose from
+var Kinds = []Kind{KindAssigned, KindStatusChanged, KindComment, KindMention}
+
+// Preferences are one user's notification settings. Quiet hours are local
+// times (HH:MM, in the business timezone); immediate messages that fall in
+// them wait until they end. A window may run past midnight.
+type Preferences struct {
+	Channel         Channel  `json:"channel"`
+	EventTypes      []Kind   `json:"eventTypes"`
+	Delivery        Delivery `json:"delivery"`
+	QuietHoursStart *string  `json:"quietHoursStart"`
+	QuietHoursEnd   *string  `json:"quietHoursEnd"`
+}
+
+// DefaultPreferences apply to users who have not saved any: every kind,
+// by email, as it happens.
+func DefaultPreferences() Preferences {
+	return Preferences{Channel: ChannelEmail, EventTypes: slices.Clone(Kinds), Delivery: DeliveryImmediate}
+}
+
+func (p Preferences) Validate() error {
+	if p.Channel != ChannelEmail && p.Channel != ChannelNone {
+		return fmt.Errorf("invalid channel %q", p.Channel)
+	}
+	if p.Delivery != DeliveryImmediate && p.Delivery != DeliveryDigest {
+		return fmt.Errorf("invalid delivery %q", p.Delivery)
+	}
+	for _, k := range p.EventTypes {
+		if !slices.Contains(Kinds, k) {
+			return fmt.Errorf("invalid event type %q", k)
+		}
+	}
+	if (p.QuietHoursStart == nil) != (p.QuietHoursEnd == nil) {
+		return errors.New("quietHoursStart and quietHoursEnd must be set together")
+	}
+	if p.QuietHoursStart != nil {
+		start, err := calendar.ParseTimeOfDay(*p.QuietHoursStart)
+		if err != nil || start >= 24*time.Hour {
+			return fmt.Errorf("invalid quietHoursStart %q, want HH:MM", *p.QuietHoursStart)
+		}
+		end, err := calendar.ParseTimeOfDay(*p.QuietHoursEnd)
+		if err != nil || end >= 24*time.Hour {
+			return fmt.Errorf("invalid quietHoursEnd %q, want HH:MM", *p.QuietHoursEnd)
+		}
+		if start == end {
+			return errors.New("quiet hours must not start and end at the same time")
+		}
+	}
+	return nil
+}
+
+// Wants reports whether the user gets mail about kind at all
+func (p Preferences) Wants(kind Kind) bool {
+	return p.Channel == ChannelEmail && slices.Contains(p.EventTypes, kind)
+}
+
+// QuietUntil returns when the quiet hours around now end, or the zero time
+// when now is outside them.
+func (p Preferences) QuietUntil(now time.Time, loc *time.Location) time.Time {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil {
+		return time.Time{}
+	}
+	start, err1 := calendar.ParseTimeOfDay(*p.QuietHoursStart)
+	end, err2 := calendar.ParseTimeOfDay(*p.QuietHoursEnd)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}
+	}
+	now = now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	at := now.Sub(midnight)
+	switch {
+	case start < end && at >= start && at < end:
+		return midnight.Add(end)
+	case start > end && at >= start:
+		// Overnight window, evening side: it ends tomorrow
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc).Add(end)
+	case start > end && at < end:
+		return midnight.Add(end)
+	}
+	return time.Time{}
+}
+
+// LastDigestTime is the most recent daily digest time (an offset from local
+// midnight) at or before now.
+func LastDigestTime(now time.Time, at time.Duration, loc *time.Location) time.Time {
+	now = now.In(loc)
+	due := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).Add(at)
+	if due.After(now) {
+		due = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, loc).Add(at)
+	}
+	return due
+}
@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+var bangkok = time.FixedZone("ICT", 7*3600)
+
+func ptr(s string) *string { return &s }
+
+func TestPreferences_Validate(t *testing.T) {
+	assert.NoError(t, DefaultPreferences().Validate())
+
+	p := DefaultPreferences()
+	p.QuietHoursStart, p.QuietHoursEnd = ptr("22:00"), ptr("07:00")
+	assert.NoError(t, p.Validate())
+
+	for name, mutate := range map[string]func(*Preferences){
+		"channel":     func(p *Preferences) { p.Channel = "sms" },
+		"delivery":    func(p *Preferences) { p.Delivery = "weekly" },
+		"event type":  func(p *Preferences) { p.EventTypes = []Kind{"deleted"} },
+		"half window": func(p *Preferences) { p.QuietHoursStart = ptr("22:00") },
+		"bad time":    func(p *Preferences) { p.QuietHoursStart, p.QuietHoursEnd = ptr("9pm"), ptr("07:00") },
+		"empty":       func(p *Preferences) { p.QuietHoursStart, p.QuietHoursEnd = ptr("08:00"), ptr("08:00") },
+	} {
+		p := DefaultPreferences()
+		mutate(&p)
+		assert.Error(t, p.Validate(), name)
+	}
+}
+
+func TestPreferences_Wants(t *testing.T) {
+	p := DefaultPreferences()
+	p.EventTypes = []Kind{KindAssigned}
+	assert.True(t, p.Wants(KindAssigned))
+	assert.False(t, p.Wants(KindComment))
+
+	p.Channel = ChannelNone
+	assert.False(t, p.Wants(KindAssigned))
+}
+
+func TestPreferences_QuietUntil(t *testing.T) {
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, bangkok) }
+
+	overnight := Preferences{QuietHoursStart: ptr("22:00"), QuietHoursEnd: ptr("07:00")}
+	assert.Equal(t, at(11, 7, 0), overnight.QuietUntil(at(10, 23, 30), bangkok))
+	assert.Equal(t, at(11, 7, 0), overnight.QuietUntil(at(11, 2, 0), bangkok))
+	assert.True(t, overnight.QuietUntil(at(11, 7, 0), bangkok).IsZero())
+	assert.True(t, overnight.QuietUntil(at(11, 12, 0), bangkok).IsZero())
+
+	lunch := Preferences{QuietHoursStart: ptr("12:00"), QuietHoursEnd: ptr("13:00")}
+	assert.Equal(t, at(11, 13, 0), lunch.QuietUntil(at(11, 12, 15), bangkok))
+	assert.True(t, lunch.QuietUntil(at(11, 11, 59), bangkok).IsZero())
+
+	// Times are compared in the business timezone
+	assert.Equal(t, at(11, 13, 0), lunch.QuietUntil(at(11, 12, 15).UTC(), bangkok))
+
+	assert.True(t, DefaultPreferences().QuietUntil(at(11, 3, 0), bangkok).IsZero())
+}
+
+func TestLastDigestTime(t *testing.T) {
+	eight := 8 * time.Hour
+	assert.Equal(t, time.Date(2026, 3, 11, 8, 0, 0, 0, bangkok), LastDigestTime(time.Date(2026, 3, 11, 9, 0, 0, 0, bangkok), eight, bangkok))
+	assert.Equal(t, time.Date(2026, 3, 10, 8, 0, 0, 0, bangkok), LastDigestTime(time.Date(2026, 3, 11, 7, 59, 0, 0, bangkok), eight, bangkok))
+	assert.Equal(t, time.Date(2026, 3, 11, 8, 0, 0, 0, bangkok), LastDigestTime(time.Date(2026, 3, 11, 8, 0, 0, 0, bangkok), eight, bangkok))
+}
+
+func TestRenderDigest(t *testing.T) {
+	at := time.Date(2026, 3, 11, 2, 30, 0, 0, time.UTC) // 09:30 in Bangkok
+	items := []DigestItem{
+		{Kind: KindAssigned, TicketCode: 42, TicketTitle: "Printer offline", TicketURL: "https://tms.example.com/en/tickets/a", ActorName: "Ploy", At: at},
+		{Kind: KindComment, TicketCode: 7, TicketTitle: "VPN", TicketURL: "https://tms.example.com/en/tickets/b", ActorName: "Ploy", Comment: "Tried\n\nagain <today>", At: at.Add(time.Hour)},
+		{Kind: KindStatusChanged, TicketCode: 42, TicketTitle: "Printer offline", TicketURL: "https://tms.example.com/en/tickets/a", ToStatus: models.StatusCompleted, At: at.Add(2 * time.Hour)},
+	}
+	d := Digest{RecipientName: "Somchai", Since: at.Add(-24 * time.Hour)}
+
+	m, err := RenderDigest(LocaleEnglish, "somchai@example.com", bangkok, d, items)
+	require.NoError(t, err)
+	assert.Equal(t, "[IT-TMS] Daily summary: activity on 2 tickets", m.Subject)
+	assert.Contains(t, m.Text, "since 10/03/2026 09:30.")
+	assert.Contains(t, m.Text, "#42 Printer offline\n- 09:30 Ploy assigned you\n- 11:30 The system changed the status to Completed\nhttps://tms.example.com/en/tickets/a\n")
+	assert.Contains(t, m.Text, "- 10:30 Ploy commented: Tried again <today>\n")
+	assert.Less(t, strings.Index(m.Text, "#42"), strings.Index(m.Text, "#7"), "tickets in order of first activity")
+	assert.Contains(t, m.HTML, `<a href="https://tms.example.com/en/tickets/b">`)
+	assert.NotContains(t, m.HTML, "<today>")
+
+	th, err := RenderDigest(LocaleThai, "somchai@example.com", bangkok, d, items[:1])
+	require.NoError(t, err)
+	assert.Equal(t, "[IT-TMS] สรุปประจำวัน: มีความเคลื่อนไหวใน 1 ตั๋ว", th.Subject)
+	assert.Contains(t, th.Text, "- 09:30 Ploy มอบหมายตั๋วให้คุณ")
+}
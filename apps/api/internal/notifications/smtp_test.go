@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/notifications/smtptest"
+)
+
+func TestSMTPSender_Send(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sender := SMTPSender{Host: srv.Host(), Port: srv.Port(), From: "IT-TMS <tms@example.com>"}
+	msg := Message{
+		To:      "Somchai <somchai@example.com>",
+		Subject: "[IT-TMS #1] ความเห็นใหม่\r\nBcc: evil@example.com",
+		Text:    "สวัสดี\n",
+		HTML:    "<p>สวัสดี</p>",
+	}
+	require.NoError(t, sender.Send(context.Background(), msg))
+
+	received := srv.Messages()
+	require.Len(t, received, 1)
+	assert.Equal(t, "tms@example.com", received[0].From)
+	assert.Equal(t, []string{"somchai@example.com"}, received[0].To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(received[0].Data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[IT-TMS #1] ความเห็นใหม่ Bcc: evil@example.com", subject)
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		p, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, _ := io.ReadAll(p)
+		bodies = append(bodies, p.Header.Get("Content-Type")+": "+string(b))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8: สวัสดี\r\n", "text/html; charset=utf-8: <p>สวัสดี</p>"}, bodies)
+}
+
+func TestSMTPSender_Rejected(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.SetReject(true)
+
+	sender := SMTPSender{Host: srv.Host(), Port: srv.Port(), From: "tms@example.com"}
+	err = sender.Send(context.Background(), Message{To: "a@example.com", Subject: "s", Text: "t"})
+	assert.ErrorContains(t, err, "451")
+	assert.Empty(t, srv.Messages())
+}
@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Queued is a message claimed from the outbox; Attempts includes the
+// current one.
+type Queued struct {
+	ID       string
+	Attempts int
+	Message  Message
+}
+
+// Outbox is the queue a Worker drains
+type Outbox interface {
+	// Claim leases up to limit due messages for lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Queued, error)
+	MarkSent(ctx context.Context, id string) error
+	// MarkFailed records err and retries at retryAt, or gives up when nil
+	MarkFailed(ctx context.Context, id string, err string, retryAt *time.Time) error
+}
+
+// Worker defaults
+const (
+	DefaultMaxAttempts = 8
+	DefaultBatchSize   = 20
+	DefaultLease       = 5 * time.Minute
+	sendTimeout        = 30 * time.Second
+)
+
+// Worker sends due outbox messages, retrying failures with Backoff until
+// MaxAttempts.
+type Worker struct {
+	Outbox      Outbox
+	Sender      Sender
+	MaxAttempts int
+	BatchSize   int
+	// Lease keeps a claimed message from being claimed again while it is
+	// being sent; it must outlast a send
+	Lease time.Duration
+	Now   func() time.Time
+}
+
+// Backoff is the wait before retrying after the given number of attempts:
+// one minute, doubling up to six hours.
+func Backoff(attempts int) time.Duration {
+	d := time.Minute
+	for i := 1; i < attempts && d < 6*time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, 6*time.Hour)
+}
+
+// Run sends due messages every interval until ctx is done
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if sent, failed, err := w.SendDue(ctx); err != nil {
+			log.Error().Err(err).Msg("notification outbox run failed")
+		} else if sent > 0 || failed > 0 {
+			log.Info().Int("sent", sent).Int("failed", failed).Msg("sent notifications")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue drains the due messages batch by batch and reports how many were
+// sent and how many failed (to be retried or given up on).
+func (w *Worker) SendDue(ctx context.Context) (sent, failed int, err error) {
+	maxAttempts, batchSize, lease, now := w.MaxAttempts, w.BatchSize, w.Lease, w.Now
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	for {
+		batch, err := w.Outbox.Claim(ctx, batchSize, lease)
+		if err != nil {
+			return sent, failed, err
+		}
+		for _, q := range batch {
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			sendErr := w.Sender.Send(sendCtx, q.Message)
+			cancel()
+			if sendErr == nil {
+				if err := w.Outbox.MarkSent(ctx, q.ID); err != nil {
+					return sent, failed, err
+				}
+				sent++
+				continue
+			}
+
+			failed++
+			var retryAt *time.Time
+			if q.Attempts < maxAttempts {
+				t := now().Add(Backoff(q.Attempts))
+				retryAt = &t
+			}
+			log.Warn().Err(sendErr).Str("notification", q.ID).Int("attempts", q.Attempts).Bool("retry", retryAt != nil).Msg("notification not sent")
+			if err := w.Outbox.MarkFailed(ctx, q.ID, sendErr.Error(), retryAt); err != nil {
+				return sent, failed, err
+			}
+		}
+		if len(batch) < batchSize || ctx.Err() != nil {
+			return sent, failed, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/notifications/smtptest"
+)
+
+// memOutbox is an Outbox over a slice, honouring leases and retry times
+type memOutbox struct {
+	now   time.Time
+	items []*memItem
+}
+
+type memItem struct {
+	Queued
+	due     time.Time
+	sent    bool
+	gaveUp  bool
+	lastErr string
+}
+
+func (o *memOutbox) add(id string, m Message) {
+	o.items = append(o.items, &memItem{Queued: Queued{ID: id, Message: m}, due: o.now})
+}
+
+func (o *memOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]Queued, error) {
+	var out []Queued
+	for _, it := range o.items {
+		if len(out) == limit {
+			break
+		}
+		if !it.sent && !it.gaveUp && !it.due.After(o.now) {
+			it.Attempts++
+			it.due = o.now.Add(lease)
+			out = append(out, it.Queued)
+		}
+	}
+	return out, nil
+}
+
+func (o *memOutbox) find(id string) *memItem {
+	for _, it := range o.items {
+		if it.ID == id {
+			return it
+		}
+	}
+	return nil
+}
+
+func (o *memOutbox) MarkSent(ctx context.Context, id string) error {
+	o.find(id).sent = true
+	return nil
+}
+
+func (o *memOutbox) MarkFailed(ctx context.Context, id string, err string, retryAt *time.Time) error {
+	it := o.find(id)
+	it.lastErr = err
+	if retryAt == nil {
+		it.gaveUp = true
+	} else {
+		it.due = *retryAt
+	}
+	return nil
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, Backoff(1))
+	assert.Equal(t, 2*time.Minute, Backoff(2))
+	assert.Equal(t, 16*time.Minute, Backoff(5))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+}
+
+func TestWorker_SendDue(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	outbox := &memOutbox{now: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)}
+	for _, id := range []string{"a", "b", "c"} {
+		outbox.add(id, Message{To: id + "@example.com", Subject: "s", Text: "t"})
+	}
+	w := &Worker{
+		Outbox:      outbox,
+		Sender:      SMTPSender{Host: srv.Host(), Port: srv.Port(), From: "tms@example.com"},
+		MaxAttempts: 2,
+		BatchSize:   2,
+		Now:         func() time.Time { return outbox.now },
+	}
+	ctx := context.Background()
+
+	// The server is down: every message is scheduled for a retry
+	srv.SetReject(true)
+	sent, failed, err := w.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 3, failed)
+	assert.Equal(t, outbox.now.Add(time.Minute), outbox.find("a").due)
+	assert.Contains(t, outbox.find("a").lastErr, "451")
+
+	// Nothing is due before the backoff ends
+	sent, failed, _ = w.SendDue(ctx)
+	assert.Equal(t, 0, sent+failed)
+
+	// The retry succeeds
+	srv.SetReject(false)
+	outbox.now = outbox.now.Add(time.Minute)
+	sent, failed, err = w.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, sent)
+	assert.Equal(t, 0, failed)
+	assert.Len(t, srv.Messages(), 3)
+}
+
+func TestWorker_GivesUpAfterMaxAttempts(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.SetReject(true)
+
+	outbox := &memOutbox{now: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)}
+	outbox.add("a", Message{To: "a@example.com", Subject: "s", Text: "t"})
+	w := &Worker{
+		Outbox:      outbox,
+		Sender:      SMTPSender{Host: srv.Host(), Port: srv.Port(), From: "tms@example.com"},
+		MaxAttempts: 3,
+		Now:         func() time.Time { return outbox.now },
+	}
+	for i := 0; i < 5; i++ {
+		w.SendDue(context.Background())
+		outbox.now = outbox.now.Add(6 * time.Hour)
+	}
+	it := outbox.find("a")
+	assert.True(t, it.gaveUp)
+	assert.Equal(t, 3, it.Attempts)
+}
@@ -0,0 +1,324 @@
+// Package oidc signs users in through an OpenID Connect identity provider
+// with the authorization code flow and PKCE (RFC 7636).
+//
+// A Client discovers the provider's endpoints from its issuer URL the first
+// time it is used, sends the browser there with a fresh state, nonce and
+// code challenge, then trades the returned code for an ID token and checks
+// that token's RS256 signature against the provider's published keys, its
+// issuer, audience, expiry and nonce. oidctest is a provider for tests.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultTimeout bounds each request to the provider
+const DefaultTimeout = 10 * time.Second
+
+// keyRefetchInterval limits how often an unknown key ID makes the Client
+// fetch the provider's keys again, which is how it follows key rotation
+const keyRefetchInterval = time.Minute
+
+// Config identifies this application to the provider
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback route, registered with the provider
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim names the ID token claim that lists the user's groups
+	GroupsClaim string
+}
+
+// Identity is who the provider says signed in
+type Identity struct {
+	Subject string
+	Email   string
+	// EmailVerified is true only when the provider says so; some leave the
+	// claim out and let users edit their address
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// Client talks to one provider. It is safe for concurrent use.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu        sync.Mutex
+	meta      *metadata
+	keys      map[string]*rsa.PublicKey
+	keysFetch time.Time
+}
+
+// metadata is the part of the discovery document the Client uses
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New returns a Client for cfg; nothing is fetched until it is used. A nil
+// httpClient uses one with DefaultTimeout.
+func New(cfg Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &Client{cfg: cfg, http: httpClient}
+}
+
+// RandomString returns 256 random bits, URL-safe, for states, nonces and
+// code verifiers
+func RandomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Challenge is the S256 code challenge of a code verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthURL is where to send the browser to sign in. The provider redirects
+// back to RedirectURL with state and a code for Exchange.
+func (c *Client) AuthURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades an authorization code and its code verifier for the
+// signed-in identity, checking the ID token carries nonce
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	meta, err := c.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if c.cfg.ClientSecret == "" {
+		// Public clients identify themselves in the body
+		form.Set("client_id", c.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+	var tok struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.getJSON(req, &tok)
+	if err != nil {
+		return Identity{}, fmt.Errorf("token request: %w", err)
+	}
+	if status != http.StatusOK || tok.IDToken == "" {
+		return Identity{}, fmt.Errorf("token request: status %d: %s %s", status, tok.Error, tok.ErrorDescription)
+	}
+	return c.verify(ctx, meta, tok.IDToken, nonce)
+}
+
+// verify checks an ID token and reads the identity from it
+func (c *Client) verify(ctx context.Context, meta *metadata, raw, nonce string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	).ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, meta, kid)
+	})
+	if err != nil {
+		return Identity{}, fmt.Errorf("id token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return Identity{}, errors.New("id token: nonce does not match")
+	}
+
+	var id Identity
+	id.Subject, _ = claims["sub"].(string)
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+	if v, ok := claims["email_verified"].(bool); ok {
+		id.EmailVerified = v
+	}
+	switch groups := claims[c.cfg.GroupsClaim].(type) {
+	case string:
+		id.Groups = []string{groups}
+	case []any:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	}
+	if id.Subject == "" {
+		return Identity{}, errors.New("id token: no subject")
+	}
+	return id, nil
+}
+
+// discover fetches the provider's discovery document once
+func (c *Client) discover(ctx context.Context) (*metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.meta != nil {
+		return c.meta, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	status, err := c.getJSON(req, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery: status %d", status)
+	}
+	// The document must be the issuer's own (OpenID Connect Discovery 4.3)
+	if strings.TrimRight(meta.Issuer, "/") != strings.TrimRight(c.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", meta.Issuer, c.cfg.IssuerURL)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("discovery: missing endpoints")
+	}
+	c.meta = &meta
+	return c.meta, nil
+}
+
+// key returns the provider's signing key kid, fetching the key set again
+// when kid is unknown
+func (c *Client) key(ctx context.Context, meta *metadata, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if k, ok := c.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(c.keysFetch) < keyRefetchInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	c.keysFetch = time.Now()
+	keys, err := c.fetchKeys(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	// A provider with one key may leave kid out of its tokens
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// fetchKeys reads the RSA signing keys of a JSON Web Key Set
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	status, err := c.getJSON(req, &set)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("jwks: status %d", status)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// getJSON sends req and decodes a JSON body of at most 1 MiB into v
+func (c *Client) getJSON(req *http.Request, v any) (int, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return resp.StatusCode, fmt.Errorf("status %d: %w", resp.StatusCode, err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,138 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/oidc/oidctest"
+)
+
+const testRedirect = "http://localhost:8080/api/v1/auth/oidc/callback"
+
+// authorize follows AuthURL to the provider and returns the query it
+// redirects back with
+func authorize(t *testing.T, c *Client, state, nonce, verifier string) url.Values {
+	t.Helper()
+	authURL, err := c.AuthURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(authURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	back, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, testRedirect, back.Scheme+"://"+back.Host+back.Path)
+	return back.Query()
+}
+
+func newTestClient(t *testing.T) (*Client, *oidctest.Provider) {
+	t.Helper()
+	p, err := oidctest.NewProvider("tms", "s3cret")
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Close() })
+	return New(Config{IssuerURL: p.Issuer, ClientID: "tms", ClientSecret: "s3cret", RedirectURL: testRedirect}, nil), p
+}
+
+func TestClient_CodeFlow(t *testing.T) {
+	c, p := newTestClient(t)
+	verified := true
+	p.SetUser(oidctest.User{Subject: "u-1", Email: "somchai@example.com", EmailVerified: &verified, Name: "Somchai", Groups: []string{"it-staff", "everyone"}})
+
+	state, nonce, verifier := RandomString(), RandomString(), RandomString()
+	q := authorize(t, c, state, nonce, verifier)
+	require.Empty(t, q.Get("error"))
+	assert.Equal(t, state, q.Get("state"))
+
+	id, err := c.Exchange(context.Background(), q.Get("code"), verifier, nonce)
+	require.NoError(t, err)
+	assert.Equal(t, Identity{Subject: "u-1", Email: "somchai@example.com", EmailVerified: true, Name: "Somchai", Groups: []string{"it-staff", "everyone"}}, id)
+
+	// Codes work once
+	_, err = c.Exchange(context.Background(), q.Get("code"), verifier, nonce)
+	assert.Error(t, err)
+}
+
+func TestClient_Exchange_Rejects(t *testing.T) {
+	c, p := newTestClient(t)
+	p.SetUser(oidctest.User{Subject: "u-1", Email: "somchai@example.com"})
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		q := authorize(t, c, "s", "n", RandomString())
+		_, err := c.Exchange(context.Background(), q.Get("code"), RandomString(), "n")
+		assert.ErrorContains(t, err, "invalid_grant")
+	})
+	t.Run("wrong nonce", func(t *testing.T) {
+		verifier := RandomString()
+		q := authorize(t, c, "s", "n", verifier)
+		_, err := c.Exchange(context.Background(), q.Get("code"), verifier, "other")
+		assert.ErrorContains(t, err, "nonce")
+	})
+	t.Run("wrong client secret", func(t *testing.T) {
+		other := New(Config{IssuerURL: p.Issuer, ClientID: "tms", ClientSecret: "guess", RedirectURL: testRedirect}, nil)
+		verifier := RandomString()
+		q := authorize(t, other, "s", "n", verifier)
+		_, err := other.Exchange(context.Background(), q.Get("code"), verifier, "n")
+		assert.ErrorContains(t, err, "invalid_client")
+	})
+}
+
+func TestClient_EmailNotVerified(t *testing.T) {
+	c, p := newTestClient(t)
+	verified := false
+	for name, claim := range map[string]*bool{"claim false": &verified, "claim absent": nil} {
+		t.Run(name, func(t *testing.T) {
+			p.SetUser(oidctest.User{Subject: "u-2", Email: "new@example.com", EmailVerified: claim})
+			verifier := RandomString()
+			q := authorize(t, c, "s", "n", verifier)
+			id, err := c.Exchange(context.Background(), q.Get("code"), verifier, "n")
+			require.NoError(t, err)
+			assert.False(t, id.EmailVerified)
+			assert.Equal(t, "new@example.com", id.Email)
+			assert.Empty(t, id.Groups)
+		})
+	}
+}
+
+func TestClient_DiscoveryIssuerMismatch(t *testing.T) {
+	p, err := oidctest.NewProvider("tms", "s3cret")
+	require.NoError(t, err)
+	defer p.Close()
+	c := New(Config{IssuerURL: p.Issuer + "/tenant", ClientID: "tms", RedirectURL: testRedirect}, nil)
+	_, err = c.AuthURL(context.Background(), "s", "n", "v")
+	assert.Error(t, err)
+}
+
+func TestChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestParseRoleRules(t *testing.T) {
+	rules, err := ParseRoleRules(" it-managers=Manager, it-staff = Supervisor ,,")
+	require.NoError(t, err)
+	assert.Equal(t, []RoleRule{{"it-managers", models.RoleManager}, {"it-staff", models.RoleSupervisor}}, rules)
+
+	rules, err = ParseRoleRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, bad := range []string{"it-staff", "=Manager", "it-staff=Admin", "it-staff=Anonymous"} {
+		_, err := ParseRoleRules(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMapRole(t *testing.T) {
+	rules := []RoleRule{{"it-managers", models.RoleManager}, {"it-staff", models.RoleSupervisor}}
+	assert.Equal(t, models.RoleManager, MapRole(rules, []string{"it-staff", "it-managers"}, models.RoleUser))
+	assert.Equal(t, models.RoleSupervisor, MapRole(rules, []string{"it-staff"}, models.RoleUser))
+	assert.Equal(t, models.RoleUser, MapRole(rules, []string{"sales"}, models.RoleUser))
+	assert.Equal(t, models.Role(""), MapRole(rules, nil, ""))
+}
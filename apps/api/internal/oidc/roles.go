@@ -0,0 +1,61 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+// RoleRule gives members of an identity provider group a role
+type RoleRule struct {
+	Group string
+	Role  models.Role
+}
+
+// ParseRoleRules reads rules written as "group=Role" pairs separated by
+// commas, e.g. "it-managers=Manager,it-staff=Supervisor". Earlier rules
+// take precedence, and a rule with no role ("contractors=") keeps its group
+// out.
+func ParseRoleRules(s string) ([]RoleRule, error) {
+	var rules []RoleRule
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(part, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("role rule %q: want group=Role", part)
+		}
+		r, err := ParseRole(role)
+		if err != nil {
+			return nil, fmt.Errorf("role rule %q: %w", part, err)
+		}
+		rules = append(rules, RoleRule{Group: group, Role: r})
+	}
+	return rules, nil
+}
+
+// ParseRole reads a role name; empty is allowed and means no role
+func ParseRole(s string) (models.Role, error) {
+	switch r := models.Role(s); r {
+	case "", models.RoleUser, models.RoleSupervisor, models.RoleManager:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// MapRole returns the role of the first rule whose group is in groups, or
+// def when none matches. An empty result means the user may not sign in.
+func MapRole(rules []RoleRule, groups []string, def models.Role) models.Role {
+	for _, rule := range rules {
+		for _, g := range groups {
+			if g == rule.Group {
+				return rule.Role
+			}
+		}
+	}
+	return def
+}
@@ -1,5 +1,7 @@
 package priority
 
+import "time"
+
 type PriorityOutput struct {
 	Impact   int  `json:"impact"`
 	Urgency  int  `json:"urgency"`
@@ -21,6 +23,25 @@ type PriorityInput struct {
 		DataLoss         bool `json:"dataLoss"`
 	} `json:"impact"`
 	Urgency string `json:"urgency"` // "<=48h" | "3-7d" | "8-30d" | ">=31d" | "none"
+	// Deadline, when given, takes precedence over Urgency. Handlers convert it
+	// to an urgency bucket using the business calendar (see UrgencyForBusinessDays).
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// UrgencyForBusinessDays maps the number of business days left until a
+// deadline to an urgency bucket, so weekends and holidays do not make a
+// deadline look further away than the team's working time allows.
+func UrgencyForBusinessDays(days int) string {
+	switch {
+	case days <= 2:
+		return "<=48h"
+	case days <= 7:
+		return "3-7d"
+	case days <= 30:
+		return "8-30d"
+	default:
+		return ">=31d"
+	}
 }
 
 func Compute(p PriorityInput) PriorityOutput {
@@ -61,6 +82,34 @@ func Compute(p PriorityInput) PriorityOutput {
 		if final > 10 { final = 10 }  // Cap at maximum 10 points
 	}
 
+	return PriorityOutput{
+		Impact:   impact,
+		Urgency:  urgency,
+		Final:    final,
+		RedFlag:  red,
+		Priority: priorityFor(final, red),
+	}
+}
+
+// FromScores derives the priority from already stored impact and urgency scores,
+// e.g. when only the red flags of a ticket change.
+func FromScores(impact, urgency int, red bool) PriorityOutput {
+	final := 10
+	if !red {
+		final = impact + urgency
+		if final > 10 { final = 10 }
+	}
+	return PriorityOutput{
+		Impact:   impact,
+		Urgency:  urgency,
+		Final:    final,
+		RedFlag:  red,
+		Priority: priorityFor(final, red),
+	}
+}
+
+// priorityFor maps a final score to a priority
+func priorityFor(final int, red bool) string {
 	// Determine priority based on score ranges
 	priority := "P3"
 	if red || final == 10 {
@@ -71,12 +120,5 @@ func Compute(p PriorityInput) PriorityOutput {
 		priority = "P2"  // Medium score (5-7)
 	}
 	// P3 for low scores (0-4)
-
-	return PriorityOutput{
-		Impact:   impact,
-		Urgency:  urgency,
-		Final:    final,
-		RedFlag:  red,
-		Priority: priority,
-	}
+	return priority
 }
\ No newline at end of file
@@ -63,4 +63,32 @@ func TestPriority(t *testing.T) {
 	if out.Priority != "P0" || out.Final != 10 || out.Impact != 0 || out.Urgency != 0 {
 		t.Fatalf("expected P0/10 with impact 0, urgency 0, got %s/%d with impact %d, urgency %d", out.Priority, out.Final, out.Impact, out.Urgency)
 	}
-}
\ No newline at end of file
+}
+
+func TestFromScores(t *testing.T) {
+	// Red flag forces P0 regardless of stored scores
+	out := FromScores(2, 1, true)
+	if out.Priority != "P0" || out.Final != 10 {
+		t.Fatalf("expected P0/10, got %s/%d", out.Priority, out.Final)
+	}
+
+	// Clearing the red flag falls back to impact + urgency
+	out = FromScores(4, 4, false)
+	if out.Priority != "P1" || out.Final != 8 {
+		t.Fatalf("expected P1/8, got %s/%d", out.Priority, out.Final)
+	}
+
+	out = FromScores(2, 1, false)
+	if out.Priority != "P3" || out.Final != 3 {
+		t.Fatalf("expected P3/3, got %s/%d", out.Priority, out.Final)
+	}
+}
+
+func TestUrgencyForBusinessDays(t *testing.T) {
+	cases := map[int]string{0: "<=48h", 2: "<=48h", 3: "3-7d", 7: "3-7d", 8: "8-30d", 30: "8-30d", 31: ">=31d"}
+	for days, want := range cases {
+		if got := UrgencyForBusinessDays(days); got != want {
+			t.Fatalf("%d business days: expected %s, got %s", days, want, got)
+		}
+	}
+}
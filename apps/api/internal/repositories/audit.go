@@ -4,16 +4,28 @@ import (
 	"context"
 	"encoding/json"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/it-tms/apps/api/internal/sqlc"
 )
 
-type AuditRepo struct{ pool *pgxpool.Pool }
+type AuditRepo struct{ q *sqlc.Queries }
 
 func (r *AuditRepo) Insert(ctx context.Context, ticketID string, actorID *string, action string, before, after any) error {
 	var b []byte
 	if after != nil {
 		b, _ = json.Marshal(after)
 	}
-	_, err := r.pool.Exec(ctx, `INSERT INTO audit_logs (ticket_id, actor_id, action, after) VALUES ($1,$2,$3,$4)`, ticketID, actorID, action, b)
-	return err
-}
\ No newline at end of file
+	return r.q.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{TicketID: &ticketID, ActorID: actorID, Action: action, After: b})
+}
+
+// InsertUser records a change to a user account, with its state before
+// and after when given
+func (r *AuditRepo) InsertUser(ctx context.Context, userID string, actorID *string, action string, before, after any) error {
+	var b, a []byte
+	if before != nil {
+		b, _ = json.Marshal(before)
+	}
+	if after != nil {
+		a, _ = json.Marshal(after)
+	}
+	return r.q.CreateUserAuditLog(ctx, sqlc.CreateUserAuditLogParams{UserID: userID, ActorID: actorID, Action: action, Before: b, After: a})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+type CalendarRepo struct{ q *sqlc.Queries }
+
+// GetWeeklySchedule returns the working window for every weekday, Sunday first
+func (r *CalendarRepo) GetWeeklySchedule(ctx context.Context) ([]models.BusinessHours, error) {
+	rows, err := r.q.ListBusinessHours(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hours := []models.BusinessHours{}
+	for _, bh := range rows {
+		hours = append(hours, models.BusinessHours{Weekday: int(bh.Weekday), StartTime: bh.StartTime, EndTime: bh.EndTime, IsWorkingDay: bh.IsWorkingDay})
+	}
+	return hours, nil
+}
+
+// UpsertBusinessHours replaces the working window of the given weekdays in one statement batch
+func (r *CalendarRepo) UpsertBusinessHours(ctx context.Context, hours []models.BusinessHours) error {
+	params := make([]sqlc.UpsertBusinessHoursParams, 0, len(hours))
+	for _, bh := range hours {
+		params = append(params, sqlc.UpsertBusinessHoursParams{
+			Weekday:      int16(bh.Weekday),
+			StartTime:    bh.StartTime,
+			EndTime:      bh.EndTime,
+			IsWorkingDay: bh.IsWorkingDay,
+		})
+	}
+	var err error
+	r.q.UpsertBusinessHours(ctx, params).Exec(func(_ int, e error) {
+		if err == nil {
+			err = e
+		}
+	})
+	return err
+}
+
+// ListHolidays returns holidays ordered by date, optionally limited to one year
+func (r *CalendarRepo) ListHolidays(ctx context.Context, year *int) ([]models.Holiday, error) {
+	rows, err := r.q.ListHolidays(ctx, optInt32(year))
+	if err != nil {
+		return nil, err
+	}
+	holidays := []models.Holiday{}
+	for _, hd := range rows {
+		holidays = append(holidays, models.Holiday{ID: hd.ID, Date: hd.Date, Name: hd.Name, CreatedAt: hd.CreatedAt})
+	}
+	return holidays, nil
+}
+
+// AddHoliday creates a holiday, or renames it if the date already exists
+func (r *CalendarRepo) AddHoliday(ctx context.Context, date, name string) (models.Holiday, error) {
+	hd, err := r.q.AddHoliday(ctx, sqlc.AddHolidayParams{Date: date, Name: name})
+	return models.Holiday{ID: hd.ID, Date: hd.Date, Name: hd.Name, CreatedAt: hd.CreatedAt}, err
+}
+
+// DeleteHoliday removes a holiday by id
+func (r *CalendarRepo) DeleteHoliday(ctx context.Context, id string) error {
+	n, err := r.q.DeleteHoliday(ctx, id)
+	if err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return err
+}
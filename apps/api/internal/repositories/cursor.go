@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for cursor tokens this API did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a row in a keyset-paginated list by its sort key and id, and
+// says whether the page wanted lies after it or before it. Time is the
+// ticket's updated_at or the comment's created_at; Rank and Effort are only
+// used for tickets.
+type Cursor struct {
+	Before bool      `json:"b,omitempty"`
+	Rank   int16     `json:"r,omitempty"`
+	Time   time.Time `json:"t"`
+	Effort int16     `json:"e,omitempty"`
+	ID     string    `json:"i"`
+}
+
+// CursorPage holds the tokens for the pages either side of the one returned;
+// a nil token means there is nothing further in that direction.
+type CursorPage struct {
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
+}
+
+// Encode returns the opaque token handed to clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode
+func DecodeCursor(token string) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// cursorPage trims rows fetched with limit+1 to the page and works out the
+// neighbouring cursors. Rows read before the cursor arrive in reverse order
+// and are put back into list order; at gives the cursor of a row.
+func cursorPage[T any](rows []T, cur *Cursor, limit int, at func(T) Cursor) ([]T, CursorPage) {
+	var page CursorPage
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	backward := cur != nil && cur.Before
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	// Going forward there is a previous page whenever we started from a
+	// cursor; going backward there is always a next page, the cursor row.
+	if backward || more {
+		next := at(rows[len(rows)-1])
+		token := next.Encode()
+		page.NextCursor = &token
+	}
+	if (backward && more) || (!backward && cur != nil) {
+		prev := at(rows[0])
+		prev.Before = true
+		token := prev.Encode()
+		page.PrevCursor = &token
+	}
+	return rows, page
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	c := Cursor{Before: true, Rank: 2, Time: time.Date(2025, 3, 1, 9, 30, 0, 123456000, time.UTC), Effort: 4, ID: "7d1c"}
+	got, err := DecodeCursor(c.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, c, got)
+
+	for _, token := range []string{"", "not base64!", "bm90IGpzb24", Cursor{Time: c.Time}.Encode()} {
+		_, err := DecodeCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	at := func(n int) Cursor { return Cursor{ID: strconv.Itoa(n)} }
+	decode := func(token *string) Cursor {
+		require.NotNil(t, token)
+		c, err := DecodeCursor(*token)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("first page with more rows", func(t *testing.T) {
+		rows, page := cursorPage([]int{1, 2, 3}, nil, 2, at)
+		assert.Equal(t, []int{1, 2}, rows)
+		assert.Equal(t, Cursor{ID: "2"}, decode(page.NextCursor))
+		assert.Nil(t, page.PrevCursor)
+	})
+
+	t.Run("last page going forward", func(t *testing.T) {
+		rows, page := cursorPage([]int{3, 4}, &Cursor{ID: "2"}, 2, at)
+		assert.Equal(t, []int{3, 4}, rows)
+		assert.Nil(t, page.NextCursor)
+		assert.Equal(t, Cursor{Before: true, ID: "3"}, decode(page.PrevCursor))
+	})
+
+	t.Run("going backward restores list order", func(t *testing.T) {
+		rows, page := cursorPage([]int{4, 3, 2}, &Cursor{Before: true, ID: "5"}, 2, at)
+		assert.Equal(t, []int{3, 4}, rows)
+		assert.Equal(t, Cursor{ID: "4"}, decode(page.NextCursor))
+		assert.Equal(t, Cursor{Before: true, ID: "3"}, decode(page.PrevCursor))
+	})
+
+	t.Run("first page reached going backward", func(t *testing.T) {
+		rows, page := cursorPage([]int{2, 1}, &Cursor{Before: true, ID: "3"}, 2, at)
+		assert.Equal(t, []int{1, 2}, rows)
+		assert.Equal(t, Cursor{ID: "2"}, decode(page.NextCursor))
+		assert.Nil(t, page.PrevCursor)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		rows, page := cursorPage([]int{}, nil, 2, at)
+		assert.Empty(t, rows)
+		assert.Equal(t, CursorPage{}, page)
+	})
+}
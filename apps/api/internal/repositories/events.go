@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/events"
+	"github.com/it-tms/apps/api/internal/sqlc"
+	"github.com/it-tms/apps/api/internal/webhooks"
+)
+
+type EventRepo struct{ q *sqlc.Queries }
+
+// Publish notifies listeners of a ticket change, adding the ticket's current
+// assignees to e.Assignees, and queues webhook deliveries for the event
+// types webhooks support. Inside WithTx the event goes out when the
+// transaction commits, and never if it rolls back.
+func (r *EventRepo) Publish(ctx context.Context, e events.Event) error {
+	assignees, err := assigneesByTicket(ctx, r.q, []string{e.TicketID})
+	if err != nil {
+		return err
+	}
+	e.Assignees = slices.Clone(e.Assignees)
+	for _, u := range assignees[e.TicketID] {
+		if !slices.Contains(e.Assignees, u.ID) {
+			e.Assignees = append(e.Assignees, u.ID)
+		}
+	}
+	if e.Assignees == nil {
+		e.Assignees = []string{}
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := r.q.NotifyEvent(ctx, sqlc.NotifyEventParams{Channel: events.Channel, Payload: string(payload)}); err != nil {
+		return err
+	}
+	if !webhooks.Supports(e.Type) {
+		return nil
+	}
+	row, err := r.q.GetTicket(ctx, e.TicketID)
+	if err != nil {
+		return err
+	}
+	return (&WebhookRepo{q: r.q}).Enqueue(ctx, webhooks.Payload{
+		Event: e.Type,
+		Ticket: webhooks.Ticket{
+			ID:        e.TicketID,
+			Code:      row.Ticket.Code,
+			Title:     row.Ticket.Title,
+			Status:    row.Ticket.Status,
+			Priority:  row.Ticket.Priority,
+			Assignees: e.Assignees,
+		},
+		ActorID: e.ActorID,
+		Data:    e.Data,
+		At:      e.At,
+	})
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/events"
+	"github.com/it-tms/apps/api/internal/webhooks"
+)
+
+func TestEventRepo_PublishAddsAssignees(t *testing.T) {
+	db := newSeededDB(1)
+	removed := []string{"user-removed"}
+	err := newRepo(db).Events.Publish(context.Background(), events.Event{
+		Type:      events.TicketUnassigned,
+		TicketID:  "ticket-0000",
+		Assignees: removed,
+	})
+	require.NoError(t, err)
+
+	args := db.args["NotifyEvent"]
+	require.Len(t, args, 2)
+	assert.Equal(t, events.Channel, args[0])
+	var e events.Event
+	require.NoError(t, json.Unmarshal([]byte(args[1].(string)), &e))
+	assert.Equal(t, events.TicketUnassigned, e.Type)
+	assert.Equal(t, []string{"user-removed", "user-0000-a", "user-0000-b"}, e.Assignees)
+	assert.False(t, e.At.IsZero())
+	assert.Equal(t, []string{"user-removed"}, removed)
+}
+
+func TestEventRepo_PublishQueuesWebhooks(t *testing.T) {
+	db := newSeededDB(1)
+	repo := newRepo(db)
+
+	require.NoError(t, repo.Events.Publish(context.Background(), events.Event{Type: events.TicketUpdated, TicketID: "ticket-0000"}))
+	assert.NotContains(t, db.args, "EnqueueWebhookDeliveries", "ticket.updated is not a webhook event")
+
+	actor := "user-actor"
+	require.NoError(t, repo.Events.Publish(context.Background(), events.Event{
+		Type:     events.TicketClassified,
+		TicketID: "ticket-0000",
+		ActorID:  &actor,
+		Data:     map[string]any{"resolvedType": "DATA_CORRECTION"},
+	}))
+	args := db.args["EnqueueWebhookDeliveries"]
+	require.Len(t, args, 2)
+	assert.Equal(t, events.TicketClassified, args[0])
+	var p webhooks.Payload
+	require.NoError(t, json.Unmarshal(args[1].([]byte), &p))
+	assert.Equal(t, events.TicketClassified, p.Event)
+	assert.Equal(t, "ticket-0000", p.Ticket.ID)
+	assert.Equal(t, []string{"user-0000-a", "user-0000-b"}, p.Ticket.Assignees)
+	assert.Equal(t, &actor, p.ActorID)
+	assert.Equal(t, "DATA_CORRECTION", p.Data["resolvedType"])
+	assert.False(t, p.At.IsZero())
+}
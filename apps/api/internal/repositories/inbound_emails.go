@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// InboundEmailRepo remembers the messages the email gateway has handled
+type InboundEmailRepo struct{ q *sqlc.Queries }
+
+// Record notes that a message opened or commented on a ticket. It reports
+// false, recording nothing, when the message was handled before.
+func (r *InboundEmailRepo) Record(ctx context.Context, messageID, sender, ticketID string, commentID *string) (bool, error) {
+	n, err := r.q.RecordInboundEmail(ctx, sqlc.RecordInboundEmailParams{
+		MessageID: messageID,
+		Sender:    sender,
+		TicketID:  ticketID,
+		CommentID: commentID,
+	})
+	return n > 0, err
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// InvitationRepo stores the invitations Managers send to new users
+type InvitationRepo struct{ q *sqlc.Queries }
+
+// InvitationStatus is where an invitation is in its life
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+// Valid reports whether s is one of the statuses above
+func (s InvitationStatus) Valid() bool {
+	switch s {
+	case InvitationPending, InvitationAccepted, InvitationRevoked, InvitationExpired:
+		return true
+	}
+	return false
+}
+
+// Invitation is an email address invited to sign up with a role
+type Invitation struct {
+	ID         string           `json:"id"`
+	Email      string           `json:"email"`
+	Role       models.Role      `json:"role"`
+	InvitedBy  *string          `json:"invitedBy"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	ExpiresAt  time.Time        `json:"expiresAt"`
+	AcceptedAt *time.Time       `json:"acceptedAt,omitempty"`
+	AcceptedBy *string          `json:"acceptedBy,omitempty"`
+	RevokedAt  *time.Time       `json:"revokedAt,omitempty"`
+	Status     InvitationStatus `json:"status"`
+}
+
+// Create invites email with role until ttl from now, replacing the address's
+// open invitation if it has one
+func (r *InvitationRepo) Create(ctx context.Context, email string, role models.Role, invitedBy *string, ttl time.Duration) (Invitation, error) {
+	if err := r.q.RevokeOpenInvitations(ctx, email); err != nil {
+		return Invitation{}, err
+	}
+	row, err := r.q.CreateInvitation(ctx, sqlc.CreateInvitationParams{
+		Email:     email,
+		Role:      role,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return Invitation{}, err
+	}
+	return invitationFromRow(row, time.Now()), nil
+}
+
+// Get returns an invitation by id, or ErrNotFound
+func (r *InvitationRepo) Get(ctx context.Context, id string) (Invitation, error) {
+	if !isUUID(id) {
+		return Invitation{}, ErrNotFound
+	}
+	row, err := r.q.GetInvitation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Invitation{}, ErrNotFound
+		}
+		return Invitation{}, err
+	}
+	return invitationFromRow(row, time.Now()), nil
+}
+
+// List returns invitations newest first, only those in status when it is
+// set, and the total that match
+func (r *InvitationRepo) List(ctx context.Context, status InvitationStatus, offset, limit int) ([]Invitation, int64, error) {
+	var s *string
+	if status != "" {
+		v := string(status)
+		s = &v
+	}
+	rows, err := r.q.ListInvitations(ctx, sqlc.ListInvitationsParams{Status: s, MaxResults: int32(limit), Skip: int32(offset)})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.q.CountInvitations(ctx, s)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	invitations := []Invitation{}
+	for _, row := range rows {
+		invitations = append(invitations, invitationFromRow(row, now))
+	}
+	return invitations, total, nil
+}
+
+// Revoke withdraws an invitation that has not been accepted; it reports
+// whether there was one to withdraw
+func (r *InvitationRepo) Revoke(ctx context.Context, id string) (bool, error) {
+	if !isUUID(id) {
+		return false, nil
+	}
+	n, err := r.q.RevokeInvitation(ctx, id)
+	return n > 0, err
+}
+
+// Accept marks a pending invitation as redeemed by userID. It reports false
+// when the invitation was already accepted, revoked or has expired, so a
+// token can only be used once.
+func (r *InvitationRepo) Accept(ctx context.Context, id, userID string) (bool, error) {
+	n, err := r.q.AcceptInvitation(ctx, sqlc.AcceptInvitationParams{AcceptedBy: userID, ID: id})
+	return n > 0, err
+}
+
+func invitationFromRow(row sqlc.Invitation, now time.Time) Invitation {
+	inv := Invitation{
+		ID:         row.ID,
+		Email:      row.Email,
+		Role:       row.Role,
+		InvitedBy:  row.InvitedBy,
+		CreatedAt:  row.CreatedAt,
+		ExpiresAt:  row.ExpiresAt,
+		AcceptedAt: row.AcceptedAt,
+		AcceptedBy: row.AcceptedBy,
+		RevokedAt:  row.RevokedAt,
+	}
+	switch {
+	case row.AcceptedAt != nil:
+		inv.Status = InvitationAccepted
+	case row.RevokedAt != nil:
+		inv.Status = InvitationRevoked
+	case !now.Before(row.ExpiresAt):
+		inv.Status = InvitationExpired
+	default:
+		inv.Status = InvitationPending
+	}
+	return inv
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+func TestInvitationRepo_CreateReplacesOpenInvitation(t *testing.T) {
+	db := newRecordDB()
+	manager := "manager-1"
+	_, err := newRepo(db).Invitations.Create(context.Background(), "new@example.com", models.RoleSupervisor, &manager, 48*time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"new@example.com"}, db.args["RevokeOpenInvitations"])
+	args := db.args["CreateInvitation"]
+	require.Len(t, args, 4)
+	assert.Equal(t, []any{"new@example.com", models.RoleSupervisor, &manager}, args[:3])
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), args[3].(time.Time), time.Minute)
+}
+
+func TestInvitationRepo_AcceptOnlyOnce(t *testing.T) {
+	db := newRecordDB()
+	// Nothing matches an invitation that is no longer pending
+	accepted, err := newRepo(db).Invitations.Accept(context.Background(), "invitation-1", "user-1")
+	require.NoError(t, err)
+	assert.False(t, accepted)
+	assert.Equal(t, []any{"user-1", "invitation-1"}, db.args["AcceptInvitation"])
+}
+
+func TestInvitationRepo_ListByStatus(t *testing.T) {
+	db := newRecordDB()
+	_, _, err := newRepo(db).Invitations.List(context.Background(), InvitationExpired, 20, 20)
+	require.NoError(t, err)
+	status := "expired"
+	assert.Equal(t, []any{&status, int32(20), int32(20)}, db.args["ListInvitations"])
+	assert.Equal(t, []any{&status}, db.args["CountInvitations"])
+
+	_, _, err = newRepo(db).Invitations.List(context.Background(), "", 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []any{(*string)(nil)}, db.args["CountInvitations"])
+}
+
+func TestInvitationFromRow_Status(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	for _, tc := range []struct {
+		name string
+		row  sqlc.Invitation
+		want InvitationStatus
+	}{
+		{"pending", sqlc.Invitation{ExpiresAt: later}, InvitationPending},
+		{"expired", sqlc.Invitation{ExpiresAt: earlier}, InvitationExpired},
+		{"revoked", sqlc.Invitation{ExpiresAt: later, RevokedAt: &earlier}, InvitationRevoked},
+		// Accepting wins over expiring afterwards
+		{"accepted", sqlc.Invitation{ExpiresAt: earlier, AcceptedAt: &earlier}, InvitationAccepted},
+	} {
+		assert.Equal(t, tc.want, invitationFromRow(tc.row, now).Status, tc.name)
+	}
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// MentionRepo stores the people comments mention
+type MentionRepo struct{ q *sqlc.Queries }
+
+// Mention is a comment that mentioned a user, with its ticket
+type Mention struct {
+	ID              string    `json:"id"`
+	TicketID        string    `json:"ticketId"`
+	TicketCode      int32     `json:"ticketCode"`
+	TicketTitle     string    `json:"ticketTitle"`
+	CommentID       string    `json:"commentId"`
+	Comment         string    `json:"comment"`
+	MentionedBy     *string   `json:"mentionedBy"`
+	MentionedByName *string   `json:"mentionedByName"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Add records that a comment mentions userID, making them a watcher of the
+// ticket as well when watch is set; repeats are ignored
+func (r *MentionRepo) Add(ctx context.Context, commentID, ticketID, userID string, mentionedBy *string, watch bool) error {
+	if err := r.q.CreateMention(ctx, sqlc.CreateMentionParams{
+		CommentID:   commentID,
+		TicketID:    ticketID,
+		UserID:      userID,
+		MentionedBy: mentionedBy,
+	}); err != nil {
+		return err
+	}
+	if !watch {
+		return nil
+	}
+	return r.q.WatchTicket(ctx, sqlc.WatchTicketParams{TicketID: ticketID, UserID: userID})
+}
+
+// List returns a page of the comments that mention userID, newest first,
+// and how many there are in all
+func (r *MentionRepo) List(ctx context.Context, userID string, offset, limit int) ([]Mention, int64, error) {
+	rows, err := r.q.ListMentions(ctx, sqlc.ListMentionsParams{UserID: userID, MaxResults: int32(limit), Skip: int32(offset)})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.q.CountMentions(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	mentions := []Mention{}
+	for _, row := range rows {
+		mentions = append(mentions, Mention{
+			ID:              row.ID,
+			TicketID:        row.TicketID,
+			TicketCode:      row.Code,
+			TicketTitle:     row.Title,
+			CommentID:       row.CommentID,
+			Comment:         row.Body,
+			MentionedBy:     row.MentionedBy,
+			MentionedByName: row.MentionedByName,
+			CreatedAt:       row.CreatedAt,
+		})
+	}
+	return mentions, total, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMentionRepo_List(t *testing.T) {
+	db := newRecordDB()
+	name := "mentioner"
+	db.rows["ListMentions"] = [][]any{
+		{"mention-1", "ticket-0000", int32(1), "title", "comment-ticket-0000", "@user-1 look", &name, &name, time.Time{}},
+		{"mention-2", "ticket-0001", int32(2), "title", "comment-ticket-0001", "@user-1 look", &name, &name, time.Time{}},
+	}
+	db.rows["CountMentions"] = [][]any{{int64(2)}}
+	mentions, total, err := newRepo(db).Mentions.List(context.Background(), "user-1", 20, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, mentions, 2)
+	assert.Equal(t, "ticket-0000", mentions[0].TicketID)
+	assert.Equal(t, "comment-ticket-0000", mentions[0].CommentID)
+	assert.Equal(t, "mentioner", *mentions[0].MentionedByName)
+	assert.Equal(t, []any{"user-1", int32(10), int32(20)}, db.args["ListMentions"])
+}
+
+func TestMentionRepo_ListEmpty(t *testing.T) {
+	db := newRecordDB()
+	db.rows["CountMentions"] = [][]any{{int64(0)}}
+	mentions, total, err := newRepo(db).Mentions.List(context.Background(), "user-1", 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.NotNil(t, mentions)
+}
+
+func TestMentionRepo_AddWatch(t *testing.T) {
+	author := "user-2"
+	for _, watch := range []bool{true, false} {
+		db := newRecordDB()
+		require.NoError(t, newRepo(db).Mentions.Add(context.Background(), "comment-1", "ticket-1", "user-1", &author, watch))
+		assert.Equal(t, []any{"comment-1", "ticket-1", "user-1", &author}, db.args["CreateMention"])
+		if watch {
+			assert.Equal(t, []any{"ticket-1", "user-1"}, db.args["WatchTicket"])
+		} else {
+			assert.NotContains(t, db.args, "WatchTicket")
+		}
+	}
+}
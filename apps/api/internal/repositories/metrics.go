@@ -2,12 +2,14 @@ package repositories
 
 import (
 	"context"
+	"sort"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
 )
 
-type MetricsRepo struct{ pool *pgxpool.Pool }
+type MetricsRepo struct{ q *sqlc.Queries }
 
 type MetricsSummary struct {
 	InProgressToday    []TicketSummary     `json:"inProgressToday"`
@@ -15,6 +17,9 @@ type MetricsSummary struct {
 	CategoryCounts     map[string]int      `json:"categoryCounts"`
 	PriorityCounts     map[string]int      `json:"priorityCounts"`
 	IssueReportCounts  map[string]int      `json:"issueReportCounts"`
+	// Reopens: tickets reopened at least once, as a share of tickets ever completed
+	ReopenedCount      int                 `json:"reopenedCount"`
+	ReopenRate         *float64            `json:"reopenRate"`
 }
 
 type AssigneeSummary struct {
@@ -47,162 +52,148 @@ func (r *MetricsRepo) SummaryWithDateFilter(ctx context.Context, month *int, yea
 	res.InProgressToday = []TicketSummary{} // Initialize as empty slice to avoid null
 
 	// In progress tickets (all currently active ones, not just updated today)
-	rows, err := r.pool.Query(ctx, `
-		SELECT 
-			t.id, 
-			t.title, 
-			t.priority, 
-			t.assignee_id,
-			u.name as assignee_name,
-			t.updated_at,
-			(SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) as latest_comment,
-			(SELECT STRING_AGG(au.name, ', ' ORDER BY au.name) 
-			 FROM ticket_assignments ta 
-			 JOIN users au ON ta.assignee_id = au.id 
-			 WHERE ta.ticket_id = t.id) as assignee_names
-		FROM tickets t
-		LEFT JOIN users u ON t.assignee_id = u.id
-		WHERE t.status='in_progress'
-		ORDER BY 
-			CASE t.priority 
-				WHEN 'P0' THEN 0 
-				WHEN 'P1' THEN 1 
-				WHEN 'P2' THEN 2 
-				WHEN 'P3' THEN 3 
-				ELSE 4 
-			END ASC, 
-			t.updated_at DESC, 
-			t.effort_score ASC 
-		LIMIT 20`)
+	rows, err := r.q.ListInProgressTickets(ctx)
 	if err != nil {
-		// Log error but continue with empty slice
 		return res, err
 	}
-	for rows.Next() {
-		var ticket TicketSummary
-		var latestComment *string
-		var assigneeNames *string
-		err := rows.Scan(&ticket.ID, &ticket.Title, &ticket.Priority, &ticket.AssigneeID, &ticket.AssigneeName, &ticket.UpdatedAt, &latestComment, &assigneeNames)
-		if err != nil {
-			continue // Skip this row if scan fails
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	assignees, err := assigneesByTicket(ctx, r.q, ids)
+	if err != nil {
+		return res, err
+	}
+	for _, row := range rows {
+		ticket := TicketSummary{
+			ID:            row.ID,
+			Title:         row.Title,
+			Priority:      string(row.Priority),
+			AssigneeID:    row.AssigneeID,
+			AssigneeName:  row.AssigneeName,
+			UpdatedAt:     row.UpdatedAt,
+			LatestComment: row.LatestComment,
 		}
-		ticket.LatestComment = latestComment
-		
+
 		// Use new assignee names if available, fallback to old single assignee
-		if assigneeNames != nil && *assigneeNames != "" {
-			ticket.AssigneeName = assigneeNames
-		}
-		
-		// Fetch detailed assignee information
-		assigneeRows, assigneeErr := r.pool.Query(ctx, `
-			SELECT u.id, u.name, u.profile_picture
-			FROM ticket_assignments ta
-			JOIN users u ON ta.assignee_id = u.id
-			WHERE ta.ticket_id = $1
-			ORDER BY u.name ASC`, ticket.ID)
-		
-		if assigneeErr == nil {
-			for assigneeRows.Next() {
-				var assignee AssigneeSummary
-				assigneeRows.Scan(&assignee.ID, &assignee.Name, &assignee.ProfilePicture)
-				ticket.Assignees = append(ticket.Assignees, assignee)
-			}
-			assigneeRows.Close()
+		if row.AssigneeNames != nil && *row.AssigneeNames != "" {
+			ticket.AssigneeName = row.AssigneeNames
 		}
-		
-		// If no new assignees found but there's a legacy assignee, add it
-		if len(ticket.Assignees) == 0 && ticket.AssigneeID != nil && ticket.AssigneeName != nil {
-			legacyAssignee := AssigneeSummary{
-				ID:   *ticket.AssigneeID,
-				Name: *ticket.AssigneeName,
-			}
-			// Try to get profile picture for legacy assignee
-			var profilePicture *string
-			profileRow := r.pool.QueryRow(ctx, `SELECT profile_picture FROM users WHERE id = $1`, *ticket.AssigneeID)
-			profileRow.Scan(&profilePicture)
-			legacyAssignee.ProfilePicture = profilePicture
-			ticket.Assignees = append(ticket.Assignees, legacyAssignee)
+
+		// Detailed assignee information, including a legacy single assignee
+		for _, a := range assignees[ticket.ID] {
+			ticket.Assignees = append(ticket.Assignees, AssigneeSummary{ID: a.ID, Name: a.Name, ProfilePicture: a.ProfilePicture})
 		}
-		
+		sort.Slice(ticket.Assignees, func(i, j int) bool { return ticket.Assignees[i].Name < ticket.Assignees[j].Name })
+
 		res.InProgressToday = append(res.InProgressToday, ticket)
 	}
-	rows.Close()
 
-	// Build date filter condition
-	dateFilter := ""
-	var args []interface{}
-	if year != nil {
-		if month != nil {
-			// Both month and year filtering
-			dateFilter = " WHERE EXTRACT(MONTH FROM created_at) = $1 AND EXTRACT(YEAR FROM created_at) = $2"
-			args = []interface{}{*month, *year}
-		} else {
-			// Year-only filtering
-			dateFilter = " WHERE EXTRACT(YEAR FROM created_at) = $1"
-			args = []interface{}{*year}
-		}
+	// Date filter; the month only applies together with a year
+	if year == nil {
+		month = nil
+	}
+	y, m := optInt32(year), optInt32(month)
+
+	// Status counts; every status is reported, including paused ones with no tickets
+	for _, status := range []models.TicketStatus{models.StatusPending, models.StatusInProgress, models.StatusOnHold, models.StatusWaitingForRequester, models.StatusCompleted, models.StatusCanceled} {
+		res.StatusCounts[string(status)] = 0
+	}
+	statusRows, err := r.q.CountTicketsByStatus(ctx, sqlc.CountTicketsByStatusParams{Year: y, Month: m})
+	if err != nil {
+		return res, err
+	}
+	for _, row := range statusRows {
+		res.StatusCounts[string(row.Status)] = int(row.Count)
 	}
 
-	// Status counts
-	r.countIntoWithDateFilter(ctx, `SELECT status, COUNT(*) FROM tickets`+dateFilter+` GROUP BY status`, args, res.StatusCounts)
 	// Category (by resolved_type if available, otherwise initial_type)
-	r.countIntoWithDateFilter(ctx, `SELECT COALESCE(resolved_type::text, initial_type::text), COUNT(*) FROM tickets`+dateFilter+` GROUP BY COALESCE(resolved_type::text, initial_type::text)`, args, res.CategoryCounts)
+	categoryRows, err := r.q.CountTicketsByCategory(ctx, sqlc.CountTicketsByCategoryParams{Year: y, Month: m})
+	if err != nil {
+		return res, err
+	}
+	for _, row := range categoryRows {
+		res.CategoryCounts[row.Category] = int(row.Count)
+	}
+
 	// Priority counts
-	r.countIntoWithDateFilter(ctx, `SELECT priority, COUNT(*) FROM tickets`+dateFilter+` GROUP BY priority`, args, res.PriorityCounts)
+	priorityRows, err := r.q.CountTicketsByPriority(ctx, sqlc.CountTicketsByPriorityParams{Year: y, Month: m})
+	if err != nil {
+		return res, err
+	}
+	for _, row := range priorityRows {
+		res.PriorityCounts[string(row.Priority)] = int(row.Count)
+	}
+
+	// Reopen rate: a reopened ticket was completed at least once, whatever its status now
+	reopens, err := r.q.CountReopens(ctx, sqlc.CountReopensParams{Year: y, Month: m})
+	if err != nil {
+		return res, err
+	}
+	res.ReopenedCount = int(reopens.Reopened)
+	if reopens.Resolved > 0 {
+		rate := float64(reopens.Reopened) / float64(reopens.Resolved) * 100
+		res.ReopenRate = &rate
+	}
 
 	// Issue Report counts breakdown
-	issueReportDateFilter := ""
-	issueReportArgs := []interface{}{}
-	if year != nil {
-		if month != nil {
-			// Both month and year filtering
-			issueReportDateFilter = " AND EXTRACT(MONTH FROM created_at) = $1 AND EXTRACT(YEAR FROM created_at) = $2"
-			issueReportArgs = []interface{}{*month, *year}
-		} else {
-			// Year-only filtering
-			issueReportDateFilter = " AND EXTRACT(YEAR FROM created_at) = $1"
-			issueReportArgs = []interface{}{*year}
-		}
+	issueRows, err := r.q.CountIssueReportsByClassification(ctx, sqlc.CountIssueReportsByClassificationParams{Year: y, Month: m})
+	if err != nil {
+		return res, err
+	}
+	for _, row := range issueRows {
+		res.IssueReportCounts[row.Classification] = int(row.Count)
 	}
-	
-	r.countIntoWithDateFilter(ctx, `
-		SELECT 
-			CASE 
-				WHEN status = 'canceled' THEN 'Rejected'
-				WHEN resolved_type IS NULL THEN 'Unclassified'
-				WHEN resolved_type = 'DATA_CORRECTION' THEN 'Data Correction'
-				WHEN resolved_type = 'EMERGENCY_CHANGE' THEN 'Emergency Change'
-				ELSE 'Other'
-			END as classification,
-			COUNT(*)
-		FROM tickets
-		WHERE initial_type = 'ISSUE_REPORT'`+issueReportDateFilter+`
-		GROUP BY 
-			CASE 
-				WHEN status = 'canceled' THEN 'Rejected'
-				WHEN resolved_type IS NULL THEN 'Unclassified'
-				WHEN resolved_type = 'DATA_CORRECTION' THEN 'Data Correction'
-				WHEN resolved_type = 'EMERGENCY_CHANGE' THEN 'Emergency Change'
-				ELSE 'Other'
-			END`, issueReportArgs, res.IssueReportCounts)
 
 	return res, nil
 }
 
-func (r *MetricsRepo) countInto(ctx context.Context, sql string, target map[string]int) {
-	r.countIntoWithDateFilter(ctx, sql, nil, target)
+// SLAMonthlyCompliance summarizes SLA outcomes for tickets created in one month.
+// Compliance values are nil when no ticket in the month has met or breached yet.
+type SLAMonthlyCompliance struct {
+	Year                 int      `json:"year"`
+	Month                int      `json:"month"`
+	Total                int      `json:"total"`
+	ResponseMet          int      `json:"responseMet"`
+	ResponseBreached     int      `json:"responseBreached"`
+	ResolutionMet        int      `json:"resolutionMet"`
+	ResolutionBreached   int      `json:"resolutionBreached"`
+	ResponseCompliance   *float64 `json:"responseCompliance"`
+	ResolutionCompliance *float64 `json:"resolutionCompliance"`
 }
 
-func (r *MetricsRepo) countIntoWithDateFilter(ctx context.Context, sql string, args []interface{}, target map[string]int) {
-	rows, err := r.pool.Query(ctx, sql, args...)
-	if err != nil { return }
-	for rows.Next() {
-		var key string
-		var cnt int
-		rows.Scan(&key, &cnt)
-		target[key] = cnt
+// SLACompliance returns per-month SLA compliance for a year, optionally narrowed to one month.
+// Canceled tickets and tickets without SLA deadlines are excluded.
+func (r *MetricsRepo) SLACompliance(ctx context.Context, year int, month *int) ([]SLAMonthlyCompliance, error) {
+	rows, err := r.q.GetSLACompliance(ctx, sqlc.GetSLAComplianceParams{Year: int32(year), Month: optInt32(month)})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SLAMonthlyCompliance{}
+	for _, row := range rows {
+		m := SLAMonthlyCompliance{
+			Year:               int(row.Year),
+			Month:              int(row.Month),
+			Total:              int(row.Total),
+			ResponseMet:        int(row.ResponseMet),
+			ResponseBreached:   int(row.ResponseBreached),
+			ResolutionMet:      int(row.ResolutionMet),
+			ResolutionBreached: int(row.ResolutionBreached),
+		}
+		m.ResponseCompliance = compliancePercent(m.ResponseMet, m.ResponseBreached)
+		m.ResolutionCompliance = compliancePercent(m.ResolutionMet, m.ResolutionBreached)
+		res = append(res, m)
 	}
-	rows.Close()
+	return res, nil
+}
+
+func compliancePercent(met, breached int) *float64 {
+	if met+breached == 0 {
+		return nil
+	}
+	v := float64(met) / float64(met+breached) * 100
+	return &v
 }
 
 type UserPerformanceStats struct {
@@ -219,49 +210,18 @@ type UserPerformanceStats struct {
 
 func (r *MetricsRepo) GetUserPerformanceStats(ctx context.Context, userID string) (UserPerformanceStats, error) {
 	var stats UserPerformanceStats
-	
-	// Get user's in progress tickets count
-	err := r.pool.QueryRow(ctx, `
-		SELECT COUNT(DISTINCT t.id)
-		FROM tickets t
-		JOIN ticket_assignments ta ON t.id = ta.ticket_id
-		WHERE ta.assignee_id = $1 AND t.status = 'in_progress'
-	`, userID).Scan(&stats.InProgressCount)
-	if err != nil {
-		return stats, err
-	}
-	
-	// Get user's completed tickets count
-	err = r.pool.QueryRow(ctx, `
-		SELECT COUNT(DISTINCT t.id)
-		FROM tickets t
-		JOIN ticket_assignments ta ON t.id = ta.ticket_id
-		WHERE ta.assignee_id = $1 AND t.status = 'completed'
-	`, userID).Scan(&stats.CompletedCount)
-	if err != nil {
-		return stats, err
-	}
-	
-	// Get total system in progress tickets count
-	err = r.pool.QueryRow(ctx, `
-		SELECT COUNT(*)
-		FROM tickets
-		WHERE status = 'in_progress'
-	`).Scan(&stats.TotalSystemInProgress)
-	if err != nil {
-		return stats, err
-	}
-	
-	// Get total system completed tickets count
-	err = r.pool.QueryRow(ctx, `
-		SELECT COUNT(*)
-		FROM tickets
-		WHERE status = 'completed'
-	`).Scan(&stats.TotalSystemCompleted)
+
+	counts, err := r.q.GetUserPerformanceCounts(ctx, userID)
 	if err != nil {
 		return stats, err
 	}
-	
+	stats.InProgressCount = int(counts.InProgressCount)
+	stats.CompletedCount = int(counts.CompletedCount)
+	stats.TotalSystemInProgress = int(counts.TotalSystemInProgress)
+	stats.TotalSystemCompleted = int(counts.TotalSystemCompleted)
+	stats.EffortScoreCurrentMonth = int(counts.EffortScoreCurrentMonth)
+	stats.EffortScorePreviousMonth = int(counts.EffortScorePreviousMonth)
+
 	// Calculate participation rates
 	if stats.TotalSystemInProgress > 0 {
 		stats.ParticipationRateInProgress = float64(stats.InProgressCount) / float64(stats.TotalSystemInProgress) * 100
@@ -269,36 +229,10 @@ func (r *MetricsRepo) GetUserPerformanceStats(ctx context.Context, userID string
 	if stats.TotalSystemCompleted > 0 {
 		stats.ParticipationRateCompleted = float64(stats.CompletedCount) / float64(stats.TotalSystemCompleted) * 100
 	}
-	
-	// Get current month effort score (completed tickets only)
-	err = r.pool.QueryRow(ctx, `
-		SELECT COALESCE(SUM(t.effort_score), 0)
-		FROM tickets t
-		JOIN ticket_assignments ta ON t.id = ta.ticket_id
-		WHERE ta.assignee_id = $1 
-		AND t.status = 'completed'
-		AND DATE_TRUNC('month', t.updated_at) = DATE_TRUNC('month', CURRENT_DATE)
-	`, userID).Scan(&stats.EffortScoreCurrentMonth)
-	if err != nil {
-		return stats, err
-	}
-	
-	// Get previous month effort score (completed tickets only)
-	err = r.pool.QueryRow(ctx, `
-		SELECT COALESCE(SUM(t.effort_score), 0)
-		FROM tickets t
-		JOIN ticket_assignments ta ON t.id = ta.ticket_id
-		WHERE ta.assignee_id = $1 
-		AND t.status = 'completed'
-		AND DATE_TRUNC('month', t.updated_at) = DATE_TRUNC('month', CURRENT_DATE - INTERVAL '1 month')
-	`, userID).Scan(&stats.EffortScorePreviousMonth)
-	if err != nil {
-		return stats, err
-	}
-	
+
 	// Calculate growth rate
 	if stats.EffortScorePreviousMonth > 0 {
-		stats.EffortScoreGrowthRate = (float64(stats.EffortScoreCurrentMonth - stats.EffortScorePreviousMonth) / float64(stats.EffortScorePreviousMonth)) * 100
+		stats.EffortScoreGrowthRate = (float64(stats.EffortScoreCurrentMonth-stats.EffortScorePreviousMonth) / float64(stats.EffortScorePreviousMonth)) * 100
 	} else if stats.EffortScoreCurrentMonth > 0 {
 		// If previous month was 0 but current month has score, it's 100% growth
 		stats.EffortScoreGrowthRate = 100.0
@@ -306,6 +240,6 @@ func (r *MetricsRepo) GetUserPerformanceStats(ctx context.Context, userID string
 		// Both months are 0, no growth
 		stats.EffortScoreGrowthRate = 0.0
 	}
-	
+
 	return stats, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// NotificationRepo is the email outbox; it implements notifications.Outbox
+type NotificationRepo struct{ q *sqlc.Queries }
+
+// Enqueue stores a message for the worker to send, not before sendAfter when
+// set. Inside WithTx it is only sent if the transaction commits.
+func (r *NotificationRepo) Enqueue(ctx context.Context, recipientID, ticketID *string, kind notifications.Kind, m notifications.Message, sendAfter *time.Time) error {
+	return r.q.EnqueueNotification(ctx, sqlc.EnqueueNotificationParams{
+		RecipientID: recipientID,
+		ToAddress:   m.To,
+		Kind:        string(kind),
+		TicketID:    ticketID,
+		Subject:     m.Subject,
+		BodyText:    m.Text,
+		BodyHtml:    m.HTML,
+		SendAfter:   sendAfter,
+	})
+}
+
+func (r *NotificationRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]notifications.Queued, error) {
+	rows, err := r.q.ClaimNotifications(ctx, sqlc.ClaimNotificationsParams{LeaseSeconds: int32(lease.Seconds()), BatchSize: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	queued := make([]notifications.Queued, len(rows))
+	for i, row := range rows {
+		queued[i] = notifications.Queued{
+			ID:       row.ID,
+			Attempts: int(row.Attempts),
+			Message:  notifications.Message{To: row.ToAddress, Subject: row.Subject, Text: row.BodyText, HTML: row.BodyHtml},
+		}
+	}
+	return queued, nil
+}
+
+func (r *NotificationRepo) MarkSent(ctx context.Context, id string) error {
+	return r.q.MarkNotificationSent(ctx, id)
+}
+
+func (r *NotificationRepo) MarkFailed(ctx context.Context, id string, err string, retryAt *time.Time) error {
+	return r.q.MarkNotificationFailed(ctx, sqlc.MarkNotificationFailedParams{LastError: err, RetryAt: retryAt, ID: id})
+}
+
+// Preferences returns a user's notification settings, or the defaults when
+// they have not saved any
+func (r *NotificationRepo) Preferences(ctx context.Context, userID string) (notifications.Preferences, error) {
+	row, err := r.q.GetNotificationPreferences(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notifications.DefaultPreferences(), nil
+	}
+	if err != nil {
+		return notifications.Preferences{}, err
+	}
+	return preferences(row.Channel, row.EventTypes, row.Delivery, row.QuietHoursStart, row.QuietHoursEnd), nil
+}
+
+func (r *NotificationRepo) SavePreferences(ctx context.Context, userID string, p notifications.Preferences) error {
+	eventTypes := make([]string, len(p.EventTypes))
+	for i, k := range p.EventTypes {
+		eventTypes[i] = string(k)
+	}
+	return r.q.UpsertNotificationPreferences(ctx, sqlc.UpsertNotificationPreferencesParams{
+		UserID:          userID,
+		Channel:         string(p.Channel),
+		EventTypes:      eventTypes,
+		Delivery:        string(p.Delivery),
+		QuietHoursStart: p.QuietHoursStart,
+		QuietHoursEnd:   p.QuietHoursEnd,
+	})
+}
+
+// DigestRecipient is a user on daily digests
+type DigestRecipient struct {
+	UserID      string
+	Name        string
+	Email       string
+	Preferences notifications.Preferences
+	// LastDigestAt is when the last digest ended, or when digests began
+	LastDigestAt *time.Time
+}
+
+// DigestRecipients lists the digest users whose digest for due is unsent
+func (r *NotificationRepo) DigestRecipients(ctx context.Context, due time.Time) ([]DigestRecipient, error) {
+	rows, err := r.q.ListDigestRecipients(ctx, due)
+	if err != nil {
+		return nil, err
+	}
+	recipients := make([]DigestRecipient, len(rows))
+	for i, row := range rows {
+		recipients[i] = DigestRecipient{
+			UserID:       row.UserID,
+			Name:         row.Name,
+			Email:        row.Email,
+			Preferences:  preferences(row.Channel, row.EventTypes, row.Delivery, row.QuietHoursStart, row.QuietHoursEnd),
+			LastDigestAt: row.LastDigestAt,
+		}
+	}
+	return recipients, nil
+}
+
+// ClaimDigest marks a user's digest for due as sent. It reports false when
+// it already was, e.g. by another replica.
+func (r *NotificationRepo) ClaimDigest(ctx context.Context, userID string, due time.Time) (bool, error) {
+	n, err := r.q.ClaimDigest(ctx, sqlc.ClaimDigestParams{Due: due, UserID: userID})
+	return n > 0, err
+}
+
+// DigestActivity returns the activity for a user's digest in [since, until),
+// oldest first. Ticket URLs are left to the caller.
+func (r *NotificationRepo) DigestActivity(ctx context.Context, userID string, since, until time.Time) ([]notifications.DigestItem, error) {
+	rows, err := r.q.ListDigestActivity(ctx, sqlc.ListDigestActivityParams{UserID: userID, Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]notifications.DigestItem, len(rows))
+	for i, row := range rows {
+		items[i] = notifications.DigestItem{
+			Kind:        notifications.Kind(row.Kind),
+			TicketID:    row.TicketID,
+			TicketCode:  row.Code,
+			TicketTitle: row.Title,
+			ActorName:   row.ActorName,
+			At:          row.CreatedAt,
+		}
+		switch items[i].Kind {
+		case notifications.KindStatusChanged:
+			items[i].ToStatus = models.TicketStatus(row.Detail)
+		case notifications.KindComment, notifications.KindMention:
+			items[i].Comment = row.Detail
+		}
+	}
+	return items, nil
+}
+
+func preferences(channel string, eventTypes []string, delivery string, quietStart, quietEnd *string) notifications.Preferences {
+	p := notifications.Preferences{
+		Channel:         notifications.Channel(channel),
+		EventTypes:      []notifications.Kind{},
+		Delivery:        notifications.Delivery(delivery),
+		QuietHoursStart: quietStart,
+		QuietHoursEnd:   quietEnd,
+	}
+	for _, k := range eventTypes {
+		p.EventTypes = append(p.EventTypes, notifications.Kind(k))
+	}
+	return p
+}
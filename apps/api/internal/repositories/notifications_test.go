@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/notifications"
+)
+
+func TestNotificationRepo_PreferencesDefault(t *testing.T) {
+	db := newRecordDB()
+	db.rows["GetNotificationPreferences"] = nil
+	prefs, err := newRepo(db).Notifications.Preferences(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, notifications.DefaultPreferences(), prefs)
+}
+
+func TestNotificationRepo_SavePreferences(t *testing.T) {
+	db := newRecordDB()
+	start, end := "22:00", "07:00"
+	err := newRepo(db).Notifications.SavePreferences(context.Background(), "user-1", notifications.Preferences{
+		Channel:         notifications.ChannelEmail,
+		EventTypes:      []notifications.Kind{notifications.KindAssigned, notifications.KindMention},
+		Delivery:        notifications.DeliveryDigest,
+		QuietHoursStart: &start,
+		QuietHoursEnd:   &end,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"user-1", "email", []string{"assigned", "mention"}, "digest", &start, &end}, db.args["UpsertNotificationPreferences"])
+}
+
+func TestNotificationRepo_DigestActivity(t *testing.T) {
+	since := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	at := since.Add(time.Hour)
+	db := newRecordDB()
+	db.rows["ListDigestActivity"] = [][]any{
+		{"status_changed", "ticket-0000", int32(1), "title", "actor", "completed", at},
+		{"comment", "ticket-0000", int32(1), "title", "actor", "a comment", at},
+		{"mention", "ticket-0000", int32(1), "title", "actor", "@user-1 a mention", at},
+	}
+	items, err := newRepo(db).Notifications.DigestActivity(context.Background(), "user-1", since, since.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, notifications.KindStatusChanged, items[0].Kind)
+	assert.Equal(t, models.StatusCompleted, items[0].ToStatus)
+	assert.Empty(t, items[0].Comment)
+	assert.Equal(t, "a comment", items[1].Comment)
+	assert.Equal(t, "ticket-0000", items[1].TicketID)
+	assert.Equal(t, notifications.KindMention, items[2].Kind)
+	assert.Equal(t, "@user-1 a mention", items[2].Comment)
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// PasswordResetRepo stores password reset tokens. Like refresh tokens, the
+// raw token only goes to the user; the table keeps its hash.
+type PasswordResetRepo struct{ q *sqlc.Queries }
+
+// Create replaces a user's reset token with a new one valid for ttl and
+// returns it
+func (r *PasswordResetRepo) Create(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	if err := r.q.ClearPasswordResets(ctx, userID); err != nil {
+		return "", err
+	}
+	token := newToken()
+	err := r.q.CreatePasswordReset(ctx, sqlc.CreatePasswordResetParams{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Use consumes a reset token and returns the user it was issued to. It
+// returns ErrNotFound for an unknown, already used or expired token.
+func (r *PasswordResetRepo) Use(ctx context.Context, token string) (string, error) {
+	userID, err := r.q.UsePasswordReset(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetRepo_CreateReplacesEarlierToken(t *testing.T) {
+	db := newRecordDB()
+	token, err := newRepo(db).PasswordResets.Create(context.Background(), "user-1", time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"user-1"}, db.args["ClearPasswordResets"])
+	args := db.args["CreatePasswordReset"]
+	require.Len(t, args, 3)
+	assert.Equal(t, "user-1", args[0])
+	assert.Equal(t, hashToken(token), args[1])
+	assert.NotContains(t, args, token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), args[2].(time.Time), time.Minute)
+}
+
+func TestPasswordResetRepo_UseUnknownToken(t *testing.T) {
+	db := newRecordDB()
+	db.rows["UsePasswordReset"] = nil
+	_, err := newRepo(db).PasswordResets.Use(context.Background(), "used-token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, []any{hashToken("used-token")}, db.args["UsePasswordReset"])
+}
+
+func TestUserRepo_SetPasswordSignsOutOtherSessions(t *testing.T) {
+	db := newRecordDB()
+	db.tags["UpdateUserPassword"] = "UPDATE 1"
+	current := "session-1"
+	require.NoError(t, newRepo(db).Users.SetPassword(context.Background(), "user-1", "hash", &current))
+
+	assert.Equal(t, []any{"hash", "user-1"}, db.args["UpdateUserPassword"])
+	assert.Equal(t, []any{"user-1"}, db.args["ClearPasswordResets"])
+	assert.Equal(t, []any{"user-1", &current}, db.args["RevokeUserSessions"])
+}
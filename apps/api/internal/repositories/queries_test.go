@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seededDB serves an in-memory dataset of in-progress tickets with two
+// assignees each for the ticket list and metrics queries. Only the columns
+// the tests look at are filled in; everything else scans as its zero value.
+type seededDB struct {
+	*recordDB
+	tickets   []string
+	assignees map[string][]string
+}
+
+func newSeededDB(n int) *seededDB {
+	db := &seededDB{recordDB: newRecordDB(), assignees: map[string][]string{}}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("ticket-%04d", i)
+		db.tickets = append(db.tickets, id)
+		db.assignees[id] = []string{fmt.Sprintf("user-%04d-a", i), fmt.Sprintf("user-%04d-b", i)}
+	}
+	return db
+}
+
+func (d *seededDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows := &fakeRows{}
+	switch d.record(sql, args) {
+	case "ListTickets":
+		offset, limit := int(args[len(args)-2].(int32)), int(args[len(args)-1].(int32))
+		for i := offset; i < len(d.tickets) && i < offset+limit; i++ {
+			rows.values = append(rows.values, []any{d.tickets[i]})
+		}
+	case "ListInProgressTickets":
+		for _, id := range d.tickets {
+			rows.values = append(rows.values, []any{id})
+		}
+	case "ListAssigneesByTicket":
+		for _, ticketID := range args[0].([]string) {
+			for _, userID := range d.assignees[ticketID] {
+				rows.values = append(rows.values, []any{ticketID, userID, "name of " + userID})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (d *seededDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if queryName(sql) == "CountTickets" {
+		d.record(sql, args)
+		return &fakeRows{values: [][]any{{int64(len(d.tickets))}}}
+	}
+	return d.recordDB.QueryRow(ctx, sql, args...)
+}
+
+func TestTicketRepo_ListBatchesAssignees(t *testing.T) {
+	ctx := context.Background()
+	for _, size := range []int{1, 10, 100} {
+		t.Run(fmt.Sprintf("page of %d", size), func(t *testing.T) {
+			db := newSeededDB(200)
+			items, total, err := newRepo(db).Tickets.List(ctx, TicketFilters{}, 0, size)
+			require.NoError(t, err)
+			assert.Equal(t, int64(200), total)
+			require.Len(t, items, size)
+			for _, item := range items {
+				require.Len(t, item.Assignees, 2)
+				assert.Equal(t, db.assignees[item.ID][0], item.Assignees[0].ID)
+			}
+			// tickets, their assignees and the total, whatever the page size
+			assert.Equal(t, 3, db.queries)
+		})
+	}
+}
+
+func TestMetricsRepo_SummaryBatchesAssignees(t *testing.T) {
+	ctx := context.Background()
+	var counts []int
+	for _, size := range []int{1, 20} {
+		db := newSeededDB(size)
+		res, err := newRepo(db).Metrics.Summary(ctx)
+		require.NoError(t, err)
+		require.Len(t, res.InProgressToday, size)
+		for _, ticket := range res.InProgressToday {
+			assert.Len(t, ticket.Assignees, 2)
+		}
+		counts = append(counts, db.queries)
+	}
+	assert.Equal(t, counts[0], counts[1])
+}
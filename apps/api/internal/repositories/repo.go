@@ -1,21 +1,138 @@
 package repositories
 
-import "github.com/jackc/pgx/v5/pgxpool"
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// DBTX is implemented by both *pgxpool.Pool and pgx.Tx, so every repository
+// can run either directly on the pool or inside a transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
 
 type Repo struct {
-	Users      *UserRepo
-	Tickets    *TicketRepo
-	Audits     *AuditRepo
-	Metrics    *MetricsRepo
-	UserScores *UserScoresRepo
+	db             DBTX
+	Users          *UserRepo
+	Tickets        *TicketRepo
+	Audits         *AuditRepo
+	Metrics        *MetricsRepo
+	UserScores     *UserScoresRepo
+	SLA            *SLARepo
+	Calendar       *CalendarRepo
+	Search         *SearchRepo
+	SavedViews     *SavedViewRepo
+	Events         *EventRepo
+	Notifications  *NotificationRepo
+	Webhooks       *WebhookRepo
+	InboundEmails  *InboundEmailRepo
+	Mentions       *MentionRepo
+	Watchers       *WatcherRepo
+	Sessions       *SessionRepo
+	Invitations    *InvitationRepo
+	PasswordResets *PasswordResetRepo
 }
 
 func New(pool *pgxpool.Pool) *Repo {
+	return newRepo(pool)
+}
+
+// newRepo builds every repository on the sqlc queries generated from
+// db/queries; run `make sqlc` after changing a query or the schema.
+func newRepo(db DBTX) *Repo {
+	q := sqlc.New(db)
 	return &Repo{
-		Users:      &UserRepo{pool: pool},
-		Tickets:    &TicketRepo{pool: pool},
-		Audits:     &AuditRepo{pool: pool},
-		Metrics:    &MetricsRepo{pool: pool},
-		UserScores: &UserScoresRepo{pool: pool},
+		db:             db,
+		Users:          &UserRepo{q: q},
+		Tickets:        &TicketRepo{q: q},
+		Audits:         &AuditRepo{q: q},
+		Metrics:        &MetricsRepo{q: q},
+		UserScores:     &UserScoresRepo{q: q},
+		SLA:            &SLARepo{q: q},
+		Calendar:       &CalendarRepo{q: q},
+		Search:         &SearchRepo{q: q},
+		SavedViews:     &SavedViewRepo{q: q},
+		Events:         &EventRepo{q: q},
+		Notifications:  &NotificationRepo{q: q},
+		Webhooks:       &WebhookRepo{q: q},
+		InboundEmails:  &InboundEmailRepo{q: q},
+		Mentions:       &MentionRepo{q: q},
+		Watchers:       &WatcherRepo{q: q},
+		Sessions:       &SessionRepo{q: q},
+		Invitations:    &InvitationRepo{q: q},
+		PasswordResets: &PasswordResetRepo{q: q},
 	}
-}
\ No newline at end of file
+}
+
+// WithTx runs fn with a Repo whose repositories all share one transaction.
+// The transaction commits if fn returns nil and rolls back otherwise; calling
+// WithTx on a transactional Repo nests through a savepoint. A transaction is a
+// single connection, so fn must close rows before issuing the next query.
+func (r *Repo) WithTx(ctx context.Context, fn func(tx *Repo) error) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(newRepo(tx))
+	})
+}
+
+// isUUID reports whether id is a UUID in its canonical hyphenated form. Ids
+// from request paths are checked before they reach a uuid column, where a
+// malformed one is a query error rather than no rows.
+func isUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, c := range id {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'):
+			return false
+		}
+	}
+	return true
+}
+
+// optString turns an empty filter value into a NULL query parameter
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optInt16(v *int32) *int16 {
+	if v == nil {
+		return nil
+	}
+	n := int16(*v)
+	return &n
+}
+
+func optInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
+
+// texts converts enum values for a text[] parameter; never nil, since a NULL
+// array would not read as empty
+func texts[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
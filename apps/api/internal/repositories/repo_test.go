@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTx records the statements it executes and how the transaction ended.
+// Methods the tests do not use are left to the embedded nil interface.
+type fakeTx struct {
+	pgx.Tx
+	execs      int
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.execs++
+	return pgconn.CommandTag{}, nil
+}
+func (t *fakeTx) Commit(ctx context.Context) error   { t.committed = true; return nil }
+func (t *fakeTx) Rollback(ctx context.Context) error { t.rolledBack = true; return nil }
+
+// fakeDB hands out a single fakeTx
+type fakeDB struct {
+	DBTX
+	tx *fakeTx
+}
+
+func (d *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) { return d.tx, nil }
+
+// recordDB records the arguments of the last call to each query and answers
+// with the results a test put in rows and tags, by query name. A QueryRow
+// without an entry in rows returns one row of zero values, and an entry with
+// no rows is pgx.ErrNoRows; an Exec without a tag affects no rows.
+type recordDB struct {
+	DBTX
+	queries int
+	args    map[string][]any
+	rows    map[string][][]any
+	tags    map[string]string
+}
+
+func newRecordDB() *recordDB {
+	return &recordDB{args: map[string][]any{}, rows: map[string][][]any{}, tags: map[string]string{}}
+}
+
+// queryName extracts the sqlc query name from its "-- name: X :kind" header
+func queryName(sql string) string {
+	if f := strings.Fields(sql); len(f) > 2 && f[1] == "name:" {
+		return f[2]
+	}
+	return ""
+}
+
+func (d *recordDB) record(sql string, args []any) string {
+	d.queries++
+	name := queryName(sql)
+	d.args[name] = args
+	return name
+}
+
+func (d *recordDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return &fakeRows{values: d.rows[d.record(sql, args)]}, nil
+}
+
+func (d *recordDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag(d.tags[d.record(sql, args)]), nil
+}
+
+func (d *recordDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if rows, ok := d.rows[d.record(sql, args)]; ok {
+		return &fakeRows{values: rows}
+	}
+	return &fakeRows{values: [][]any{{}}}
+}
+
+// fakeRows implements pgx.Rows and pgx.Row over literal values
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	cur    []any
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.values) == 0 {
+		return false
+	}
+	r.cur, r.values = r.values[0], r.values[1:]
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.cur == nil && !r.Next() {
+		return pgx.ErrNoRows
+	}
+	for i, v := range r.cur {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+func TestRepo_WithTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits and shares the transaction", func(t *testing.T) {
+		db := &fakeDB{tx: &fakeTx{}}
+		err := newRepo(db).WithTx(ctx, func(tx *Repo) error {
+			assert.Same(t, db.tx, tx.db)
+			// Every repository issues its statements on the transaction
+			assert.NoError(t, tx.Tickets.Assign(ctx, "ticket", nil))
+			assert.NoError(t, tx.Audits.Insert(ctx, "ticket", nil, "test", nil, nil))
+			assert.NoError(t, tx.UserScores.RemoveAllPointsForTicket(ctx, "ticket"))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, db.tx.execs)
+		assert.True(t, db.tx.committed)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		db := &fakeDB{tx: &fakeTx{}}
+		boom := errors.New("boom")
+		err := newRepo(db).WithTx(ctx, func(tx *Repo) error { return boom })
+		assert.ErrorIs(t, err, boom)
+		assert.False(t, db.tx.committed)
+		assert.True(t, db.tx.rolledBack)
+	})
+}
+
+func TestIsUUID(t *testing.T) {
+	assert.True(t, isUUID("0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a6b"))
+	assert.True(t, isUUID("0B6E3B5C-6D43-4F0E-9F0A-3C2D1E4F5A6B"))
+	for _, id := range []string{"", "42", "not-a-uuid", "0b6e3b5c6d434f0e9f0a3c2d1e4f5a6b", "0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a6g", "{0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a}"} {
+		assert.False(t, isUUID(id), id)
+	}
+}
+
+func TestMalformedIDsAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	db := newRecordDB()
+	r := newRepo(db)
+
+	_, err := r.Invitations.Get(ctx, "42")
+	assert.ErrorIs(t, err, ErrNotFound)
+	revoked, err := r.Invitations.Revoke(ctx, "42")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = r.Sessions.Revoke(ctx, "42", "user-1")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	_, err = r.Webhooks.GetByID(ctx, "42")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, r.Webhooks.Delete(ctx, "42"), ErrNotFound)
+	_, err = r.Webhooks.Redeliver(ctx, "0b6e3b5c-6d43-4f0e-9f0a-3c2d1e4f5a6b", "42")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = r.SavedViews.GetByID(ctx, "42")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, r.SavedViews.Delete(ctx, "42", "user-1"), ErrNotFound)
+	assert.ErrorIs(t, r.SLA.DeletePolicy(ctx, "42"), ErrNotFound)
+	_, err = r.Users.GetByID(ctx, "42")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// None of them reached the database
+	assert.Zero(t, db.queries)
+}
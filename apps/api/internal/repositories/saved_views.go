@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+type SavedViewRepo struct{ q *sqlc.Queries }
+
+// SavedView is a named ticket list: its filters, sort and the columns the UI
+// shows. Its owner can share it with every user of one role.
+type SavedView struct {
+	ID         string        `json:"id"`
+	OwnerID    string        `json:"ownerId"`
+	Name       string        `json:"name"`
+	Filters    TicketFilters `json:"filters"`
+	Sort       string        `json:"sort"`
+	Columns    []string      `json:"columns"`
+	SharedRole *models.Role  `json:"sharedRole"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// TicketFilters returns the view's filters together with its sort
+func (v SavedView) TicketFilters() TicketFilters {
+	f := v.Filters
+	f.Sort = v.Sort
+	return f
+}
+
+// VisibleTo reports whether a user may list and apply the view
+func (v SavedView) VisibleTo(userID string, role models.Role) bool {
+	return v.OwnerID == userID || (v.SharedRole != nil && *v.SharedRole == role)
+}
+
+// SavedViewCount is the number of tickets a view currently matches
+type SavedViewCount struct {
+	ID    string `json:"id"`
+	Count int64  `json:"count"`
+}
+
+// List returns the views a user owns and those shared with their role, by name
+func (r *SavedViewRepo) List(ctx context.Context, userID string, role models.Role) ([]SavedView, error) {
+	rows, err := r.q.ListSavedViews(ctx, sqlc.ListSavedViewsParams{UserID: userID, Role: role})
+	if err != nil {
+		return nil, err
+	}
+	views := []SavedView{}
+	for _, row := range rows {
+		views = append(views, savedViewFromRow(row))
+	}
+	return views, nil
+}
+
+func (r *SavedViewRepo) GetByID(ctx context.Context, id string) (SavedView, error) {
+	if !isUUID(id) {
+		return SavedView{}, ErrNotFound
+	}
+	row, err := r.q.GetSavedView(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SavedView{}, ErrNotFound
+	}
+	if err != nil {
+		return SavedView{}, err
+	}
+	return savedViewFromRow(row), nil
+}
+
+func (r *SavedViewRepo) Create(ctx context.Context, v *SavedView) error {
+	filters, _ := json.Marshal(v.Filters)
+	row, err := r.q.CreateSavedView(ctx, sqlc.CreateSavedViewParams{
+		OwnerID:    v.OwnerID,
+		Name:       v.Name,
+		Filters:    filters,
+		Sort:       v.Sort,
+		Columns:    columns(v.Columns),
+		SharedRole: v.SharedRole,
+	})
+	if err != nil {
+		return err
+	}
+	*v = savedViewFromRow(row)
+	return nil
+}
+
+// Update saves every field of v; it returns ErrNotFound unless the view
+// exists and belongs to v.OwnerID.
+func (r *SavedViewRepo) Update(ctx context.Context, v *SavedView) error {
+	filters, _ := json.Marshal(v.Filters)
+	row, err := r.q.UpdateSavedView(ctx, sqlc.UpdateSavedViewParams{
+		Name:       v.Name,
+		Filters:    filters,
+		Sort:       v.Sort,
+		Columns:    columns(v.Columns),
+		SharedRole: v.SharedRole,
+		ID:         v.ID,
+		OwnerID:    v.OwnerID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*v = savedViewFromRow(row)
+	return nil
+}
+
+// Delete removes a view owned by ownerID, or returns ErrNotFound
+func (r *SavedViewRepo) Delete(ctx context.Context, id, ownerID string) error {
+	if !isUUID(id) {
+		return ErrNotFound
+	}
+	n, err := r.q.DeleteSavedView(ctx, sqlc.DeleteSavedViewParams{ID: id, OwnerID: ownerID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Counts returns how many tickets each view matches for viewerID, in the
+// order given
+func (r *SavedViewRepo) Counts(ctx context.Context, viewerID string, views []SavedView) ([]SavedViewCount, error) {
+	tickets := &TicketRepo{q: r.q}
+	counts := []SavedViewCount{}
+	for _, v := range views {
+		f := v.Filters
+		f.Watcher = viewerID
+		n, err := tickets.Count(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, SavedViewCount{ID: v.ID, Count: n})
+	}
+	return counts, nil
+}
+
+func savedViewFromRow(row sqlc.SavedView) SavedView {
+	v := SavedView{
+		ID:         row.ID,
+		OwnerID:    row.OwnerID,
+		Name:       row.Name,
+		Sort:       row.Sort,
+		Columns:    columns(row.Columns),
+		SharedRole: row.SharedRole,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+	json.Unmarshal(row.Filters, &v.Filters)
+	return v
+}
+
+// columns never returns nil: the column is NOT NULL
+func columns(c []string) []string {
+	if c == nil {
+		return []string{}
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+func TestTicketFilters_JSON(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	redFlag, effort := true, 3
+	f := TicketFilters{
+		Statuses:    []models.TicketStatus{models.StatusPending, models.StatusOnHold},
+		Unassigned:  true,
+		RedFlag:     &redFlag,
+		CreatedFrom: &from,
+		EffortMin:   &effort,
+		Query:       "vpn",
+		Sort:        "-createdAt",
+	}
+	b, err := json.Marshal(f)
+	require.NoError(t, err)
+	// Named like the query parameters; the sort is stored apart
+	assert.JSONEq(t, `{"status":["pending","on_hold"],"unassigned":true,"redFlag":true,
+		"createdFrom":"2025-03-01T00:00:00Z","effortMin":3,"q":"vpn"}`, string(b))
+
+	var back TicketFilters
+	require.NoError(t, json.Unmarshal(b, &back))
+	f.Sort = ""
+	assert.Equal(t, f, back)
+}
+
+func TestTicketFilters_Validate(t *testing.T) {
+	negative := -1
+	assert.NoError(t, TicketFilters{Statuses: []models.TicketStatus{models.StatusPending}, Sort: "-dueAt"}.Validate())
+	assert.EqualError(t, TicketFilters{Statuses: []models.TicketStatus{"lost"}}.Validate(), `invalid status "lost"`)
+	assert.EqualError(t, TicketFilters{Priorities: []models.TicketPriority{"P9"}}.Validate(), `invalid priority "P9"`)
+	assert.EqualError(t, TicketFilters{EffortMax: &negative}.Validate(), "invalid effortMax -1")
+	assert.EqualError(t, TicketFilters{Sort: "title"}.Validate(), `invalid sort "title"`)
+}
+
+func TestTicketFilters_Merge(t *testing.T) {
+	redFlag := true
+	view := TicketFilters{
+		Statuses:   []models.TicketStatus{models.StatusPending, models.StatusInProgress},
+		Priorities: []models.TicketPriority{models.PriorityP0},
+		RedFlag:    &redFlag,
+		Sort:       "-updatedAt",
+	}
+
+	assert.Equal(t, view, view.Merge(TicketFilters{}))
+
+	got := view.Merge(TicketFilters{Statuses: []models.TicketStatus{models.StatusOnHold}, Query: "printer", Sort: "code"})
+	assert.Equal(t, TicketFilters{
+		Statuses:   []models.TicketStatus{models.StatusOnHold},
+		Priorities: []models.TicketPriority{models.PriorityP0},
+		RedFlag:    &redFlag,
+		Query:      "printer",
+		Sort:       "code",
+	}, got)
+}
+
+func TestSavedView_VisibleTo(t *testing.T) {
+	supervisor := models.RoleSupervisor
+	private := SavedView{OwnerID: "owner"}
+	shared := SavedView{OwnerID: "owner", SharedRole: &supervisor}
+
+	assert.True(t, private.VisibleTo("owner", models.RoleUser))
+	assert.False(t, private.VisibleTo("other", models.RoleSupervisor))
+	assert.True(t, shared.VisibleTo("other", models.RoleSupervisor))
+	assert.False(t, shared.VisibleTo("other", models.RoleManager))
+}
+
+func TestSavedViewFromRow(t *testing.T) {
+	v := savedViewFromRow(sqlc.SavedView{ID: "v1", Name: "Morning", Filters: []byte(`{"status":["pending"],"unassigned":true}`), Sort: "-createdAt"})
+	assert.Equal(t, []string{}, v.Columns)
+	assert.Equal(t, TicketFilters{Statuses: []models.TicketStatus{"pending"}, Unassigned: true, Sort: "-createdAt"}, v.TicketFilters())
+	assert.Empty(t, v.Filters.Sort)
+}
+
+func TestSavedViewRepo_Counts(t *testing.T) {
+	db := newSeededDB(7)
+	views := []SavedView{
+		{ID: "a", Filters: TicketFilters{Statuses: []models.TicketStatus{models.StatusPending}}},
+		{ID: "b", Filters: TicketFilters{Unassigned: true}},
+	}
+	counts, err := newRepo(db).SavedViews.Counts(context.Background(), "user-1", views)
+	require.NoError(t, err)
+	assert.Equal(t, []SavedViewCount{{ID: "a", Count: 7}, {ID: "b", Count: 7}}, counts)
+	assert.Equal(t, 2, db.queries)
+	// The last count ran with the second view's filters
+	assert.Equal(t, true, db.args["CountTickets"][5])
+	assert.Nil(t, db.args["CountTickets"][17])
+}
+
+func TestSavedViewRepo_CountsWatchingIsPerViewer(t *testing.T) {
+	db := newSeededDB(1)
+	views := []SavedView{{ID: "a", Filters: TicketFilters{Watching: true}}}
+	_, err := newRepo(db).SavedViews.Counts(context.Background(), "user-1", views)
+	require.NoError(t, err)
+	watcher := "user-1"
+	assert.Equal(t, &watcher, db.args["CountTickets"][17])
+}
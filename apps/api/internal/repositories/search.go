@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/search"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// snippetWidth is roughly how many characters of context a match shows
+const snippetWidth = 160
+
+type SearchRepo struct{ q *sqlc.Queries }
+
+type TicketMatch struct {
+	ID        string                `json:"id"`
+	Code      int32                 `json:"code"`
+	Title     string                `json:"title"`
+	Status    models.TicketStatus   `json:"status"`
+	Priority  models.TicketPriority `json:"priority"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+	Score     float64               `json:"score"`
+	// Snippet is HTML-escaped text with matches wrapped in <mark>
+	Snippet string `json:"snippet"`
+}
+
+type CommentMatch struct {
+	ID          string    `json:"id"`
+	TicketID    string    `json:"ticketId"`
+	TicketCode  int32     `json:"ticketCode"`
+	TicketTitle string    `json:"ticketTitle"`
+	AuthorID    *string   `json:"authorId"`
+	AuthorName  *string   `json:"authorName"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Score       float64   `json:"score"`
+	Snippet     string    `json:"snippet"`
+}
+
+type SearchResults struct {
+	Tickets  []TicketMatch  `json:"tickets"`
+	Comments []CommentMatch `json:"comments"`
+}
+
+// Search returns up to limit tickets and limit comments matching every term
+// of q, best match first.
+func (r *SearchRepo) Search(ctx context.Context, q string, limit int) (SearchResults, error) {
+	res := SearchResults{Tickets: []TicketMatch{}, Comments: []CommentMatch{}}
+	query := search.Query(q)
+	if query == "" {
+		return res, nil
+	}
+
+	tickets, err := r.q.SearchTickets(ctx, sqlc.SearchTicketsParams{Query: query, MaxResults: int32(limit)})
+	if err != nil {
+		return res, err
+	}
+	for _, t := range tickets {
+		var details map[string]any
+		json.Unmarshal(t.Details, &details)
+		res.Tickets = append(res.Tickets, TicketMatch{
+			ID:        t.ID,
+			Code:      t.Code,
+			Title:     t.Title,
+			Status:    t.Status,
+			Priority:  t.Priority,
+			UpdatedAt: t.UpdatedAt,
+			Score:     t.Score,
+			Snippet:   bestSnippet(q, t.Description, search.Text(details), t.Title),
+		})
+	}
+
+	comments, err := r.q.SearchComments(ctx, sqlc.SearchCommentsParams{Query: query, MaxResults: int32(limit)})
+	if err != nil {
+		return res, err
+	}
+	for _, c := range comments {
+		res.Comments = append(res.Comments, CommentMatch{
+			ID:          c.ID,
+			TicketID:    c.TicketID,
+			TicketCode:  c.TicketCode,
+			TicketTitle: c.TicketTitle,
+			AuthorID:    c.AuthorID,
+			AuthorName:  c.AuthorName,
+			CreatedAt:   c.CreatedAt,
+			Score:       c.Score,
+			Snippet:     search.Snippet(c.Body, q, snippetWidth),
+		})
+	}
+	return res, nil
+}
+
+// Reindex rebuilds the search document of every ticket and comment, for
+// data written before the index existed.
+func (r *SearchRepo) Reindex(ctx context.Context) (tickets, comments int, err error) {
+	ticketRows, err := r.q.ListTicketsForSearch(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, t := range ticketRows {
+		var details map[string]any
+		json.Unmarshal(t.Details, &details)
+		if err := indexTicket(ctx, r.q, t.ID, t.Title, t.Description, details); err != nil {
+			return tickets, 0, err
+		}
+		tickets++
+	}
+
+	commentRows, err := r.q.ListCommentsForSearch(ctx)
+	if err != nil {
+		return tickets, 0, err
+	}
+	for _, c := range commentRows {
+		if err := indexComment(ctx, r.q, c.ID, c.TicketID, c.Body); err != nil {
+			return tickets, comments, err
+		}
+		comments++
+	}
+	return tickets, comments, nil
+}
+
+// indexTicket writes a ticket's search document; called on every write to
+// its title, description or details.
+func indexTicket(ctx context.Context, q *sqlc.Queries, id, title, description string, details map[string]any) error {
+	return q.UpsertTicketSearch(ctx, sqlc.UpsertTicketSearchParams{
+		TicketID:    id,
+		Title:       lexemes(title),
+		Description: lexemes(description),
+		Details:     lexemes(search.Text(details)),
+	})
+}
+
+func indexComment(ctx context.Context, q *sqlc.Queries, id, ticketID, body string) error {
+	return q.UpsertCommentSearch(ctx, sqlc.UpsertCommentSearchParams{TicketID: ticketID, CommentID: id, Body: lexemes(body)})
+}
+
+// lexemes never returns nil: array_to_tsvector(NULL) would be NULL
+func lexemes(text string) []string {
+	l := search.Document(text)
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
+
+// bestSnippet highlights the first of texts that contains a match, falling
+// back to the start of the first one.
+func bestSnippet(q string, texts ...string) string {
+	for _, text := range texts {
+		if s := search.Snippet(text, q, snippetWidth); strings.Contains(s, "<mark>") {
+			return s
+		}
+	}
+	return search.Snippet(texts[0], q, snippetWidth)
+}
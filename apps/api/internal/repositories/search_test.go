@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestSnippet(t *testing.T) {
+	// The first text with a match wins, otherwise the start of the first
+	assert.Equal(t, "ERP <mark>ล่ม</mark>", bestSnippet("ล่ม", "เข้าระบบไม่ได้", "ERP ล่ม", "ล่ม"))
+	assert.Equal(t, "เข้าระบบไม่ได้", bestSnippet("vpn", "เข้าระบบไม่ได้", "ERP"))
+}
+
+func TestLexemes(t *testing.T) {
+	// array_to_tsvector needs an array, never NULL
+	assert.NotNil(t, lexemes(""))
+	assert.Equal(t, []string{"erp"}, lexemes("ERP"))
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// SessionRepo stores sign-ins and their refresh tokens. Raw refresh tokens
+// are only ever returned to the caller; the table keeps their hashes.
+type SessionRepo struct{ q *sqlc.Queries }
+
+// Session is one signed-in device. Its ID is the jti of the access tokens
+// issued for it.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Create starts a session for userID that can be refreshed for ttl, and
+// returns it with its refresh token. The user's dead sessions are dropped.
+func (r *SessionRepo) Create(ctx context.Context, userID, userAgent, ip string, ttl time.Duration) (Session, string, error) {
+	if err := r.q.PruneUserSessions(ctx, userID); err != nil {
+		return Session{}, "", err
+	}
+	token := newToken()
+	row, err := r.q.CreateSession(ctx, sqlc.CreateSessionParams{
+		UserID:      userID,
+		RefreshHash: hashToken(token),
+		UserAgent:   userAgent,
+		Ip:          ip,
+		ExpiresAt:   time.Now().Add(ttl),
+	})
+	if err != nil {
+		return Session{}, "", err
+	}
+	return sessionFromRow(row), token, nil
+}
+
+// Rotate trades a refresh token for a new one and extends the session by
+// ttl. It returns ErrNotFound for an unknown, already used, revoked or
+// expired token.
+func (r *SessionRepo) Rotate(ctx context.Context, refreshToken string, ttl time.Duration) (Session, string, error) {
+	token := newToken()
+	row, err := r.q.RotateSession(ctx, sqlc.RotateSessionParams{
+		NewHash:     hashToken(token),
+		ExpiresAt:   time.Now().Add(ttl),
+		RefreshHash: hashToken(refreshToken),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Session{}, "", ErrNotFound
+		}
+		return Session{}, "", err
+	}
+	return sessionFromRow(row), token, nil
+}
+
+// Active reports whether a session is neither revoked nor expired
+func (r *SessionRepo) Active(ctx context.Context, id string) (bool, error) {
+	return r.q.IsSessionActive(ctx, id)
+}
+
+// List returns a user's live sessions, most recently refreshed first
+func (r *SessionRepo) List(ctx context.Context, userID string) ([]Session, error) {
+	rows, err := r.q.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []Session{}
+	for _, row := range rows {
+		sessions = append(sessions, sessionFromRow(row))
+	}
+	return sessions, nil
+}
+
+// Revoke signs one of a user's sessions out; it reports whether there was
+// a live session to revoke
+func (r *SessionRepo) Revoke(ctx context.Context, id, userID string) (bool, error) {
+	if !isUUID(id) {
+		return false, nil
+	}
+	n, err := r.q.RevokeSession(ctx, sqlc.RevokeSessionParams{ID: id, UserID: userID})
+	return n > 0, err
+}
+
+// RevokeByRefreshToken signs out the session a refresh token belongs to
+func (r *SessionRepo) RevokeByRefreshToken(ctx context.Context, refreshToken string) error {
+	_, err := r.q.RevokeSessionByRefreshHash(ctx, hashToken(refreshToken))
+	return err
+}
+
+// RevokeAll signs a user out of every session but except, when given, and
+// returns how many were revoked
+func (r *SessionRepo) RevokeAll(ctx context.Context, userID string, except *string) (int64, error) {
+	return r.q.RevokeUserSessions(ctx, sqlc.RevokeUserSessionsParams{UserID: userID, ExceptID: except})
+}
+
+func sessionFromRow(row sqlc.Session) Session {
+	return Session{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		UserAgent:  row.UserAgent,
+		IP:         row.Ip,
+		CreatedAt:  row.CreatedAt,
+		LastUsedAt: row.LastUsedAt,
+		ExpiresAt:  row.ExpiresAt,
+	}
+}
+
+// newToken returns 256 random bits, URL-safe
+func newToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashToken is how tokens are stored: they are random, so an unsalted
+// SHA-256 is enough to make a leaked table useless
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestSessionRepo_CreateStoresOnlyTheHash(t *testing.T) {
+	db := newRecordDB()
+	_, token, err := newRepo(db).Sessions.Create(context.Background(), "user-1", "curl/8", "10.0.0.1", time.Hour)
+	require.NoError(t, err)
+
+	assert.Len(t, token, 43)
+	args := db.args["CreateSession"]
+	require.Len(t, args, 5)
+	assert.Equal(t, "user-1", args[0])
+	assert.Equal(t, hashToken(token), args[1])
+	assert.NotContains(t, args, token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), args[4].(time.Time), time.Minute)
+	// Dead sessions are cleared as new ones start
+	assert.Equal(t, []any{"user-1"}, db.args["PruneUserSessions"])
+}
+
+func TestSessionRepo_RotateUnknownToken(t *testing.T) {
+	db := newRecordDB()
+	db.rows["RotateSession"] = nil
+	_, _, err := newRepo(db).Sessions.Rotate(context.Background(), "used-token", time.Hour)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	args := db.args["RotateSession"]
+	require.Len(t, args, 3)
+	assert.Equal(t, hashToken("used-token"), args[2])
+	assert.NotEqual(t, args[0], args[2])
+}
+
+func TestUserRepo_UpdateRoleRevokesSessions(t *testing.T) {
+	db := newRecordDB()
+	db.tags["UpdateUserRole"] = "UPDATE 1"
+	require.NoError(t, newRepo(db).Users.UpdateRole(context.Background(), "user-1", models.RoleUser))
+	// Every session goes, none is spared
+	assert.Equal(t, []any{"user-1", (*string)(nil)}, db.args["RevokeUserSessions"])
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+type SLARepo struct{ q *sqlc.Queries }
+
+// ListPolicies returns all configured SLA policies, priority defaults first
+func (r *SLARepo) ListPolicies(ctx context.Context) ([]models.SLAPolicy, error) {
+	rows, err := r.q.ListSLAPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := []models.SLAPolicy{}
+	for _, p := range rows {
+		policies = append(policies, policyFromRow(p))
+	}
+	return policies, nil
+}
+
+// UpsertPolicy creates or replaces the policy for a priority/ticket type pair
+func (r *SLARepo) UpsertPolicy(ctx context.Context, p models.SLAPolicy) (models.SLAPolicy, error) {
+	row, err := r.q.UpsertSLAPolicy(ctx, sqlc.UpsertSLAPolicyParams{
+		Priority:          p.Priority,
+		InitialType:       p.InitialType,
+		ResponseMinutes:   p.ResponseMinutes,
+		ResolutionMinutes: p.ResolutionMinutes,
+	})
+	return policyFromRow(row), err
+}
+
+// DeletePolicy removes a policy; tickets fall back to the priority default
+func (r *SLARepo) DeletePolicy(ctx context.Context, id string) error {
+	if !isUUID(id) {
+		return ErrNotFound
+	}
+	n, err := r.q.DeleteSLAPolicy(ctx, id)
+	if err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func policyFromRow(p sqlc.SlaPolicy) models.SLAPolicy {
+	return models.SLAPolicy{
+		ID:                p.ID,
+		Priority:          p.Priority,
+		InitialType:       p.InitialType,
+		ResponseMinutes:   p.ResponseMinutes,
+		ResolutionMinutes: p.ResolutionMinutes,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
@@ -5,220 +5,442 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/search"
+	"github.com/it-tms/apps/api/internal/sqlc"
 )
 
-type TicketRepo struct{ pool *pgxpool.Pool }
+type TicketRepo struct{ q *sqlc.Queries }
 
 func (r *TicketRepo) Create(ctx context.Context, t *models.Ticket) error {
-    details, _ := json.Marshal(t.Details)
-    redFlagsData, _ := json.Marshal(t.RedFlagsData)
-    impactAssessmentData, _ := json.Marshal(t.ImpactAssessmentData)
-    urgencyTimelineData, _ := json.Marshal(t.UrgencyTimelineData)
-    effortData, _ := json.Marshal(t.EffortData)
-	
-    row := r.pool.QueryRow(ctx, `INSERT INTO tickets 
-        (created_by, initial_type, status, title, description, details, impact_score, urgency_score, final_score, red_flag, priority, red_flags_data, impact_assessment_data, urgency_timeline_data, effort_data, effort_score) 
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
-        RETURNING id, code, created_at, updated_at`,
-        t.CreatedBy, t.InitialType, t.Status, t.Title, t.Description, details, t.ImpactScore, t.UrgencyScore, t.FinalScore, t.RedFlag, t.Priority, redFlagsData, impactAssessmentData, urgencyTimelineData, effortData, t.EffortScore,
-    )
-	return row.Scan(&t.ID, &t.Code, &t.CreatedAt, &t.UpdatedAt)
+	details, _ := json.Marshal(t.Details)
+	redFlagsData, _ := json.Marshal(t.RedFlagsData)
+	impactAssessmentData, _ := json.Marshal(t.ImpactAssessmentData)
+	urgencyTimelineData, _ := json.Marshal(t.UrgencyTimelineData)
+	effortData, _ := json.Marshal(t.EffortData)
+
+	row, err := r.q.CreateTicket(ctx, sqlc.CreateTicketParams{
+		CreatedBy:            t.CreatedBy,
+		InitialType:          t.InitialType,
+		Status:               t.Status,
+		Title:                t.Title,
+		Description:          t.Description,
+		Details:              details,
+		ImpactScore:          int16(t.ImpactScore),
+		UrgencyScore:         int16(t.UrgencyScore),
+		FinalScore:           int16(t.FinalScore),
+		RedFlag:              t.RedFlag,
+		Priority:             t.Priority,
+		RedFlagsData:         redFlagsData,
+		ImpactAssessmentData: impactAssessmentData,
+		UrgencyTimelineData:  urgencyTimelineData,
+		EffortData:           effortData,
+		EffortScore:          int16(t.EffortScore),
+		ResponseDueAt:        t.ResponseDueAt,
+		ResolutionDueAt:      t.ResolutionDueAt,
+	})
+	if err != nil {
+		return err
+	}
+	t.ID, t.Code, t.CreatedAt, t.UpdatedAt = row.ID, row.Code, row.CreatedAt, row.UpdatedAt
+	if t.CreatedBy != nil {
+		if err := r.q.WatchTicket(ctx, sqlc.WatchTicketParams{TicketID: t.ID, UserID: *t.CreatedBy}); err != nil {
+			return err
+		}
+	}
+	return indexTicket(ctx, r.q, t.ID, t.Title, t.Description, t.Details)
 }
 
+// TicketFilters narrows ticket lists. Zero values mean no filter; a list
+// matches any of its values. The JSON form, with the same names as the list
+// query parameters, is how saved views store their filters.
 type TicketFilters struct {
-	Status     string
-	Priority   string
-	AssigneeID string
-	CreatedBy  string
-	Query      string
+	Statuses      []models.TicketStatus       `json:"status,omitempty"`
+	Priorities    []models.TicketPriority     `json:"priority,omitempty"`
+	InitialTypes  []models.TicketInitialType  `json:"initialType,omitempty"`
+	ResolvedTypes []models.TicketResolvedType `json:"resolvedType,omitempty"`
+	AssigneeID    string                      `json:"assigneeId,omitempty"`
+	// Unassigned keeps only tickets nobody is assigned to
+	Unassigned bool   `json:"unassigned,omitempty"`
+	CreatedBy  string `json:"createdBy,omitempty"`
+	RedFlag    *bool  `json:"redFlag,omitempty"`
+	// Date ranges include From and exclude To
+	CreatedFrom *time.Time `json:"createdFrom,omitempty"`
+	CreatedTo   *time.Time `json:"createdTo,omitempty"`
+	UpdatedFrom *time.Time `json:"updatedFrom,omitempty"`
+	UpdatedTo   *time.Time `json:"updatedTo,omitempty"`
+	ClosedFrom  *time.Time `json:"closedFrom,omitempty"`
+	ClosedTo    *time.Time `json:"closedTo,omitempty"`
+	EffortMin   *int       `json:"effortMin,omitempty"`
+	EffortMax   *int       `json:"effortMax,omitempty"`
+	Query       string     `json:"q,omitempty"`
+	// Watching keeps the tickets Watcher watches. Saved views store only
+	// the flag, so a shared view lists each viewer's own watched tickets.
+	Watching bool   `json:"watching,omitempty"`
+	Watcher  string `json:"-"`
+	// Sort is a key of TicketSorts, prefixed with "-" for descending order;
+	// empty keeps the default order (priority, then most recently updated).
+	// Saved views keep it apart from their filters.
+	Sort string `json:"-"`
+}
+
+var (
+	ticketStatuses = []models.TicketStatus{models.StatusPending, models.StatusInProgress, models.StatusOnHold,
+		models.StatusWaitingForRequester, models.StatusCompleted, models.StatusCanceled}
+	ticketPriorities   = []models.TicketPriority{models.PriorityP0, models.PriorityP1, models.PriorityP2, models.PriorityP3}
+	ticketInitialTypes = []models.TicketInitialType{models.InitialIssueReport, models.InitialChangeRequestNormal,
+		models.InitialServiceDataCorrection, models.InitialServiceDataExtraction, models.InitialServiceAdvisory,
+		models.InitialServiceGeneral}
+	ticketResolvedTypes = []models.TicketResolvedType{models.ResolvedEmergencyChange, models.ResolvedDataCorrection}
+)
+
+// Validate reports the first value the list queries would not understand,
+// named after its query parameter.
+func (f TicketFilters) Validate() error {
+	if err := validEnums("status", f.Statuses, ticketStatuses); err != nil {
+		return err
+	}
+	if err := validEnums("priority", f.Priorities, ticketPriorities); err != nil {
+		return err
+	}
+	if err := validEnums("initialType", f.InitialTypes, ticketInitialTypes); err != nil {
+		return err
+	}
+	if err := validEnums("resolvedType", f.ResolvedTypes, ticketResolvedTypes); err != nil {
+		return err
+	}
+	if f.EffortMin != nil && *f.EffortMin < 0 {
+		return fmt.Errorf("invalid effortMin %d", *f.EffortMin)
+	}
+	if f.EffortMax != nil && *f.EffortMax < 0 {
+		return fmt.Errorf("invalid effortMax %d", *f.EffortMax)
+	}
+	if _, err := f.sortKey(); err != nil {
+		return fmt.Errorf("invalid sort %q", f.Sort)
+	}
+	return nil
+}
+
+func validEnums[T ~string](key string, values, valid []T) error {
+	for _, v := range values {
+		if !slices.Contains(valid, v) {
+			return fmt.Errorf("invalid %s %q", key, v)
+		}
+	}
+	return nil
+}
+
+// Merge returns f with every filter that o sets replacing f's own, so that
+// query parameters can refine a saved view.
+func (f TicketFilters) Merge(o TicketFilters) TicketFilters {
+	merged := map[string]json.RawMessage{}
+	for _, src := range []TicketFilters{f, o} {
+		b, _ := json.Marshal(src)
+		json.Unmarshal(b, &merged)
+	}
+	b, _ := json.Marshal(merged)
+	var out TicketFilters
+	json.Unmarshal(b, &out)
+	out.Sort = f.Sort
+	if o.Sort != "" {
+		out.Sort = o.Sort
+	}
+	return out
+}
+
+// TicketSorts are the sort keys List accepts, mapped to the column keys the
+// ListTickets query orders by
+var TicketSorts = map[string]string{
+	"priority":    "priority_rank",
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+	"closedAt":    "closed_at",
+	"dueAt":       "resolution_due_at",
+	"code":        "code",
+	"effortScore": "effort_score",
+	"finalScore":  "final_score",
+}
+
+// ErrInvalidSort is returned by List for a sort outside TicketSorts
+var ErrInvalidSort = errors.New("invalid sort")
+
+// sortKey resolves Sort to the ListTickets sort parameter
+func (f TicketFilters) sortKey() (string, error) {
+	if f.Sort == "" {
+		return "", nil
+	}
+	name, desc := strings.CutPrefix(f.Sort, "-")
+	key, ok := TicketSorts[name]
+	if !ok {
+		return "", ErrInvalidSort
+	}
+	if desc {
+		return "-" + key, nil
+	}
+	// Ascending priority is the default order
+	if key == "priority_rank" {
+		return "", nil
+	}
+	return key, nil
+}
+
+// params maps the filters onto the query parameters; NULLs and empty lists
+// disable their condition
+func (f TicketFilters) params() sqlc.CountTicketsParams {
+	return sqlc.CountTicketsParams{
+		Statuses:      texts(f.Statuses),
+		Priorities:    texts(f.Priorities),
+		InitialTypes:  texts(f.InitialTypes),
+		ResolvedTypes: texts(f.ResolvedTypes),
+		AssigneeID:    optString(f.AssigneeID),
+		Unassigned:    f.Unassigned,
+		CreatedBy:     optString(f.CreatedBy),
+		RedFlag:       f.RedFlag,
+		CreatedFrom:   f.CreatedFrom,
+		CreatedTo:     f.CreatedTo,
+		UpdatedFrom:   f.UpdatedFrom,
+		UpdatedTo:     f.UpdatedTo,
+		ClosedFrom:    f.ClosedFrom,
+		ClosedTo:      f.ClosedTo,
+		EffortMin:     optInt16(optInt32(f.EffortMin)),
+		EffortMax:     optInt16(optInt32(f.EffortMax)),
+		Query:         optString(search.Query(f.Query)),
+		WatcherID:     f.watcherID(),
+	}
+}
+
+// watcherID is the Watching filter's parameter; Watcher must be set with it
+func (f TicketFilters) watcherID() *string {
+	if !f.Watching {
+		return nil
+	}
+	return &f.Watcher
 }
 
 func (r *TicketRepo) List(ctx context.Context, f TicketFilters, offset, limit int) ([]models.Ticket, int64, error) {
-	clauses := []string{"1=1"}
-	args := []any{}
-	arg := 1
-	if f.Status != "" {
-		clauses = append(clauses, fmt.Sprintf("status = $%d", arg)); args = append(args, f.Status); arg++
-	}
-	if f.Priority != "" {
-		clauses = append(clauses, fmt.Sprintf("priority = $%d", arg)); args = append(args, f.Priority); arg++
-	}
-	if f.AssigneeID != "" {
-		// Check both the new multiple assignee system and legacy single assignee field
-		clauses = append(clauses, fmt.Sprintf("(assignee_id = $%d OR EXISTS (SELECT 1 FROM ticket_assignments ta WHERE ta.ticket_id = t.id AND ta.assignee_id = $%d))", arg, arg)); args = append(args, f.AssigneeID); arg++
-	}
-	if f.CreatedBy != "" {
-		clauses = append(clauses, fmt.Sprintf("created_by = $%d", arg)); args = append(args, f.CreatedBy); arg++
-	}
-	if f.Query != "" {
-		clauses = append(clauses, fmt.Sprintf("to_tsvector('english', title || ' ' || description) @@ plainto_tsquery('english', $%d)", arg))
-		args = append(args, f.Query); arg++
-	}
-
-	where := strings.Join(clauses, " AND ")
-	sql := fmt.Sprintf(`SELECT 
-		t.id, t.code, t.created_by, t.initial_type, t.resolved_type, t.status, t.title, t.description, t.details, t.impact_score, t.urgency_score, t.final_score, t.red_flag, t.priority, t.assignee_id, t.created_at, t.updated_at, t.closed_at,
-		(SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) as latest_comment
-	FROM tickets t WHERE %s ORDER BY 
-		CASE t.priority 
-			WHEN 'P0' THEN 0 
-			WHEN 'P1' THEN 1 
-			WHEN 'P2' THEN 2 
-			WHEN 'P3' THEN 3 
-			ELSE 4 
-		END ASC, 
-		t.updated_at DESC, 
-		t.effort_score ASC 
-	OFFSET $%d LIMIT $%d`, where, arg, arg+1)
-	args = append(args, offset, limit)
-
-	rows, err := r.pool.Query(ctx, sql, args...)
-	if err != nil { return nil, 0, err }
-	defer rows.Close()
+	sort, err := f.sortKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	filter := f.params()
+	rows, err := r.q.ListTickets(ctx, sqlc.ListTicketsParams{
+		Statuses:      filter.Statuses,
+		Priorities:    filter.Priorities,
+		InitialTypes:  filter.InitialTypes,
+		ResolvedTypes: filter.ResolvedTypes,
+		AssigneeID:    filter.AssigneeID,
+		Unassigned:    filter.Unassigned,
+		CreatedBy:     filter.CreatedBy,
+		RedFlag:       filter.RedFlag,
+		CreatedFrom:   filter.CreatedFrom,
+		CreatedTo:     filter.CreatedTo,
+		UpdatedFrom:   filter.UpdatedFrom,
+		UpdatedTo:     filter.UpdatedTo,
+		ClosedFrom:    filter.ClosedFrom,
+		ClosedTo:      filter.ClosedTo,
+		EffortMin:     filter.EffortMin,
+		EffortMax:     filter.EffortMax,
+		Query:         filter.Query,
+		WatcherID:     filter.WatcherID,
+		Sort:          sort,
+		Offset:        int32(offset),
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.Ticket.ID
+	}
+	assignees, err := assigneesByTicket(ctx, r.q, ids)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	items := []models.Ticket{}
-	for rows.Next() {
-		var t models.Ticket
-		var details []byte
-		var latestComment *string
-		err := rows.Scan(&t.ID, &t.Code, &t.CreatedBy, &t.InitialType, &t.ResolvedType, &t.Status, &t.Title, &t.Description, &details, &t.ImpactScore, &t.UrgencyScore, &t.FinalScore, &t.RedFlag, &t.Priority, &t.AssigneeID, &t.CreatedAt, &t.UpdatedAt, &t.ClosedAt, &latestComment)
-		if err != nil { return nil, 0, err }
-		json.Unmarshal(details, &t.Details)
-		t.LatestComment = latestComment
-		
-		// Fetch assignees for this ticket
-		assignees := []models.User{}
-		assigneeRows, assigneeErr := r.pool.Query(ctx, `
-			SELECT u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at
-			FROM ticket_assignments ta
-			JOIN users u ON ta.assignee_id = u.id
-			WHERE ta.ticket_id=$1 
-			ORDER BY ta.assigned_at ASC`, t.ID)
-		
-		if assigneeErr == nil {
-			for assigneeRows.Next() {
-				var u models.User
-				assigneeRows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.CreatedAt, &u.UpdatedAt)
-				assignees = append(assignees, u)
-			}
-			assigneeRows.Close()
+	for _, row := range rows {
+		t := ticketFromRow(row.Ticket, row.LatestComment)
+		t.Assignees = assignees[t.ID]
+		if t.Assignees == nil {
+			t.Assignees = []models.User{}
 		}
-		t.Assignees = assignees
-		
 		items = append(items, t)
 	}
 
-	// total
-	countArgs := args[:len(args)-2] // Remove offset and limit from args
-	var total int64
-	if len(countArgs) == 0 {
-		row := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM tickets`)
-		if err := row.Scan(&total); err != nil { return nil, 0, err }
+	total, err := r.q.CountTickets(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Count returns how many tickets match f
+func (r *TicketRepo) Count(ctx context.Context, f TicketFilters) (int64, error) {
+	return r.q.CountTickets(ctx, f.params())
+}
+
+// ListByCursor is the keyset-paginated counterpart of List: it returns up to
+// limit tickets after (or before) cur, in the default order, without a total.
+// A nil cursor starts from the top of the list. f.Sort is not supported.
+func (r *TicketRepo) ListByCursor(ctx context.Context, f TicketFilters, cur *Cursor, limit int) ([]models.Ticket, CursorPage, error) {
+	if f.Sort != "" {
+		return nil, CursorPage{}, ErrInvalidSort
+	}
+	filter := f.params()
+	var rows []sqlc.ListTicketsAfterRow
+	var err error
+	if cur != nil && cur.Before {
+		var before []sqlc.ListTicketsBeforeRow
+		before, err = r.q.ListTicketsBefore(ctx, sqlc.ListTicketsBeforeParams{
+			Statuses:        filter.Statuses,
+			Priorities:      filter.Priorities,
+			InitialTypes:    filter.InitialTypes,
+			ResolvedTypes:   filter.ResolvedTypes,
+			AssigneeID:      filter.AssigneeID,
+			Unassigned:      filter.Unassigned,
+			CreatedBy:       filter.CreatedBy,
+			RedFlag:         filter.RedFlag,
+			CreatedFrom:     filter.CreatedFrom,
+			CreatedTo:       filter.CreatedTo,
+			UpdatedFrom:     filter.UpdatedFrom,
+			UpdatedTo:       filter.UpdatedTo,
+			ClosedFrom:      filter.ClosedFrom,
+			ClosedTo:        filter.ClosedTo,
+			EffortMin:       filter.EffortMin,
+			EffortMax:       filter.EffortMax,
+			Query:           filter.Query,
+			WatcherID:       filter.WatcherID,
+			CursorRank:      cur.Rank,
+			CursorUpdatedAt: cur.Time,
+			CursorEffort:    cur.Effort,
+			CursorID:        cur.ID,
+			Limit:           int32(limit + 1),
+		})
+		for _, row := range before {
+			rows = append(rows, sqlc.ListTicketsAfterRow(row))
+		}
 	} else {
-		countSQL := fmt.Sprintf(`SELECT COUNT(*) FROM tickets t WHERE %s`, where)
-		row := r.pool.QueryRow(ctx, countSQL, countArgs...)
-		if err := row.Scan(&total); err != nil { return nil, 0, err }
+		params := sqlc.ListTicketsAfterParams{
+			Statuses:      filter.Statuses,
+			Priorities:    filter.Priorities,
+			InitialTypes:  filter.InitialTypes,
+			ResolvedTypes: filter.ResolvedTypes,
+			AssigneeID:    filter.AssigneeID,
+			Unassigned:    filter.Unassigned,
+			CreatedBy:     filter.CreatedBy,
+			RedFlag:       filter.RedFlag,
+			CreatedFrom:   filter.CreatedFrom,
+			CreatedTo:     filter.CreatedTo,
+			UpdatedFrom:   filter.UpdatedFrom,
+			UpdatedTo:     filter.UpdatedTo,
+			ClosedFrom:    filter.ClosedFrom,
+			ClosedTo:      filter.ClosedTo,
+			EffortMin:     filter.EffortMin,
+			EffortMax:     filter.EffortMax,
+			Query:         filter.Query,
+			WatcherID:     filter.WatcherID,
+			Limit:         int32(limit + 1),
+		}
+		if cur != nil {
+			params.CursorID, params.CursorRank, params.CursorUpdatedAt, params.CursorEffort = &cur.ID, &cur.Rank, &cur.Time, &cur.Effort
+		}
+		rows, err = r.q.ListTicketsAfter(ctx, params)
+	}
+	if err != nil {
+		return nil, CursorPage{}, err
 	}
 
-	return items, total, nil
+	rows, page := cursorPage(rows, cur, limit, func(row sqlc.ListTicketsAfterRow) Cursor {
+		return Cursor{Rank: row.Ticket.PriorityRank, Time: row.Ticket.UpdatedAt, Effort: row.Ticket.EffortScore, ID: row.Ticket.ID}
+	})
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.Ticket.ID
+	}
+	assignees, err := assigneesByTicket(ctx, r.q, ids)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	items := []models.Ticket{}
+	for _, row := range rows {
+		t := ticketFromRow(row.Ticket, row.LatestComment)
+		t.Assignees = assignees[t.ID]
+		if t.Assignees == nil {
+			t.Assignees = []models.User{}
+		}
+		items = append(items, t)
+	}
+	return items, page, nil
 }
 
 func (r *TicketRepo) GetByID(ctx context.Context, id string) (models.Ticket, error) {
-	var t models.Ticket
-    var details, redFlagsData, impactAssessmentData, urgencyTimelineData, effortData []byte
-	var latestComment *string
-	row := r.pool.QueryRow(ctx, `SELECT 
-        t.id, t.code, t.created_by, t.initial_type, t.resolved_type, t.status, t.title, t.description, t.details, t.impact_score, t.urgency_score, t.final_score, t.red_flag, t.priority, t.assignee_id, t.red_flags_data, t.impact_assessment_data, t.urgency_timeline_data, t.effort_data, t.effort_score, t.created_at, t.updated_at, t.closed_at,
-		(SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) as latest_comment
-	FROM tickets t WHERE t.id=$1`, id)
-    if err := row.Scan(&t.ID, &t.Code, &t.CreatedBy, &t.InitialType, &t.ResolvedType, &t.Status, &t.Title, &t.Description, &details, &t.ImpactScore, &t.UrgencyScore, &t.FinalScore, &t.RedFlag, &t.Priority, &t.AssigneeID, &redFlagsData, &impactAssessmentData, &urgencyTimelineData, &effortData, &t.EffortScore, &t.CreatedAt, &t.UpdatedAt, &t.ClosedAt, &latestComment); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return t, ErrNotFound }
-		return t, err
-	}
-	json.Unmarshal(details, &t.Details)
-	json.Unmarshal(redFlagsData, &t.RedFlagsData)
-	json.Unmarshal(impactAssessmentData, &t.ImpactAssessmentData)
-	json.Unmarshal(urgencyTimelineData, &t.UrgencyTimelineData)
-    json.Unmarshal(effortData, &t.EffortData)
-	t.LatestComment = latestComment
-	return t, nil
+	row, err := r.q.GetTicket(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Ticket{}, ErrNotFound
+		}
+		return models.Ticket{}, err
+	}
+	return ticketFromRow(row.Ticket, row.LatestComment), nil
+}
+
+// IDByCode looks up a ticket by the number users see, as in "IT-TMS #42"
+func (r *TicketRepo) IDByCode(ctx context.Context, code int32) (string, error) {
+	id, err := r.q.GetTicketIDByCode(ctx, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return id, err
 }
 
 func (r *TicketRepo) GetWithRelations(ctx context.Context, id string) (models.Ticket, []models.Comment, []models.Attachment, error) {
-	var t models.Ticket
-    var details, redFlagsData, impactAssessmentData, urgencyTimelineData, effortData []byte
-	var latestComment *string
-	row := r.pool.QueryRow(ctx, `SELECT 
-        t.id, t.code, t.created_by, t.initial_type, t.resolved_type, t.status, t.title, t.description, t.details, t.impact_score, t.urgency_score, t.final_score, t.red_flag, t.priority, t.assignee_id, t.red_flags_data, t.impact_assessment_data, t.urgency_timeline_data, t.effort_data, t.effort_score, t.created_at, t.updated_at, t.closed_at,
-		(SELECT c.body FROM comments c WHERE c.ticket_id = t.id ORDER BY c.created_at DESC LIMIT 1) as latest_comment
-	FROM tickets t WHERE t.id=$1`, id)
-	if err := row.Scan(&t.ID, &t.Code, &t.CreatedBy, &t.InitialType, &t.ResolvedType, &t.Status, &t.Title, &t.Description, &details, &t.ImpactScore, &t.UrgencyScore, &t.FinalScore, &t.RedFlag, &t.Priority, &t.AssigneeID, &redFlagsData, &impactAssessmentData, &urgencyTimelineData, &effortData, &t.EffortScore, &t.CreatedAt, &t.UpdatedAt, &t.ClosedAt, &latestComment); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return t, nil, nil, ErrNotFound }
+	t, err := r.GetByID(ctx, id)
+	if err != nil {
 		return t, nil, nil, err
 	}
-	json.Unmarshal(details, &t.Details)
-	json.Unmarshal(redFlagsData, &t.RedFlagsData)
-	json.Unmarshal(impactAssessmentData, &t.ImpactAssessmentData)
-	json.Unmarshal(urgencyTimelineData, &t.UrgencyTimelineData)
-    json.Unmarshal(effortData, &t.EffortData)
-	t.LatestComment = latestComment
 
+	commentRows, err := r.q.ListTicketComments(ctx, id)
+	if err != nil {
+		return t, nil, nil, err
+	}
 	comments := []models.Comment{}
-	rows, err := r.pool.Query(ctx, `
-		SELECT c.id, c.ticket_id, c.author_id, u.name, u.role, c.body, c.created_at 
-		FROM comments c
-		LEFT JOIN users u ON c.author_id = u.id
-		WHERE c.ticket_id=$1 
-		ORDER BY c.created_at DESC`, id)
-	if err == nil {
-		for rows.Next() {
-			var c models.Comment
-			rows.Scan(&c.ID, &c.TicketID, &c.AuthorID, &c.AuthorName, &c.AuthorRole, &c.Body, &c.CreatedAt)
-			
-			// Get comment attachments
-			commentAttachments, _ := r.GetCommentAttachments(ctx, c.ID)
-			c.Attachments = commentAttachments
-			
-			comments = append(comments, c)
+	for _, row := range commentRows {
+		c := commentFromRow(row)
+		// Get comment attachments
+		if c.Attachments, err = r.GetCommentAttachments(ctx, c.ID); err != nil {
+			return t, nil, nil, err
 		}
-		rows.Close()
+		comments = append(comments, c)
 	}
 
+	attachmentRows, err := r.q.ListTicketAttachments(ctx, id)
+	if err != nil {
+		return t, nil, nil, err
+	}
 	atts := []models.Attachment{}
-	r2, err := r.pool.Query(ctx, `SELECT id, ticket_id, filename, mime, size, path, created_at FROM attachments WHERE ticket_id=$1 ORDER BY created_at ASC`, id)
-	if err == nil {
-		for r2.Next() {
-			var a models.Attachment
-			r2.Scan(&a.ID, &a.TicketID, &a.Filename, &a.MIME, &a.Size, &a.Path, &a.CreatedAt)
-			atts = append(atts, a)
-		}
-		r2.Close()
+	for _, a := range attachmentRows {
+		atts = append(atts, models.Attachment{ID: a.ID, TicketID: a.TicketID, Filename: a.Filename, MIME: a.Mime, Size: a.Size, Path: a.Path, CreatedAt: a.CreatedAt})
 	}
 
 	// Fetch assignees
-	assignees := []models.User{}
-	r3, err := r.pool.Query(ctx, `
-		SELECT u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at
-		FROM ticket_assignments ta
-		JOIN users u ON ta.assignee_id = u.id
-		WHERE ta.ticket_id=$1 
-		ORDER BY ta.assigned_at ASC`, id)
-	if err == nil {
-		for r3.Next() {
-			var u models.User
-			r3.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.CreatedAt, &u.UpdatedAt)
-			assignees = append(assignees, u)
-		}
-		r3.Close()
+	assignees, err := assigneesByTicket(ctx, r.q, []string{id})
+	if err != nil {
+		return t, nil, nil, err
+	}
+	t.Assignees = assignees[id]
+	if t.Assignees == nil {
+		t.Assignees = []models.User{}
 	}
-	t.Assignees = assignees
 
 	return t, comments, atts, nil
 }
@@ -227,198 +449,216 @@ func (r *TicketRepo) Update(ctx context.Context, id string, title, description *
 	if title == nil && description == nil && details == nil {
 		return nil
 	}
-	set := []string{}
-	args := []any{}
-	arg := 1
-	if title != nil {
-		set = append(set, fmt.Sprintf("title=$%d", arg))
-		args = append(args, *title); arg++
-	}
-	if description != nil {
-		set = append(set, fmt.Sprintf("description=$%d", arg))
-		args = append(args, *description); arg++
-	}
+	var detailsJSON []byte
 	if details != nil {
-		b, _ := json.Marshal(details)
-		set = append(set, fmt.Sprintf("details=$%d", arg))
-		args = append(args, b); arg++
+		detailsJSON, _ = json.Marshal(details)
 	}
-	args = append(args, id)
-	sql := fmt.Sprintf("UPDATE tickets SET %s, updated_at=NOW() WHERE id=$%d", strings.Join(set, ","), arg)
-	_, err := r.pool.Exec(ctx, sql, args...)
-	return err
+	row, err := r.q.UpdateTicket(ctx, sqlc.UpdateTicketParams{Title: title, Description: description, Details: detailsJSON, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	var current map[string]any
+	json.Unmarshal(row.Details, &current)
+	return indexTicket(ctx, r.q, id, row.Title, row.Description, current)
 }
 
 func (r *TicketRepo) Assign(ctx context.Context, id string, assigneeID *string) error {
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET assignee_id=$1, updated_at=NOW() WHERE id=$2`, assigneeID, id)
-	return err
+	return r.q.AssignTicket(ctx, sqlc.AssignTicketParams{AssigneeID: assigneeID, ID: id})
 }
 
-func (r *TicketRepo) ChangeStatus(ctx context.Context, id string, status models.TicketStatus) error {
-	// Validate completion requirements
-	if status == models.StatusCompleted {
-		assignees, err := r.GetAssignees(ctx, id)
-		if err != nil {
-			return err
-		}
-		if len(assignees) == 0 {
-			return errors.New("cannot complete ticket without at least one assignee")
-		}
-	}
+// ErrStatusChanged is returned by ChangeStatus when the ticket no longer has
+// the status its transition was checked from
+var ErrStatusChanged = errors.New("ticket status changed")
 
+// ChangeStatus moves a ticket from one status to another. Transition rules and
+// guards live in internal/workflow and must be checked by the caller against
+// from; if another request changed the status since, nothing is written and
+// ErrStatusChanged is returned.
+func (r *TicketRepo) ChangeStatus(ctx context.Context, id string, from, status models.TicketStatus) error {
 	now := time.Now()
 	var closedAt *time.Time
 	if status == models.StatusCompleted || status == models.StatusCanceled {
 		closedAt = &now
 	}
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET status=$1, closed_at=$2, updated_at=NOW() WHERE id=$3`, status, closedAt, id)
-	return err
+	n, err := r.q.ChangeStatus(ctx, sqlc.ChangeStatusParams{Status: status, ClosedAt: closedAt, ID: id, FromStatus: from})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStatusChanged
+	}
+	return nil
 }
 
+// Reopen moves a completed ticket back to in_progress and counts the reopen.
+// It returns ErrNotFound if the ticket does not exist or is no longer completed.
+func (r *TicketRepo) Reopen(ctx context.Context, id string) (int32, error) {
+	count, err := r.q.ReopenTicket(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return count, err
+}
+
+// ListIdlePaused returns paused tickets with no activity (pause or comment) since before
+func (r *TicketRepo) ListIdlePaused(ctx context.Context, before time.Time) ([]string, error) {
+	ids, err := r.q.ListIdlePausedTickets(ctx, before)
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, err
+}
+
+// UpdateSLADueDates stores recalculated SLA deadlines for a ticket
+func (r *TicketRepo) UpdateSLADueDates(ctx context.Context, id string, responseDueAt, resolutionDueAt time.Time) error {
+	return r.q.UpdateSLADueDates(ctx, sqlc.UpdateSLADueDatesParams{ResponseDueAt: &responseDueAt, ResolutionDueAt: &resolutionDueAt, ID: id})
+}
+
+// AddSLAPausedTime adds d to the business time the SLA clock of a ticket has been paused
+func (r *TicketRepo) AddSLAPausedTime(ctx context.Context, id string, d time.Duration) error {
+	return r.q.AddSLAPausedTime(ctx, sqlc.AddSLAPausedTimeParams{Seconds: int64(d / time.Second), ID: id})
+}
+
+// UpdateTicketFields stores the non-nil fields and leaves the others unchanged
 func (r *TicketRepo) UpdateTicketFields(ctx context.Context, id string, initialType *models.TicketInitialType, resolvedType *models.TicketResolvedType, priority *models.TicketPriority, impactScore, urgencyScore, finalScore *int32, redFlag *bool) error {
-	args := []any{id}
-	set := []string{}
-	arg := 2
-	
-	if initialType != nil {
-		set = append(set, fmt.Sprintf("initial_type=$%d", arg))
-		args = append(args, *initialType)
-		arg++
-	}
-	if resolvedType != nil {
-		set = append(set, fmt.Sprintf("resolved_type=$%d", arg))
-		args = append(args, *resolvedType)
-		arg++
-	}
-	if priority != nil {
-		set = append(set, fmt.Sprintf("priority=$%d", arg))
-		args = append(args, *priority)
-		arg++
-	}
-	if impactScore != nil {
-		set = append(set, fmt.Sprintf("impact_score=$%d", arg))
-		args = append(args, *impactScore)
-		arg++
-	}
-	if urgencyScore != nil {
-		set = append(set, fmt.Sprintf("urgency_score=$%d", arg))
-		args = append(args, *urgencyScore)
-		arg++
-	}
-	if finalScore != nil {
-		set = append(set, fmt.Sprintf("final_score=$%d", arg))
-		args = append(args, *finalScore)
-		arg++
-	}
-    if redFlag != nil {
-        set = append(set, fmt.Sprintf("red_flag=$%d", arg))
-        args = append(args, *redFlag)
-        arg++
-    }
-	
-	if len(set) == 0 {
+	if initialType == nil && resolvedType == nil && priority == nil && impactScore == nil && urgencyScore == nil && finalScore == nil && redFlag == nil {
 		return nil
 	}
-	
-	sql := fmt.Sprintf("UPDATE tickets SET %s, updated_at=NOW() WHERE id=$1", strings.Join(set, ","))
-	_, err := r.pool.Exec(ctx, sql, args...)
-	return err
+	return r.q.UpdateTicketFields(ctx, sqlc.UpdateTicketFieldsParams{
+		InitialType:  initialType,
+		ResolvedType: resolvedType,
+		Priority:     priority,
+		ImpactScore:  optInt16(impactScore),
+		UrgencyScore: optInt16(urgencyScore),
+		FinalScore:   optInt16(finalScore),
+		RedFlag:      redFlag,
+		ID:           id,
+	})
 }
 
 func (r *TicketRepo) AddComment(ctx context.Context, id string, authorID *string, body string) error {
-	_, err := r.pool.Exec(ctx, `INSERT INTO comments (ticket_id, author_id, body, is_system_generated) VALUES ($1,$2,$3,$4)`, id, authorID, body, false)
+	_, err := r.AddCommentWithID(ctx, id, authorID, body)
 	return err
 }
 
 func (r *TicketRepo) AddSystemComment(ctx context.Context, id string, body string) error {
-	_, err := r.pool.Exec(ctx, `INSERT INTO comments (ticket_id, author_id, body, is_system_generated) VALUES ($1,NULL,$2,$3)`, id, body, true)
+	_, err := r.q.CreateComment(ctx, sqlc.CreateCommentParams{TicketID: id, Body: body, IsSystemGenerated: true})
 	return err
 }
 
 func (r *TicketRepo) AddCommentWithID(ctx context.Context, id string, authorID *string, body string) (string, error) {
-	var commentID string
-	err := r.pool.QueryRow(ctx, `INSERT INTO comments (ticket_id, author_id, body) VALUES ($1,$2,$3) RETURNING id`, id, authorID, body).Scan(&commentID)
-	return commentID, err
+	commentID, err := r.q.CreateComment(ctx, sqlc.CreateCommentParams{TicketID: id, AuthorID: authorID, Body: body})
+	if err != nil {
+		return "", err
+	}
+	// Commenting on a ticket follows it
+	if authorID != nil {
+		if err := r.q.WatchTicket(ctx, sqlc.WatchTicketParams{TicketID: id, UserID: *authorID}); err != nil {
+			return "", err
+		}
+	}
+	return commentID, indexComment(ctx, r.q, commentID, id, body)
 }
 
 func (r *TicketRepo) AddAttachment(ctx context.Context, id, filename, mime string, size int64, path string) error {
-	_, err := r.pool.Exec(ctx, `INSERT INTO attachments (ticket_id, filename, mime, size, path) VALUES ($1,$2,$3,$4,$5)`, id, filename, mime, size, path)
-	return err
+	return r.q.CreateAttachment(ctx, sqlc.CreateAttachmentParams{TicketID: id, Filename: filename, Mime: mime, Size: size, Path: path})
 }
 
 func (r *TicketRepo) AddCommentAttachment(ctx context.Context, commentID, filename, mime string, size int64, path string) error {
-	_, err := r.pool.Exec(ctx, `INSERT INTO comment_attachments (comment_id, filename, mime, size, path) VALUES ($1,$2,$3,$4,$5)`, commentID, filename, mime, size, path)
-	return err
+	return r.q.CreateCommentAttachment(ctx, sqlc.CreateCommentAttachmentParams{CommentID: commentID, Filename: filename, Mime: mime, Size: size, Path: path})
 }
 
 func (r *TicketRepo) GetCommentsPaginated(ctx context.Context, ticketID string, page, pageSize int) ([]models.Comment, int64, error) {
 	offset := (page - 1) * pageSize
-	
-	// Get comments with pagination
-	rows, err := r.pool.Query(ctx, `
-		SELECT c.id, c.ticket_id, c.author_id, u.name, u.role, c.body, c.is_system_generated, c.created_at 
-		FROM comments c
-		LEFT JOIN users u ON c.author_id = u.id
-		WHERE c.ticket_id=$1 
-		ORDER BY c.created_at DESC
-		LIMIT $2 OFFSET $3`, ticketID, pageSize, offset)
+
+	rows, err := r.q.ListTicketCommentsPage(ctx, sqlc.ListTicketCommentsPageParams{TicketID: ticketID, Limit: int32(pageSize), Offset: int32(offset)})
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
-	
+
 	comments := []models.Comment{}
-	for rows.Next() {
-		var c models.Comment
-		if err := rows.Scan(&c.ID, &c.TicketID, &c.AuthorID, &c.AuthorName, &c.AuthorRole, &c.Body, &c.IsSystemGenerated, &c.CreatedAt); err != nil {
+	for _, row := range rows {
+		// Both comment queries select the same columns
+		c := commentFromRow(sqlc.ListTicketCommentsRow(row))
+		if c.Attachments, err = r.GetCommentAttachments(ctx, c.ID); err != nil {
 			return nil, 0, err
 		}
-		
-		// Get comment attachments
-		commentAttachments, _ := r.GetCommentAttachments(ctx, c.ID)
-		c.Attachments = commentAttachments
-		
 		comments = append(comments, c)
 	}
-	
-	// Get total count
-	var total int64
-	row := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM comments WHERE ticket_id=$1`, ticketID)
-	if err := row.Scan(&total); err != nil {
+
+	total, err := r.q.CountTicketComments(ctx, ticketID)
+	if err != nil {
 		return nil, 0, err
 	}
-	
-	return comments, total, rows.Err()
+	return comments, total, nil
+}
+
+// GetCommentsByCursor is the keyset-paginated counterpart of
+// GetCommentsPaginated, newest comment first. A nil cursor starts from the
+// newest comment.
+func (r *TicketRepo) GetCommentsByCursor(ctx context.Context, ticketID string, cur *Cursor, limit int) ([]models.Comment, CursorPage, error) {
+	var rows []sqlc.ListTicketCommentsRow
+	if cur != nil && cur.Before {
+		before, err := r.q.ListTicketCommentsBefore(ctx, sqlc.ListTicketCommentsBeforeParams{TicketID: ticketID, CursorCreatedAt: cur.Time, CursorID: cur.ID, Limit: int32(limit + 1)})
+		if err != nil {
+			return nil, CursorPage{}, err
+		}
+		for _, row := range before {
+			rows = append(rows, sqlc.ListTicketCommentsRow(row))
+		}
+	} else {
+		params := sqlc.ListTicketCommentsAfterParams{TicketID: ticketID, Limit: int32(limit + 1)}
+		if cur != nil {
+			params.CursorID, params.CursorCreatedAt = &cur.ID, &cur.Time
+		}
+		after, err := r.q.ListTicketCommentsAfter(ctx, params)
+		if err != nil {
+			return nil, CursorPage{}, err
+		}
+		for _, row := range after {
+			rows = append(rows, sqlc.ListTicketCommentsRow(row))
+		}
+	}
+
+	rows, page := cursorPage(rows, cur, limit, func(row sqlc.ListTicketCommentsRow) Cursor {
+		return Cursor{Time: row.CreatedAt, ID: row.ID}
+	})
+
+	comments := []models.Comment{}
+	for _, row := range rows {
+		c := commentFromRow(row)
+		var err error
+		if c.Attachments, err = r.GetCommentAttachments(ctx, c.ID); err != nil {
+			return nil, CursorPage{}, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, page, nil
 }
 
 func (r *TicketRepo) GetCommentAttachments(ctx context.Context, commentID string) ([]models.CommentAttachment, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, comment_id, filename, mime, size, path, created_at FROM comment_attachments WHERE comment_id=$1 ORDER BY created_at`, commentID)
+	rows, err := r.q.ListCommentAttachments(ctx, commentID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	
 	var attachments []models.CommentAttachment
-	for rows.Next() {
-		var a models.CommentAttachment
-		if err := rows.Scan(&a.ID, &a.CommentID, &a.Filename, &a.MIME, &a.Size, &a.Path, &a.CreatedAt); err != nil {
-			return nil, err
-		}
-		attachments = append(attachments, a)
+	for _, a := range rows {
+		attachments = append(attachments, commentAttachmentFromRow(a))
 	}
-	return attachments, rows.Err()
+	return attachments, nil
 }
 
 // UpdateRedFlags updates red flags data and creates automatic comment
 // Note: This should trigger score recalculation in the handler, not here
 func (r *TicketRepo) UpdateRedFlags(ctx context.Context, id string, redFlagsData map[string]any, authorName string) error {
 	redFlagsJSON, _ := json.Marshal(redFlagsData)
-	
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET red_flags_data=$1, updated_at=NOW() WHERE id=$2`, redFlagsJSON, id)
-	if err != nil {
+	if err := r.q.UpdateRedFlagsData(ctx, sqlc.UpdateRedFlagsDataParams{RedFlagsData: redFlagsJSON, ID: id}); err != nil {
 		return err
 	}
-	
+
 	// Add automatic comment
 	commentBody := fmt.Sprintf("Red Flags (Critical Issues) updated by %s", authorName)
 	return r.AddSystemComment(ctx, id, commentBody)
@@ -427,12 +667,10 @@ func (r *TicketRepo) UpdateRedFlags(ctx context.Context, id string, redFlagsData
 // UpdateImpactAssessment updates impact assessment data and creates automatic comment
 func (r *TicketRepo) UpdateImpactAssessment(ctx context.Context, id string, impactAssessmentData map[string]any, authorName string) error {
 	impactJSON, _ := json.Marshal(impactAssessmentData)
-	
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET impact_assessment_data=$1, updated_at=NOW() WHERE id=$2`, impactJSON, id)
-	if err != nil {
+	if err := r.q.UpdateImpactAssessmentData(ctx, sqlc.UpdateImpactAssessmentDataParams{ImpactAssessmentData: impactJSON, ID: id}); err != nil {
 		return err
 	}
-	
+
 	// Add automatic comment
 	commentBody := fmt.Sprintf("Impact Assessment updated by %s", authorName)
 	return r.AddSystemComment(ctx, id, commentBody)
@@ -441,12 +679,10 @@ func (r *TicketRepo) UpdateImpactAssessment(ctx context.Context, id string, impa
 // UpdateUrgencyTimeline updates urgency timeline data and creates automatic comment
 func (r *TicketRepo) UpdateUrgencyTimeline(ctx context.Context, id string, urgencyTimelineData map[string]any, authorName string) error {
 	urgencyJSON, _ := json.Marshal(urgencyTimelineData)
-	
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET urgency_timeline_data=$1, updated_at=NOW() WHERE id=$2`, urgencyJSON, id)
-	if err != nil {
+	if err := r.q.UpdateUrgencyTimelineData(ctx, sqlc.UpdateUrgencyTimelineDataParams{UrgencyTimelineData: urgencyJSON, ID: id}); err != nil {
 		return err
 	}
-	
+
 	// Add automatic comment
 	commentBody := fmt.Sprintf("Urgency Timeline updated by %s", authorName)
 	return r.AddSystemComment(ctx, id, commentBody)
@@ -454,70 +690,67 @@ func (r *TicketRepo) UpdateUrgencyTimeline(ctx context.Context, id string, urgen
 
 // UpdateEffort updates effort data and updates effort_score accordingly
 func (r *TicketRepo) UpdateEffort(ctx context.Context, id string, effortData map[string]any, effortScore int32, authorName string) error {
-    effortJSON, _ := json.Marshal(effortData)
-    _, err := r.pool.Exec(ctx, `UPDATE tickets SET effort_data=$1, effort_score=$2, updated_at=NOW() WHERE id=$3`, effortJSON, effortScore, id)
-    if err != nil {
-        return err
-    }
-    commentBody := fmt.Sprintf("Effort Score updated by %s", authorName)
-    return r.AddSystemComment(ctx, id, commentBody)
+	effortJSON, _ := json.Marshal(effortData)
+	if err := r.q.UpdateEffort(ctx, sqlc.UpdateEffortParams{EffortData: effortJSON, EffortScore: int16(effortScore), ID: id}); err != nil {
+		return err
+	}
+	commentBody := fmt.Sprintf("Effort Score updated by %s", authorName)
+	return r.AddSystemComment(ctx, id, commentBody)
 }
 
 func (r *TicketRepo) GetAttachmentByID(ctx context.Context, attachmentID string) (models.Attachment, error) {
-	var attachment models.Attachment
-	row := r.pool.QueryRow(ctx, `SELECT id, ticket_id, filename, mime, size, path, created_at FROM attachments WHERE id=$1`, attachmentID)
-	err := row.Scan(&attachment.ID, &attachment.TicketID, &attachment.Filename, &attachment.MIME, &attachment.Size, &attachment.Path, &attachment.CreatedAt)
+	a, err := r.q.GetAttachment(ctx, attachmentID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return attachment, ErrNotFound
+			return models.Attachment{}, ErrNotFound
 		}
-		return attachment, err
+		return models.Attachment{}, err
 	}
-	return attachment, nil
+	return models.Attachment{ID: a.ID, TicketID: a.TicketID, Filename: a.Filename, MIME: a.Mime, Size: a.Size, Path: a.Path, CreatedAt: a.CreatedAt}, nil
 }
 
 func (r *TicketRepo) GetCommentAttachmentByID(ctx context.Context, attachmentID string) (models.CommentAttachment, error) {
-	var attachment models.CommentAttachment
-	row := r.pool.QueryRow(ctx, `SELECT id, comment_id, filename, mime, size, path, created_at FROM comment_attachments WHERE id=$1`, attachmentID)
-	err := row.Scan(&attachment.ID, &attachment.CommentID, &attachment.Filename, &attachment.MIME, &attachment.Size, &attachment.Path, &attachment.CreatedAt)
+	a, err := r.q.GetCommentAttachment(ctx, attachmentID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return attachment, ErrNotFound
+			return models.CommentAttachment{}, ErrNotFound
 		}
-		return attachment, err
+		return models.CommentAttachment{}, err
 	}
-	return attachment, nil
+	return commentAttachmentFromRow(a), nil
 }
 
-func (r *TicketRepo) Classify(ctx context.Context, id string, resolved models.TicketResolvedType) error {
-	// only classify Issue Report
-	row := r.pool.QueryRow(ctx, `SELECT initial_type FROM tickets WHERE id=$1`, id)
-	var initial string
-	if err := row.Scan(&initial); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return ErrNotFound }
+// issueReport returns ErrNotFound for a missing ticket and an error naming
+// action for any ticket that is not an Issue Report
+func (r *TicketRepo) issueReport(ctx context.Context, id, action string) error {
+	initial, err := r.q.GetTicketInitialType(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
 		return err
 	}
-	if initial != string(models.InitialIssueReport) {
-		return errors.New("only ISSUE_REPORT can be classified")
+	if initial != models.InitialIssueReport {
+		return fmt.Errorf("only ISSUE_REPORT can be %s", action)
 	}
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET resolved_type=$1, updated_at=NOW() WHERE id=$2`, resolved, id)
-	return err
+	return nil
 }
 
-func (r *TicketRepo) RejectIssueReport(ctx context.Context, id string) error {
-	// only reject Issue Report
-	row := r.pool.QueryRow(ctx, `SELECT initial_type FROM tickets WHERE id=$1`, id)
-	var initial string
-	if err := row.Scan(&initial); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return ErrNotFound }
+func (r *TicketRepo) Classify(ctx context.Context, id string, resolved models.TicketResolvedType) error {
+	if err := r.issueReport(ctx, id, "classified"); err != nil {
 		return err
 	}
-	if initial != string(models.InitialIssueReport) {
-		return errors.New("only ISSUE_REPORT can be rejected")
+	return r.q.ClassifyTicket(ctx, sqlc.ClassifyTicketParams{ResolvedType: resolved, ID: id})
+}
+
+// RejectIssueReport cancels an Issue Report whose status is still from. It
+// returns ErrNotFound if the ticket does not exist and ErrStatusChanged if its
+// status moved on since the workflow allowed the cancel.
+func (r *TicketRepo) RejectIssueReport(ctx context.Context, id string, from models.TicketStatus) error {
+	if err := r.issueReport(ctx, id, "rejected"); err != nil {
+		return err
 	}
-	now := time.Now()
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET status=$1, closed_at=$2, updated_at=NOW() WHERE id=$3`, models.StatusCanceled, &now, id)
-	return err
+	return r.ChangeStatus(ctx, id, from, models.StatusCanceled)
 }
 
 // Multi-assignee methods
@@ -525,101 +758,111 @@ func (r *TicketRepo) AssignUsers(ctx context.Context, ticketID string, assigneeI
 	if len(assigneeIDs) == 0 {
 		return nil
 	}
-	
-	// Insert new assignments
-	for _, assigneeID := range assigneeIDs {
-		_, err := r.pool.Exec(ctx, `
-			INSERT INTO ticket_assignments (ticket_id, assignee_id, assigned_by) 
-			VALUES ($1, $2, $3)
-			ON CONFLICT (ticket_id, assignee_id) DO NOTHING`, 
-			ticketID, assigneeID, assignedBy)
-		if err != nil {
+	if err := r.q.AssignUsers(ctx, sqlc.AssignUsersParams{TicketID: ticketID, AssigneeIds: assigneeIDs, AssignedBy: assignedBy}); err != nil {
+		return err
+	}
+	for _, id := range assigneeIDs {
+		if err := r.q.WatchTicket(ctx, sqlc.WatchTicketParams{TicketID: ticketID, UserID: id}); err != nil {
 			return err
 		}
 	}
-	
-	// Update ticket timestamp
-	_, err := r.pool.Exec(ctx, `UPDATE tickets SET updated_at=NOW() WHERE id=$1`, ticketID)
-	return err
+	return r.q.MarkTicketAssigned(ctx, ticketID)
 }
 
 func (r *TicketRepo) UnassignUsers(ctx context.Context, ticketID string, assigneeIDs []string) error {
 	if len(assigneeIDs) == 0 {
 		return nil
 	}
-	
-	// Build placeholders for IN clause
-	placeholders := make([]string, len(assigneeIDs))
-	args := []any{ticketID}
-	for i, assigneeID := range assigneeIDs {
-		placeholders[i] = fmt.Sprintf("$%d", i+2)
-		args = append(args, assigneeID)
-	}
-	
-	sql := fmt.Sprintf(`DELETE FROM ticket_assignments WHERE ticket_id=$1 AND assignee_id IN (%s)`, 
-		strings.Join(placeholders, ","))
-	
-	_, err := r.pool.Exec(ctx, sql, args...)
-	if err != nil {
+	if err := r.q.UnassignUsers(ctx, sqlc.UnassignUsersParams{TicketID: ticketID, AssigneeIds: assigneeIDs}); err != nil {
 		return err
 	}
-	
-	// Update ticket timestamp
-	_, err = r.pool.Exec(ctx, `UPDATE tickets SET updated_at=NOW() WHERE id=$1`, ticketID)
-	return err
+	return r.q.TouchTicket(ctx, ticketID)
 }
 
 func (r *TicketRepo) GetAssignees(ctx context.Context, ticketID string) ([]models.User, error) {
-	// First try the new multiple assignees system
-	rows, err := r.pool.Query(ctx, `
-		SELECT u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at
-		FROM ticket_assignments ta
-		JOIN users u ON ta.assignee_id = u.id
-		WHERE ta.ticket_id=$1 
-		ORDER BY ta.assigned_at ASC`, ticketID)
-	
+	assignees, err := assigneesByTicket(ctx, r.q, []string{ticketID})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	
-	var assignees []models.User
-	for rows.Next() {
-		var u models.User
-		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.CreatedAt, &u.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		assignees = append(assignees, u)
-	}
-	
-	// If no assignees found in the new system, try the legacy single assignee system
-	if len(assignees) == 0 {
-		var legacyAssignee models.User
-		legacyErr := r.pool.QueryRow(ctx, `
-			SELECT u.id, u.name, u.email, u.role, u.profile_picture, u.created_at, u.updated_at
-			FROM tickets t
-			JOIN users u ON t.assignee_id = u.id
-			WHERE t.id = $1 AND t.assignee_id IS NOT NULL`, ticketID).Scan(
-			&legacyAssignee.ID, &legacyAssignee.Name, &legacyAssignee.Email, 
-			&legacyAssignee.Role, &legacyAssignee.ProfilePicture, 
-			&legacyAssignee.CreatedAt, &legacyAssignee.UpdatedAt)
-		
-		if legacyErr == nil {
-			assignees = append(assignees, legacyAssignee)
-		}
+	return assignees[ticketID], nil
+}
+
+func (r *TicketRepo) IsUserAssignedToTicket(ctx context.Context, ticketID, userID string) (bool, error) {
+	return r.q.IsUserAssigned(ctx, sqlc.IsUserAssignedParams{TicketID: ticketID, AssigneeID: userID})
+}
+
+func ticketFromRow(row sqlc.Ticket, latestComment *string) models.Ticket {
+	t := models.Ticket{
+		ID:               row.ID,
+		Code:             row.Code,
+		CreatedBy:        row.CreatedBy,
+		InitialType:      row.InitialType,
+		ResolvedType:     row.ResolvedType,
+		Status:           row.Status,
+		Title:            row.Title,
+		Description:      row.Description,
+		ImpactScore:      int32(row.ImpactScore),
+		UrgencyScore:     int32(row.UrgencyScore),
+		FinalScore:       int32(row.FinalScore),
+		RedFlag:          row.RedFlag,
+		Priority:         row.Priority,
+		AssigneeID:       row.AssigneeID,
+		LatestComment:    latestComment,
+		EffortScore:      int32(row.EffortScore),
+		ResponseDueAt:    row.ResponseDueAt,
+		ResolutionDueAt:  row.ResolutionDueAt,
+		FirstRespondedAt: row.FirstRespondedAt,
+		PausedAt:         row.PausedAt,
+		ReopenCount:      row.ReopenCount,
+		LastReopenedAt:   row.LastReopenedAt,
+		SLAPaused:        time.Duration(row.SlaPausedSeconds) * time.Second,
+		CreatedAt:        row.CreatedAt,
+		UpdatedAt:        row.UpdatedAt,
+		ClosedAt:         row.ClosedAt,
+	}
+	json.Unmarshal(row.Details, &t.Details)
+	json.Unmarshal(row.RedFlagsData, &t.RedFlagsData)
+	json.Unmarshal(row.ImpactAssessmentData, &t.ImpactAssessmentData)
+	json.Unmarshal(row.UrgencyTimelineData, &t.UrgencyTimelineData)
+	json.Unmarshal(row.EffortData, &t.EffortData)
+	return t
+}
+
+// assigneesByTicket loads the assignees of a page of tickets with a single
+// query, keyed by ticket id. Tickets that only have the legacy assignee_id
+// get that user; tickets with no assignee are absent from the map.
+func assigneesByTicket(ctx context.Context, q *sqlc.Queries, ticketIDs []string) (map[string][]models.User, error) {
+	assignees := map[string][]models.User{}
+	if len(ticketIDs) == 0 {
+		return assignees, nil
+	}
+	rows, err := q.ListAssigneesByTicket(ctx, ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		assignees[row.TicketID] = append(assignees[row.TicketID], assigneeFromRow(row))
 	}
-	
 	return assignees, nil
 }
 
-func (r *TicketRepo) IsUserAssignedToTicket(ctx context.Context, ticketID, userID string) (bool, error) {
-	var exists bool
-	err := r.pool.QueryRow(ctx, `
-		SELECT EXISTS(
-			SELECT 1 FROM ticket_assignments 
-			WHERE ticket_id = $1 AND assignee_id = $2
-		)
-	`, ticketID, userID).Scan(&exists)
-	return exists, err
-}
\ No newline at end of file
+func assigneeFromRow(row sqlc.ListAssigneesByTicketRow) models.User {
+	return models.User{ID: row.ID, Name: row.Name, Email: row.Email, Role: row.Role, ProfilePicture: row.ProfilePicture, CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt}
+}
+
+func commentFromRow(row sqlc.ListTicketCommentsRow) models.Comment {
+	return models.Comment{
+		ID:                row.ID,
+		TicketID:          row.TicketID,
+		AuthorID:          row.AuthorID,
+		AuthorName:        row.AuthorName,
+		AuthorRole:        (*string)(row.AuthorRole),
+		Body:              row.Body,
+		IsSystemGenerated: row.IsSystemGenerated != nil && *row.IsSystemGenerated,
+		CreatedAt:         row.CreatedAt,
+	}
+}
+
+func commentAttachmentFromRow(a sqlc.CommentAttachment) models.CommentAttachment {
+	return models.CommentAttachment{ID: a.ID, CommentID: a.CommentID, Filename: a.Filename, MIME: a.Mime, Size: a.Size, Path: a.Path, CreatedAt: a.CreatedAt}
+}
@@ -1,11 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/it-tms/apps/api/internal/models"
 )
@@ -216,3 +221,170 @@ func TestChangeDetection_Logic(t *testing.T) {
 		})
 	}
 }
+
+func TestTicketFilters_Params(t *testing.T) {
+	// Empty filters become NULL parameters and empty lists, which disable
+	// their condition; lists must not be nil or they would be NULL arrays
+	p := TicketFilters{}.params()
+	assert.Equal(t, []string{}, p.Statuses)
+	assert.Equal(t, []string{}, p.Priorities)
+	assert.Equal(t, []string{}, p.InitialTypes)
+	assert.Equal(t, []string{}, p.ResolvedTypes)
+	assert.Nil(t, p.AssigneeID)
+	assert.False(t, p.Unassigned)
+	assert.Nil(t, p.CreatedBy)
+	assert.Nil(t, p.RedFlag)
+	assert.Nil(t, p.CreatedFrom)
+	assert.Nil(t, p.ClosedTo)
+	assert.Nil(t, p.EffortMin)
+	assert.Nil(t, p.EffortMax)
+	assert.Nil(t, p.Query)
+	assert.Nil(t, p.WatcherID)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	redFlag, effortMin, effortMax := true, 2, 6
+	p = TicketFilters{
+		Statuses:      []models.TicketStatus{models.StatusInProgress, models.StatusOnHold},
+		Priorities:    []models.TicketPriority{models.PriorityP1},
+		InitialTypes:  []models.TicketInitialType{models.InitialIssueReport},
+		ResolvedTypes: []models.TicketResolvedType{models.ResolvedDataCorrection},
+		AssigneeID:    "u1",
+		Unassigned:    true,
+		RedFlag:       &redFlag,
+		CreatedFrom:   &from,
+		EffortMin:     &effortMin,
+		EffortMax:     &effortMax,
+		Query:         "printer",
+	}.params()
+	assert.Equal(t, []string{"in_progress", "on_hold"}, p.Statuses)
+	assert.Equal(t, []string{"P1"}, p.Priorities)
+	assert.Equal(t, []string{"ISSUE_REPORT"}, p.InitialTypes)
+	assert.Equal(t, []string{"DATA_CORRECTION"}, p.ResolvedTypes)
+	assert.Equal(t, "u1", *p.AssigneeID)
+	assert.True(t, p.Unassigned)
+	assert.Nil(t, p.CreatedBy)
+	assert.True(t, *p.RedFlag)
+	assert.Equal(t, from, *p.CreatedFrom)
+	assert.Nil(t, p.CreatedTo)
+	assert.Equal(t, int16(2), *p.EffortMin)
+	assert.Equal(t, int16(6), *p.EffortMax)
+	// The search text becomes a tsquery over the search index
+	assert.Equal(t, "'printer'", *p.Query)
+
+	// Nothing searchable is no filter at all
+	assert.Nil(t, TicketFilters{Query: " ?! "}.params().Query)
+
+	// The watcher only applies when watching was asked for
+	assert.Equal(t, "u1", *TicketFilters{Watching: true, Watcher: "u1"}.params().WatcherID)
+	assert.Nil(t, TicketFilters{Watcher: "u1"}.params().WatcherID)
+}
+
+func TestTicketFilters_SortKey(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{sort: "", want: ""},
+		{sort: "priority", want: ""},
+		{sort: "-priority", want: "-priority_rank"},
+		{sort: "createdAt", want: "created_at"},
+		{sort: "-updatedAt", want: "-updated_at"},
+		{sort: "dueAt", want: "resolution_due_at"},
+		{sort: "-effortScore", want: "-effort_score"},
+		{sort: "title", wantErr: true},
+		{sort: "created_at", wantErr: true},
+		{sort: "--code", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := TicketFilters{Sort: tt.sort}.sortKey()
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidSort, tt.sort)
+			continue
+		}
+		assert.NoError(t, err, tt.sort)
+		assert.Equal(t, tt.want, got, tt.sort)
+	}
+}
+
+func TestTicketRepo_ListSortAndFilters(t *testing.T) {
+	ctx := context.Background()
+	db := newSeededDB(5)
+	repo := newRepo(db).Tickets
+
+	f := TicketFilters{Statuses: []models.TicketStatus{models.StatusPending}, Unassigned: true, Sort: "-createdAt"}
+	_, total, err := repo.List(ctx, f, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	// ListTickets takes the filters, then sort, offset and limit
+	args := db.args["ListTickets"]
+	assert.Equal(t, []any{"-created_at", int32(0), int32(10)}, args[len(args)-3:])
+	assert.Equal(t, []string{"pending"}, args[0])
+	assert.Equal(t, true, args[5])
+	// The count uses the same filters
+	assert.Equal(t, args[:len(args)-3], db.args["CountTickets"])
+
+	db = newSeededDB(5)
+	_, _, err = newRepo(db).Tickets.List(ctx, TicketFilters{Sort: "title"}, 0, 10)
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	assert.Zero(t, db.queries)
+
+	// Keyset pages only follow the default order
+	_, _, err = newRepo(db).Tickets.ListByCursor(ctx, TicketFilters{Sort: "createdAt"}, nil, 10)
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// TestTicketQueries_SameFilters checks that the list, keyset page and count
+// queries filter tickets with the same WHERE clause, which sqlc makes each
+// of them spell out
+func TestTicketQueries_SameFilters(t *testing.T) {
+	raw, err := os.ReadFile("../../db/queries/tickets.sql")
+	require.NoError(t, err)
+	const (
+		start = "WHERE (COALESCE(cardinality(@statuses::text[])"
+		end   = "w.user_id = sqlc.narg('watcher_id')))"
+	)
+	filters := func(name string) string {
+		_, query, ok := strings.Cut(string(raw), "-- name: "+name+" ")
+		require.True(t, ok, name)
+		query, _, _ = strings.Cut(query, "-- name: ")
+		i, j := strings.Index(query, start), strings.Index(query, end)
+		require.True(t, i >= 0 && j > i, "%s has no ticket filters", name)
+		return query[i : j+len(end)]
+	}
+	want := filters("ListTickets")
+	for _, name := range []string{"ListTicketsAfter", "ListTicketsBefore", "CountTickets"} {
+		assert.Equal(t, want, filters(name), name)
+	}
+}
+
+// statusDB answers ChangeStatus for a ticket whose status is currently status
+type statusDB struct {
+	DBTX
+	status models.TicketStatus
+}
+
+func (d *statusDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !strings.Contains(sql, "name: ChangeStatus") {
+		return pgconn.CommandTag{}, fmt.Errorf("unexpected statement %q", sql)
+	}
+	if args[3] != d.status {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	}
+	d.status = args[0].(models.TicketStatus)
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func TestTicketRepo_ChangeStatusGuardsFromStatus(t *testing.T) {
+	ctx := context.Background()
+	db := &statusDB{status: models.StatusPending}
+	r := newRepo(db)
+
+	require.NoError(t, r.Tickets.ChangeStatus(ctx, "ticket", models.StatusPending, models.StatusInProgress))
+	assert.Equal(t, models.StatusInProgress, db.status)
+
+	// A second request that also read pending loses the race and writes nothing
+	err := r.Tickets.ChangeStatus(ctx, "ticket", models.StatusPending, models.StatusCanceled)
+	assert.ErrorIs(t, err, ErrStatusChanged)
+	assert.Equal(t, models.StatusInProgress, db.status)
+}
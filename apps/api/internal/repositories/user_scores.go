@@ -2,38 +2,27 @@ package repositories
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
 )
 
-type UserScoresRepo struct{ pool *pgxpool.Pool }
+type UserScoresRepo struct{ q *sqlc.Queries }
 
 // AwardPoints awards points to a user for completing a ticket
 func (r *UserScoresRepo) AwardPoints(ctx context.Context, userID, ticketID string, points float64) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO user_scores (user_id, ticket_id, points) 
-		VALUES ($1, $2, $3)
-		ON CONFLICT (user_id, ticket_id) 
-		DO UPDATE SET points = $3, awarded_at = NOW()`,
-		userID, ticketID, points)
-	return err
+	return r.q.AwardPoints(ctx, sqlc.AwardPointsParams{UserID: userID, TicketID: ticketID, Points: points})
 }
 
 // RemovePoints removes points for a user from a specific ticket (when ticket is reopened or assignees change)
 func (r *UserScoresRepo) RemovePoints(ctx context.Context, userID, ticketID string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM user_scores WHERE user_id = $1 AND ticket_id = $2`, userID, ticketID)
-	return err
+	return r.q.RemovePoints(ctx, sqlc.RemovePointsParams{UserID: userID, TicketID: ticketID})
 }
 
 // RemoveAllPointsForTicket removes all points awarded for a specific ticket
 func (r *UserScoresRepo) RemoveAllPointsForTicket(ctx context.Context, ticketID string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM user_scores WHERE ticket_id = $1`, ticketID)
-	return err
+	return r.q.RemoveTicketPoints(ctx, ticketID)
 }
 
 // GetUserRankings returns top N users by total points
@@ -41,134 +30,50 @@ func (r *UserScoresRepo) GetUserRankings(ctx context.Context, limit int) ([]mode
 	return r.GetUserRankingsWithDateFilter(ctx, limit, nil, nil)
 }
 
-// GetUserRankingsWithDateFilter returns top N users by total points, optionally filtered by ticket creation date
+// GetUserRankingsWithDateFilter returns top N users by total points, optionally filtered by ticket creation date.
+// The month only applies together with a year.
 func (r *UserScoresRepo) GetUserRankingsWithDateFilter(ctx context.Context, limit int, month *int, year *int) ([]models.UserRanking, error) {
-	// Build the query with optional date filtering
-	var query string
-	var args []interface{}
-	
-	if year != nil {
-		// Filter by ticket creation date
-		if month != nil {
-			// Both month and year filtering
-			query = `
-				SELECT 
-					u.id,
-					u.name,
-					u.email,
-					u.role,
-					u.profile_picture,
-					COALESCE(SUM(us.points), 0) as total_points,
-					COUNT(us.ticket_id) as tickets_completed,
-					ROW_NUMBER() OVER (ORDER BY COALESCE(SUM(us.points), 0) DESC, u.name ASC) as rank
-				FROM users u
-				LEFT JOIN user_scores us ON u.id = us.user_id
-				LEFT JOIN tickets t ON us.ticket_id = t.id
-				WHERE t.id IS NULL OR (EXTRACT(MONTH FROM t.created_at) = $1 AND EXTRACT(YEAR FROM t.created_at) = $2)
-				GROUP BY u.id, u.name, u.email, u.role
-				ORDER BY total_points DESC, u.name ASC
-				LIMIT $3`
-			args = []interface{}{*month, *year, limit}
-		} else {
-			// Year-only filtering
-			query = `
-				SELECT 
-					u.id,
-					u.name,
-					u.email,
-					u.role,
-					u.profile_picture,
-					COALESCE(SUM(us.points), 0) as total_points,
-					COUNT(us.ticket_id) as tickets_completed,
-					ROW_NUMBER() OVER (ORDER BY COALESCE(SUM(us.points), 0) DESC, u.name ASC) as rank
-				FROM users u
-				LEFT JOIN user_scores us ON u.id = us.user_id
-				LEFT JOIN tickets t ON us.ticket_id = t.id
-				WHERE t.id IS NULL OR EXTRACT(YEAR FROM t.created_at) = $1
-				GROUP BY u.id, u.name, u.email, u.role
-				ORDER BY total_points DESC, u.name ASC
-				LIMIT $2`
-			args = []interface{}{*year, limit}
-		}
-	} else {
-		// Use the existing view for all-time rankings
-		query = `
-			SELECT ur.id, ur.name, ur.email, ur.role, u.profile_picture, ur.total_points, ur.tickets_completed, ur.rank
-			FROM user_rankings ur
-			LEFT JOIN users u ON ur.id = u.id
-			ORDER BY ur.total_points DESC, ur.name ASC
-			LIMIT $1`
-		args = []interface{}{limit}
+	if year == nil {
+		month = nil
 	}
-	
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.q.ListUserRankings(ctx, sqlc.ListUserRankingsParams{Year: optInt32(year), Month: optInt32(month), MaxResults: int32(limit)})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	var rankings []models.UserRanking
-	for rows.Next() {
-		var r models.UserRanking
-		var profilePicture *string
-		var totalPoints sql.NullFloat64
-		var ticketsCompleted sql.NullInt32
-		var rank sql.NullInt32
-
-		if err := rows.Scan(&r.ID, &r.Name, &r.Email, &r.Role, &profilePicture, &totalPoints, &ticketsCompleted, &rank); err != nil {
-			return nil, err
-		}
-
-		r.ProfilePicture = profilePicture
-		r.TotalPoints = totalPoints.Float64
-		r.TicketsCompleted = int(ticketsCompleted.Int32)
-		r.Rank = int(rank.Int32)
-		rankings = append(rankings, r)
+	for _, row := range rows {
+		rankings = append(rankings, models.UserRanking{
+			ID:               row.ID,
+			Name:             row.Name,
+			Email:            row.Email,
+			Role:             string(row.Role),
+			ProfilePicture:   row.ProfilePicture,
+			TotalPoints:      row.TotalPoints,
+			TicketsCompleted: int(row.TicketsCompleted),
+			Rank:             int(row.Rank),
+		})
 	}
-
-	return rankings, rows.Err()
+	return rankings, nil
 }
 
 // GetUserTotalPoints gets total points for a specific user
 func (r *UserScoresRepo) GetUserTotalPoints(ctx context.Context, userID string) (float64, error) {
-	var totalPoints sql.NullFloat64
-	err := r.pool.QueryRow(ctx, `
-		SELECT COALESCE(SUM(points), 0) as total_points
-		FROM user_scores 
-		WHERE user_id = $1`, userID).Scan(&totalPoints)
-	
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, nil
-		}
-		return 0, err
-	}
-	
-	return totalPoints.Float64, nil
+	return r.q.GetUserTotalPoints(ctx, userID)
 }
 
 // GetTicketPointsDistribution gets current points distribution for a ticket
 func (r *UserScoresRepo) GetTicketPointsDistribution(ctx context.Context, ticketID string) ([]models.UserScore, error) {
-	rows, err := r.pool.Query(ctx, `
-		SELECT id, user_id, ticket_id, points, awarded_at
-		FROM user_scores 
-		WHERE ticket_id = $1
-		ORDER BY awarded_at DESC`, ticketID)
+	rows, err := r.q.ListTicketPoints(ctx, ticketID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	var scores []models.UserScore
-	for rows.Next() {
-		var s models.UserScore
-		if err := rows.Scan(&s.ID, &s.UserID, &s.TicketID, &s.Points, &s.AwardedAt); err != nil {
-			return nil, err
-		}
-		scores = append(scores, s)
+	for _, s := range rows {
+		scores = append(scores, models.UserScore{ID: s.ID, UserID: s.UserID, TicketID: s.TicketID, Points: s.Points, AwardedAt: s.AwardedAt})
 	}
-
-	return scores, rows.Err()
+	return scores, nil
 }
 
 // DistributePoints distributes points evenly among multiple assignees for a completed ticket
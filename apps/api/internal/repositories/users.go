@@ -3,109 +3,215 @@ package repositories
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strings"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/it-tms/apps/api/internal/models"
+	"github.com/it-tms/apps/api/internal/sqlc"
 )
 
 var ErrNotFound = errors.New("not found")
 
-type UserRepo struct{ pool *pgxpool.Pool }
+type UserRepo struct{ q *sqlc.Queries }
 
 func (r *UserRepo) GetByEmail(ctx context.Context, email string) (models.User, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, name, email, role, profile_picture, password_hash, created_at, updated_at FROM users WHERE email=$1`, email)
-	var u models.User
-	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return u, ErrNotFound }
-		return u, err
+	u, err := r.q.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, ErrNotFound
+		}
+		return models.User{}, err
 	}
-	return u, nil
+	return userFromRow(u), nil
 }
 
 func (r *UserRepo) GetByID(ctx context.Context, id string) (models.User, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, name, email, role, profile_picture, password_hash, created_at, updated_at FROM users WHERE id=$1`, id)
-	var u models.User
-	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) { return u, ErrNotFound }
-		return u, err
+	if !isUUID(id) {
+		return models.User{}, ErrNotFound
+	}
+	u, err := r.q.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, ErrNotFound
+		}
+		return models.User{}, err
+	}
+	return userFromRow(u), nil
+}
+
+// GetByOIDCSubject finds the user linked to a single sign-on subject
+func (r *UserRepo) GetByOIDCSubject(ctx context.Context, subject string) (models.User, error) {
+	u, err := r.q.GetUserByOIDCSubject(ctx, &subject)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, ErrNotFound
+		}
+		return models.User{}, err
 	}
-	return u, nil
+	return userFromRow(u), nil
+}
+
+// CreateOIDC provisions a user linked to a single sign-on subject, without
+// a password
+func (r *UserRepo) CreateOIDC(ctx context.Context, u models.User, subject string) (models.User, error) {
+	row, err := r.q.CreateOIDCUser(ctx, sqlc.CreateOIDCUserParams{Name: u.Name, Email: u.Email, Role: u.Role, OidcSubject: &subject})
+	if err != nil {
+		return models.User{}, err
+	}
+	return userFromRow(row), nil
+}
+
+// LinkOIDC links an existing account to a single sign-on subject. The
+// provider takes over its password: the local one and any reset links stop
+// working. It reports false when the account was already linked.
+func (r *UserRepo) LinkOIDC(ctx context.Context, id, subject string) (bool, error) {
+	n, err := r.q.LinkUserOIDCSubject(ctx, sqlc.LinkUserOIDCSubjectParams{OidcSubject: &subject, ID: id})
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, r.q.ClearPasswordResets(ctx, id)
 }
 
 func (r *UserRepo) Create(ctx context.Context, u models.User) error {
-	_, err := r.pool.Exec(ctx, `INSERT INTO users (name, email, role, password_hash) VALUES ($1,$2,$3,$4)`, u.Name, u.Email, u.Role, u.PasswordHash)
-	return err
+	return r.q.CreateUser(ctx, sqlc.CreateUserParams{Name: u.Name, Email: u.Email, Role: u.Role, PasswordHash: u.PasswordHash})
 }
 
 func (r *UserRepo) UpdateProfile(ctx context.Context, id, name, email string) (models.User, error) {
-	_, err := r.pool.Exec(ctx, `UPDATE users SET name=$1, email=$2, updated_at=NOW() WHERE id=$3`, name, email, id)
-	if err != nil {
+	if err := r.q.UpdateUserProfile(ctx, sqlc.UpdateUserProfileParams{Name: name, Email: email, ID: id}); err != nil {
 		return models.User{}, err
 	}
 	return r.GetByID(ctx, id)
 }
 
 func (r *UserRepo) UpdateProfilePicture(ctx context.Context, id, profilePicture string) (models.User, error) {
-	_, err := r.pool.Exec(ctx, `UPDATE users SET profile_picture=$1, updated_at=NOW() WHERE id=$2`, profilePicture, id)
-	if err != nil {
+	if err := r.q.UpdateUserProfilePicture(ctx, sqlc.UpdateUserProfilePictureParams{ProfilePicture: &profilePicture, ID: id}); err != nil {
 		return models.User{}, err
 	}
 	return r.GetByID(ctx, id)
 }
 
+// UpdateRole changes a user's role and revokes their sessions, so tokens
+// carrying the old role stop working at once
+func (r *UserRepo) UpdateRole(ctx context.Context, id string, role models.Role) error {
+	n, err := r.q.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{Role: role, ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	_, err = r.q.RevokeUserSessions(ctx, sqlc.RevokeUserSessionsParams{UserID: id})
+	return err
+}
+
+// SetPassword replaces a user's password hash and signs them out of every
+// session but keep, when given. Outstanding reset tokens stop working.
+func (r *UserRepo) SetPassword(ctx context.Context, id, hash string, keep *string) error {
+	n, err := r.q.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{PasswordHash: hash, ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	if err := r.q.ClearPasswordResets(ctx, id); err != nil {
+		return err
+	}
+	_, err = r.q.RevokeUserSessions(ctx, sqlc.RevokeUserSessionsParams{UserID: id, ExceptID: keep})
+	return err
+}
+
+// SetDeactivated switches an account off, revoking its sessions, or back
+// on. It reports whether anything changed: false when the user was already
+// in that state.
+func (r *UserRepo) SetDeactivated(ctx context.Context, id string, deactivated bool) (bool, error) {
+	n, err := r.q.SetUserDeactivated(ctx, sqlc.SetUserDeactivatedParams{Deactivated: deactivated, ID: id})
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if deactivated {
+		if _, err := r.q.RevokeUserSessions(ctx, sqlc.RevokeUserSessionsParams{UserID: id}); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// UserFilters narrows the admin user list; zero values match everyone
+type UserFilters struct {
+	Query  string
+	Role   models.Role
+	Active *bool
+}
+
+// List returns a page of users by name, with how many match in all
+func (r *UserRepo) List(ctx context.Context, f UserFilters, offset, limit int) ([]models.User, int64, error) {
+	var pattern *string
+	if f.Query != "" {
+		p := "%" + f.Query + "%"
+		pattern = &p
+	}
+	var role *models.Role
+	if f.Role != "" {
+		role = &f.Role
+	}
+	rows, err := r.q.ListUsers(ctx, sqlc.ListUsersParams{Pattern: pattern, Role: role, Active: f.Active, MaxResults: int32(limit), Skip: int32(offset)})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.q.CountUsers(ctx, sqlc.CountUsersParams{Pattern: pattern, Role: role, Active: f.Active})
+	if err != nil {
+		return nil, 0, err
+	}
+	users := []models.User{}
+	for _, u := range rows {
+		users = append(users, models.User{
+			ID:             u.ID,
+			Name:           u.Name,
+			Email:          u.Email,
+			Role:           u.Role,
+			ProfilePicture: u.ProfilePicture,
+			CreatedAt:      u.CreatedAt,
+			UpdatedAt:      u.UpdatedAt,
+			DeactivatedAt:  u.DeactivatedAt,
+		})
+	}
+	return users, total, nil
+}
+
+// Search matches query against name and email, optionally limited to roles
 func (r *UserRepo) Search(ctx context.Context, query string, roles []string, limit int) ([]models.User, error) {
-	args := []any{}
-	arg := 1
-	
-	whereClause := "1=1"
-	
-	// Add search query if provided
+	var pattern *string
 	if query != "" {
-		whereClause += fmt.Sprintf(" AND (name ILIKE $%d OR email ILIKE $%d)", arg, arg)
-		args = append(args, "%"+query+"%")
-		arg++
-	}
-	
-	// Add role filter if provided
-	if len(roles) > 0 {
-		placeholders := make([]string, len(roles))
-		for i, role := range roles {
-			placeholders[i] = fmt.Sprintf("$%d", arg)
-			args = append(args, role)
-			arg++
-		}
-		whereClause += fmt.Sprintf(" AND role IN (%s)", strings.Join(placeholders, ","))
-	}
-	
-	// Add limit
-	args = append(args, limit)
-	
-	sql := fmt.Sprintf(`
-		SELECT id, name, email, role, profile_picture, created_at, updated_at
-		FROM users 
-		WHERE %s 
-		ORDER BY name ASC 
-		LIMIT $%d`, whereClause, arg)
-	
-	rows, err := r.pool.Query(ctx, sql, args...)
+		p := "%" + query + "%"
+		pattern = &p
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+
+	rows, err := r.q.SearchUsers(ctx, sqlc.SearchUsersParams{Pattern: pattern, Roles: roles, MaxResults: int32(limit)})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	
 	var users []models.User
-	for rows.Next() {
-		var u models.User
-		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.ProfilePicture, &u.CreatedAt, &u.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, u)
+	for _, u := range rows {
+		users = append(users, models.User{ID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role, ProfilePicture: u.ProfilePicture, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt})
 	}
-	
 	return users, nil
-}
\ No newline at end of file
+}
+
+func userFromRow(u sqlc.User) models.User {
+	return models.User{
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		Role:           u.Role,
+		ProfilePicture: u.ProfilePicture,
+		PasswordHash:   u.PasswordHash,
+		DeactivatedAt:  u.DeactivatedAt,
+		OIDCSubject:    u.OidcSubject,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+	}
+}
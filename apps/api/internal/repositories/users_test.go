@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestUserRepo_ListFilters(t *testing.T) {
+	db := newRecordDB()
+	active := false
+	_, total, err := newRepo(db).Users.List(context.Background(), UserFilters{Query: "som", Role: models.RoleSupervisor, Active: &active}, 40, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+
+	pattern, role := "%som%", models.RoleSupervisor
+	assert.Equal(t, []any{&pattern, &role, &active, int32(20), int32(40)}, db.args["ListUsers"])
+	assert.Equal(t, []any{&pattern, &role, &active}, db.args["CountUsers"])
+
+	// Zero filters match everyone
+	_, _, err = newRepo(db).Users.List(context.Background(), UserFilters{}, 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, []any{(*string)(nil), (*models.Role)(nil), (*bool)(nil), int32(20), int32(0)}, db.args["ListUsers"])
+}
+
+func TestUserRepo_SetDeactivated(t *testing.T) {
+	db := newRecordDB()
+	db.tags["SetUserDeactivated"] = "UPDATE 1"
+	changed, err := newRepo(db).Users.SetDeactivated(context.Background(), "user-1", true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []any{"user-1", (*string)(nil)}, db.args["RevokeUserSessions"])
+
+	// Reactivating leaves sessions alone; the old ones stay revoked
+	db = newRecordDB()
+	db.tags["SetUserDeactivated"] = "UPDATE 1"
+	_, err = newRepo(db).Users.SetDeactivated(context.Background(), "user-1", false)
+	require.NoError(t, err)
+	assert.NotContains(t, db.args, "RevokeUserSessions")
+}
+
+func TestUserRepo_LinkOIDC(t *testing.T) {
+	db := newRecordDB()
+	db.tags["LinkUserOIDCSubject"] = "UPDATE 1"
+	linked, err := newRepo(db).Users.LinkOIDC(context.Background(), "user-1", "idp|42")
+	require.NoError(t, err)
+	assert.True(t, linked)
+	subject := "idp|42"
+	assert.Equal(t, []any{&subject, "user-1"}, db.args["LinkUserOIDCSubject"])
+	assert.Equal(t, []any{"user-1"}, db.args["ClearPasswordResets"])
+}
+
+func TestUserRepo_GetByOIDCSubject(t *testing.T) {
+	db := newRecordDB()
+	_, err := newRepo(db).Users.GetByOIDCSubject(context.Background(), "idp|42")
+	require.NoError(t, err)
+	subject := "idp|42"
+	assert.Equal(t, []any{&subject}, db.args["GetUserByOIDCSubject"])
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+)
+
+// WatcherRepo stores who follows a ticket. TicketRepo adds creators,
+// assignees and commenters itself.
+type WatcherRepo struct{ q *sqlc.Queries }
+
+// Watch follows a ticket; watching it again is a no-op
+func (r *WatcherRepo) Watch(ctx context.Context, ticketID, userID string) error {
+	return r.q.WatchTicket(ctx, sqlc.WatchTicketParams{TicketID: ticketID, UserID: userID})
+}
+
+// Unwatch stops following a ticket; it reports whether the user was
+// watching it
+func (r *WatcherRepo) Unwatch(ctx context.Context, ticketID, userID string) (bool, error) {
+	n, err := r.q.UnwatchTicket(ctx, sqlc.UnwatchTicketParams{TicketID: ticketID, UserID: userID})
+	return n > 0, err
+}
+
+// List returns the IDs of a ticket's watchers, earliest first
+func (r *WatcherRepo) List(ctx context.Context, ticketID string) ([]string, error) {
+	return r.q.ListTicketWatchers(ctx, ticketID)
+}
+
+// IsWatching reports whether a user follows a ticket
+func (r *WatcherRepo) IsWatching(ctx context.Context, ticketID, userID string) (bool, error) {
+	return r.q.IsWatchingTicket(ctx, sqlc.IsWatchingTicketParams{TicketID: ticketID, UserID: userID})
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketRepo_CommentingWatches(t *testing.T) {
+	db := newRecordDB()
+	author := "user-1"
+	_, err := newRepo(db).Tickets.AddCommentWithID(context.Background(), "ticket-0000", &author, "looking into it")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"ticket-0000", "user-1"}, db.args["WatchTicket"])
+
+	// Mail from an address without an account has no one to watch
+	db = newRecordDB()
+	_, err = newRepo(db).Tickets.AddCommentWithID(context.Background(), "ticket-0000", nil, "from email")
+	require.NoError(t, err)
+	assert.NotContains(t, db.args, "WatchTicket")
+}
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/it-tms/apps/api/internal/sqlc"
+	"github.com/it-tms/apps/api/internal/webhooks"
+)
+
+// WebhookRepo stores webhook subscriptions and their delivery log; it
+// implements webhooks.Outbox
+type WebhookRepo struct{ q *sqlc.Queries }
+
+// Webhook is a subscription. Its secret is only shown when it is set.
+type Webhook struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"-"`
+	Active      bool      `json:"active"`
+	CreatedBy   *string   `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for a subscription, with the outcome
+// of its latest attempt
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt"`
+	ResponseStatus *int32          `json:"responseStatus"`
+	ResponseBody   *string         `json:"responseBody"`
+	LastError      *string         `json:"lastError"`
+	DurationMs     *int32          `json:"durationMs"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+	FailedAt       *time.Time      `json:"failedAt"`
+	RedeliveryOf   *string         `json:"redeliveryOf"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]Webhook, error) {
+	rows, err := r.q.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hooks := []Webhook{}
+	for _, row := range rows {
+		hooks = append(hooks, webhookFromRow(row))
+	}
+	return hooks, nil
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id string) (Webhook, error) {
+	if !isUUID(id) {
+		return Webhook{}, ErrNotFound
+	}
+	row, err := r.q.GetWebhook(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Webhook{}, ErrNotFound
+	}
+	if err != nil {
+		return Webhook{}, err
+	}
+	return webhookFromRow(row), nil
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, w *Webhook) error {
+	row, err := r.q.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		Url:         w.URL,
+		Description: w.Description,
+		Events:      eventTypes(w.Events),
+		Secret:      w.Secret,
+		Active:      w.Active,
+		CreatedBy:   w.CreatedBy,
+	})
+	if err != nil {
+		return err
+	}
+	*w = webhookFromRow(row)
+	return nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, w *Webhook) error {
+	row, err := r.q.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		Url:         w.URL,
+		Description: w.Description,
+		Events:      eventTypes(w.Events),
+		Secret:      w.Secret,
+		Active:      w.Active,
+		ID:          w.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*w = webhookFromRow(row)
+	return nil
+}
+
+// Delete removes a subscription and its delivery log
+func (r *WebhookRepo) Delete(ctx context.Context, id string) error {
+	if !isUUID(id) {
+		return ErrNotFound
+	}
+	n, err := r.q.DeleteWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue queues p for every active subscription to its event. Inside
+// WithTx nothing is sent unless the transaction commits.
+func (r *WebhookRepo) Enqueue(ctx context.Context, p webhooks.Payload) error {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = r.q.EnqueueWebhookDeliveries(ctx, sqlc.EnqueueWebhookDeliveriesParams{Event: p.Event, Payload: payload})
+	return err
+}
+
+func (r *WebhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]webhooks.Delivery, error) {
+	rows, err := r.q.ClaimWebhookDeliveries(ctx, sqlc.ClaimWebhookDeliveriesParams{LeaseSeconds: int32(lease.Seconds()), BatchSize: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]webhooks.Delivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = webhooks.Delivery{
+			ID:       row.ID,
+			URL:      row.Url,
+			Secret:   row.Secret,
+			Event:    row.Event,
+			Payload:  row.Payload,
+			Attempts: int(row.Attempts),
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) MarkDelivered(ctx context.Context, id string, a webhooks.Attempt) error {
+	return r.q.MarkWebhookDelivered(ctx, sqlc.MarkWebhookDeliveredParams{
+		ResponseStatus: int32(a.Status),
+		ResponseBody:   a.Response,
+		DurationMs:     int32(a.Duration.Milliseconds()),
+		ID:             id,
+	})
+}
+
+func (r *WebhookRepo) MarkFailed(ctx context.Context, id string, a webhooks.Attempt, retryAt *time.Time) error {
+	params := sqlc.MarkWebhookFailedParams{
+		LastError:  a.Error,
+		DurationMs: int32(a.Duration.Milliseconds()),
+		RetryAt:    retryAt,
+		ID:         id,
+	}
+	// Without a response there is no status or body to record
+	if a.Status != 0 {
+		status := int32(a.Status)
+		params.ResponseStatus, params.ResponseBody = &status, &a.Response
+	}
+	return r.q.MarkWebhookFailed(ctx, params)
+}
+
+// Deliveries returns the latest deliveries of a subscription, newest first
+func (r *WebhookRepo) Deliveries(ctx context.Context, webhookID string, limit int) ([]WebhookDelivery, error) {
+	rows, err := r.q.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{WebhookID: webhookID, MaxResults: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []WebhookDelivery{}
+	for _, row := range rows {
+		deliveries = append(deliveries, deliveryFromRow(row))
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a delivery's payload again as a new delivery, keeping
+// the original in the log
+func (r *WebhookRepo) Redeliver(ctx context.Context, webhookID, deliveryID string) (WebhookDelivery, error) {
+	if !isUUID(webhookID) || !isUUID(deliveryID) {
+		return WebhookDelivery{}, ErrNotFound
+	}
+	row, err := r.q.RedeliverWebhookDelivery(ctx, sqlc.RedeliverWebhookDeliveryParams{ID: deliveryID, WebhookID: webhookID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WebhookDelivery{}, ErrNotFound
+	}
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	return deliveryFromRow(row), nil
+}
+
+func webhookFromRow(row sqlc.Webhook) Webhook {
+	return Webhook{
+		ID:          row.ID,
+		URL:         row.Url,
+		Description: row.Description,
+		Events:      eventTypes(row.Events),
+		Secret:      row.Secret,
+		Active:      row.Active,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func deliveryFromRow(row sqlc.WebhookDelivery) WebhookDelivery {
+	d := WebhookDelivery{
+		ID:             row.ID,
+		WebhookID:      row.WebhookID,
+		Event:          row.Event,
+		Payload:        row.Payload,
+		Status:         DeliveryPending,
+		Attempts:       row.Attempts,
+		ResponseStatus: row.ResponseStatus,
+		ResponseBody:   row.ResponseBody,
+		LastError:      row.LastError,
+		DurationMs:     row.DurationMs,
+		DeliveredAt:    row.DeliveredAt,
+		FailedAt:       row.FailedAt,
+		RedeliveryOf:   row.RedeliveryOf,
+		CreatedAt:      row.CreatedAt,
+	}
+	switch {
+	case row.DeliveredAt != nil:
+		d.Status = DeliveryDelivered
+	case row.FailedAt != nil:
+		d.Status = DeliveryFailed
+	default:
+		next := row.NextAttemptAt
+		d.NextAttemptAt = &next
+	}
+	return d
+}
+
+// eventTypes never returns nil, so an empty filter encodes as []
+func eventTypes(types []string) []string {
+	if types == nil {
+		return []string{}
+	}
+	return types
+}
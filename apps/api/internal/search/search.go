@@ -0,0 +1,234 @@
+// Package search turns ticket and comment text into search tokens and
+// highlights matches for display.
+//
+// Thai is written without spaces between words and Postgres has no Thai text
+// search configuration, so Thai runs are split into character clusters (a
+// consonant with its leading vowel and combining marks) and indexed as
+// overlapping cluster bigrams, the same way CJK text is commonly indexed.
+// A query matches when all of its bigrams appear in the document, which finds
+// Thai words wherever they sit in a sentence. Other scripts are split into
+// lowercased words.
+package search
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document returns the lexemes to index for text: words, and for Thai both
+// single clusters and cluster bigrams so that one-cluster queries match too.
+func Document(text string) []string {
+	var tokens []string
+	for _, run := range runs(text) {
+		if !run.thai {
+			tokens = append(tokens, run.text)
+			continue
+		}
+		clusters := thaiClusters(run.text)
+		tokens = append(tokens, clusters...)
+		tokens = append(tokens, bigrams(clusters)...)
+	}
+	return tokens
+}
+
+// Query returns the tsquery text matching documents that contain every term
+// of q, or "" when q has nothing searchable.
+func Query(q string) string {
+	var lexemes []string
+	for _, run := range runs(q) {
+		if !run.thai {
+			lexemes = append(lexemes, run.text)
+			continue
+		}
+		clusters := thaiClusters(run.text)
+		if len(clusters) == 1 {
+			lexemes = append(lexemes, clusters[0])
+		} else {
+			lexemes = append(lexemes, bigrams(clusters)...)
+		}
+	}
+	quoted := make([]string, len(lexemes))
+	for i, l := range lexemes {
+		quoted[i] = "'" + strings.ReplaceAll(strings.ReplaceAll(l, `\`, `\\`), "'", "''") + "'"
+	}
+	return strings.Join(quoted, " & ")
+}
+
+// Text flattens the string and number values of a JSON object (such as a
+// ticket's details) into searchable text, in key order.
+func Text(v any) string {
+	var parts []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			parts = append(parts, v)
+		case float64, int, int32, int64:
+			parts = append(parts, fmt.Sprint(v))
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(v[k])
+			}
+		}
+	}
+	walk(v)
+	return strings.Join(parts, "\n")
+}
+
+// Snippet returns about width characters of text around the first match of
+// q's terms, HTML-escaped, with matches wrapped in <mark> tags. Without a
+// match it returns the start of text.
+func Snippet(text, q string, width int) string {
+	src := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(src))
+	for i, r := range src {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Matches of each term, merged where they overlap
+	type span struct{ start, end int }
+	var matches []span
+	for _, run := range runs(q) {
+		term := []rune(run.text)
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if string(lower[i:i+len(term)]) == run.text {
+				matches = append(matches, span{i, i + len(term)})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	merged := matches[:0]
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, m.end)
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	start := 0
+	if len(merged) > 0 {
+		start = max(0, merged[0].start-(width-(merged[0].end-merged[0].start))/2)
+	}
+	end := min(len(src), start+width)
+	start = max(0, min(start, end-width))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range merged {
+		s, e := max(m.start, start), min(m.end, end)
+		if s >= e {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(src[pos:s])))
+		b.WriteString("<mark>" + html.EscapeString(string(src[s:e])) + "</mark>")
+		pos = e
+	}
+	b.WriteString(html.EscapeString(string(src[pos:end])))
+	if end < len(src) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// run is a lowercased word, or a maximal stretch of Thai letters
+type run struct {
+	text string
+	thai bool
+}
+
+func runs(text string) []run {
+	var out []run
+	var cur []rune
+	curThai := false
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, run{text: string(cur), thai: curThai})
+		}
+		cur = cur[:0]
+	}
+	for _, r := range text {
+		switch {
+		case r == 0x0E2F || r == 0x0E46:
+			flush()
+		case isThaiLetter(r):
+			if !curThai {
+				flush()
+			}
+			curThai = true
+			cur = append(cur, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if curThai {
+				flush()
+			}
+			curThai = false
+			cur = append(cur, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// isThaiLetter covers Thai consonants, vowels and marks, but not Thai digits
+// (which count as digits) or the punctuation signs ฯ and ๆ.
+func isThaiLetter(r rune) bool {
+	return r >= 0x0E01 && r <= 0x0E4E && r != 0x0E2F && r != 0x0E46
+}
+
+// thaiClusters splits a Thai run into clusters: a leading vowel (เ แ โ ใ ไ)
+// joins the consonant after it, and following vowels, tone marks and other
+// combining signs join the cluster before them.
+func thaiClusters(s string) []string {
+	var clusters []string
+	var cur []rune
+	leading := false
+	for _, r := range s {
+		switch {
+		case r >= 0x0E40 && r <= 0x0E44:
+			if len(cur) > 0 && !leading {
+				clusters = append(clusters, string(cur))
+				cur = cur[:0]
+			}
+			cur = append(cur, r)
+			leading = true
+		case (r >= 0x0E30 && r <= 0x0E3A) || (r >= 0x0E47 && r <= 0x0E4E):
+			cur = append(cur, r)
+		default:
+			if len(cur) > 0 && !leading {
+				clusters = append(clusters, string(cur))
+				cur = cur[:0]
+			}
+			cur = append(cur, r)
+			leading = false
+		}
+	}
+	if len(cur) > 0 {
+		clusters = append(clusters, string(cur))
+	}
+	return clusters
+}
+
+func bigrams(clusters []string) []string {
+	var out []string
+	for i := 0; i+1 < len(clusters); i++ {
+		out = append(out, clusters[i]+clusters[i+1])
+	}
+	return out
+}
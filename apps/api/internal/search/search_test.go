@@ -0,0 +1,88 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThaiClusters(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"เครื่องพิมพ์", []string{"เค", "รื่", "อ", "ง", "พิ", "ม", "พ์"}},
+		{"ไฟดับ", []string{"ไฟ", "ดั", "บ"}},
+		{"แก้ไข", []string{"แก้", "ไข"}},
+		{"ระบบ", []string{"ระ", "บ", "บ"}},
+		{"น้ำ", []string{"น้ำ"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, thaiClusters(tt.in), tt.in)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	assert.Equal(t, []string{"vpn", "ใช้", "ไม่", "ได้", "ใช้ไม่", "ไม่ได้", "error", "404"}, Document("VPN ใช้ไม่ได้ (Error 404)"))
+	assert.Empty(t, Document(" ... ฯ ๆ "))
+}
+
+func TestQuery(t *testing.T) {
+	assert.Equal(t, "'vpn'", Query("VPN"))
+	// A Thai word must have all its bigrams in the document
+	assert.Equal(t, "'printer' & 'ไม่ได้'", Query("printer ไม่ได้"))
+	// A single cluster matches on its own
+	assert.Equal(t, "'ไฟ'", Query("ไฟ"))
+	assert.Equal(t, "", Query("  !? "))
+}
+
+func TestQuery_MatchesDocument(t *testing.T) {
+	doc := map[string]bool{}
+	for _, tok := range Document("วันนี้เครื่องพิมพ์ชั้น 3 เสียอีกแล้ว") {
+		doc[tok] = true
+	}
+	for _, q := range []string{"เครื่องพิมพ์", "เสีย", "ชั้น 3", "พิมพ์"} {
+		for _, run := range runs(q) {
+			clusters := thaiClusters(run.text)
+			terms := bigrams(clusters)
+			if len(clusters) == 1 || !run.thai {
+				terms = []string{run.text}
+			}
+			for _, term := range terms {
+				assert.True(t, doc[term], "%q: %q not indexed", q, term)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	details := map[string]any{
+		"system":  "ERP",
+		"count":   float64(3),
+		"contact": map[string]any{"name": "สมชาย", "phones": []any{"081", "082"}},
+		"urgent":  true,
+	}
+	assert.Equal(t, "สมชาย\n081\n082\n3\nERP", Text(details))
+	assert.Equal(t, "", Text(nil))
+}
+
+func TestSnippet(t *testing.T) {
+	t.Run("highlights every term", func(t *testing.T) {
+		got := Snippet("The VPN client\nfails: vpn <down>", "vpn", 100)
+		assert.Equal(t, "The <mark>VPN</mark> client fails: <mark>vpn</mark> &lt;down&gt;", got)
+	})
+
+	t.Run("Thai term inside a sentence", func(t *testing.T) {
+		got := Snippet("วันนี้เครื่องพิมพ์เสีย", "เครื่องพิมพ์", 100)
+		assert.Equal(t, "วันนี้<mark>เครื่องพิมพ์</mark>เสีย", got)
+	})
+
+	t.Run("window around the first match", func(t *testing.T) {
+		got := Snippet("aaaaaaaaaa bbbbbbbbbb target cccccccccc dddddddddd", "target", 16)
+		assert.Equal(t, "…bbbb <mark>target</mark> cccc…", got)
+	})
+
+	t.Run("no match shows the start", func(t *testing.T) {
+		assert.Equal(t, "hello…", Snippet("hello world", "nothing", 5))
+	})
+}
@@ -0,0 +1,158 @@
+package sla
+
+import (
+	"time"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+// AtRiskFraction is the share of an SLA window that may remain before an open
+// ticket is reported as at risk.
+const AtRiskFraction = 0.25
+
+// DefaultPolicies apply when no policy is configured for a priority.
+var DefaultPolicies = []models.SLAPolicy{
+	{Priority: models.PriorityP0, ResponseMinutes: 30, ResolutionMinutes: 4 * 60},
+	{Priority: models.PriorityP1, ResponseMinutes: 60, ResolutionMinutes: 8 * 60},
+	{Priority: models.PriorityP2, ResponseMinutes: 4 * 60, ResolutionMinutes: 3 * 24 * 60},
+	{Priority: models.PriorityP3, ResponseMinutes: 8 * 60, ResolutionMinutes: 5 * 24 * 60},
+}
+
+// Clock measures SLA time. A business calendar pauses the clock outside
+// working hours; WallClock counts every minute.
+type Clock interface {
+	Add(start time.Time, d time.Duration) time.Time
+	Between(from, to time.Time) time.Duration
+}
+
+// WallClock is a Clock that never pauses.
+type WallClock struct{}
+
+func (WallClock) Add(start time.Time, d time.Duration) time.Time { return start.Add(d) }
+
+func (WallClock) Between(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// Due holds the response and resolution deadlines for a ticket.
+type Due struct {
+	Response   time.Time
+	Resolution time.Time
+}
+
+// Resolve picks the policy for a priority and ticket type. A type-specific
+// policy wins over the priority default; DefaultPolicies is the last resort.
+func Resolve(policies []models.SLAPolicy, priority models.TicketPriority, initialType models.TicketInitialType) (models.SLAPolicy, bool) {
+	var fallback *models.SLAPolicy
+	for i := range policies {
+		p := policies[i]
+		if p.Priority != priority {
+			continue
+		}
+		if p.InitialType != nil && *p.InitialType == initialType {
+			return p, true
+		}
+		if p.InitialType == nil && fallback == nil {
+			fallback = &policies[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	for _, p := range DefaultPolicies {
+		if p.Priority == priority {
+			return p, true
+		}
+	}
+	return models.SLAPolicy{}, false
+}
+
+// DueDates computes the deadlines for a ticket opened at start, counting
+// only the time the clock runs.
+func DueDates(clock Clock, start time.Time, p models.SLAPolicy) Due {
+	return Due{
+		Response:   clock.Add(start, time.Duration(p.ResponseMinutes)*time.Minute),
+		Resolution: clock.Add(start, time.Duration(p.ResolutionMinutes)*time.Minute),
+	}
+}
+
+// Extend pushes both deadlines back by d of time the clock runs, such as the
+// time a ticket has spent paused.
+func (d Due) Extend(clock Clock, by time.Duration) Due {
+	return Due{Response: clock.Add(d.Response, by), Resolution: clock.Add(d.Resolution, by)}
+}
+
+// Resume pushes a deadline back by the working time a ticket spent paused.
+// Deadlines that had already passed when the clock stopped are left alone.
+func Resume(clock Clock, due, pausedAt, resumedAt time.Time) time.Time {
+	if !due.After(pausedAt) {
+		return due
+	}
+	return clock.Add(resumedAt, clock.Between(pausedAt, due))
+}
+
+// Evaluate reports the SLA state of a ticket at now, or at the moment it was
+// paused. The at-risk window is measured on clock, from creation or the last reopen.
+// It returns nil for tickets without deadlines and for canceled tickets, which are out of scope.
+func Evaluate(clock Clock, t models.Ticket, now time.Time) *models.SLAStatus {
+	if t.ResolutionDueAt == nil || t.Status == models.StatusCanceled {
+		return nil
+	}
+	// A paused ticket is judged as of the moment its clock stopped
+	if t.PausedAt != nil && t.PausedAt.Before(now) {
+		now = *t.PausedAt
+	}
+	start := t.CreatedAt
+	if t.LastReopenedAt != nil {
+		start = *t.LastReopenedAt
+	}
+	s := &models.SLAStatus{
+		ResponseDueAt:   t.ResponseDueAt,
+		ResolutionDueAt: t.ResolutionDueAt,
+	}
+
+	if t.ResponseDueAt != nil {
+		respondedAt := now
+		if t.FirstRespondedAt != nil {
+			respondedAt = *t.FirstRespondedAt
+		}
+		s.ResponseBreached = respondedAt.After(*t.ResponseDueAt)
+		if t.FirstRespondedAt == nil && !s.ResponseBreached {
+			s.AtRisk = atRisk(clock, start, *t.ResponseDueAt, now)
+		}
+	}
+
+	closed := t.Status == models.StatusCompleted && t.ClosedAt != nil
+	resolvedAt := now
+	if closed {
+		resolvedAt = *t.ClosedAt
+	}
+	s.ResolutionBreached = resolvedAt.After(*t.ResolutionDueAt)
+	if !closed && !s.ResolutionBreached && atRisk(clock, start, *t.ResolutionDueAt, now) {
+		s.AtRisk = true
+	}
+
+	switch {
+	case s.ResponseBreached || s.ResolutionBreached:
+		s.State = models.SLAStateBreached
+		s.AtRisk = false
+	case closed:
+		s.State = models.SLAStateMet
+	case s.AtRisk:
+		s.State = models.SLAStateAtRisk
+	default:
+		s.State = models.SLAStateOnTrack
+	}
+	return s
+}
+
+func atRisk(clock Clock, start, due, now time.Time) bool {
+	window := clock.Between(start, due)
+	if window <= 0 {
+		return false
+	}
+	return clock.Between(now, due) < time.Duration(float64(window)*AtRiskFraction)
+}
@@ -0,0 +1,207 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestResolve(t *testing.T) {
+	change := models.InitialChangeRequestNormal
+	policies := []models.SLAPolicy{
+		{Priority: models.PriorityP1, ResponseMinutes: 120, ResolutionMinutes: 960},
+		{Priority: models.PriorityP1, InitialType: &change, ResponseMinutes: 240, ResolutionMinutes: 2880},
+	}
+
+	// Type-specific policy wins
+	p, ok := Resolve(policies, models.PriorityP1, models.InitialChangeRequestNormal)
+	if !ok || p.ResolutionMinutes != 2880 {
+		t.Fatalf("expected change request override, got %+v", p)
+	}
+
+	// Priority default for other types
+	p, ok = Resolve(policies, models.PriorityP1, models.InitialIssueReport)
+	if !ok || p.ResolutionMinutes != 960 {
+		t.Fatalf("expected P1 default, got %+v", p)
+	}
+
+	// Built-in defaults when nothing is configured
+	p, ok = Resolve(nil, models.PriorityP0, models.InitialIssueReport)
+	if !ok || p.ResponseMinutes != 30 || p.ResolutionMinutes != 240 {
+		t.Fatalf("expected built-in P0 policy, got %+v", p)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	due := DueDates(WallClock{}, created, models.SLAPolicy{ResponseMinutes: 60, ResolutionMinutes: 8 * 60})
+	ticket := func(status models.TicketStatus) models.Ticket {
+		return models.Ticket{
+			Status:          status,
+			CreatedAt:       created,
+			ResponseDueAt:   &due.Response,
+			ResolutionDueAt: &due.Resolution,
+		}
+	}
+
+	// Fresh ticket is on track
+	tk := ticket(models.StatusPending)
+	if s := Evaluate(WallClock{}, tk, created.Add(10*time.Minute)); s.State != models.SLAStateOnTrack {
+		t.Fatalf("expected on_track, got %s", s.State)
+	}
+
+	// No response within the last quarter of the response window is at risk
+	if s := Evaluate(WallClock{}, tk, created.Add(50*time.Minute)); s.State != models.SLAStateAtRisk || !s.AtRisk {
+		t.Fatalf("expected at_risk, got %s", s.State)
+	}
+
+	// Missed response is a breach
+	if s := Evaluate(WallClock{}, tk, created.Add(2*time.Hour)); s.State != models.SLAStateBreached || !s.ResponseBreached {
+		t.Fatalf("expected response breach, got %+v", s)
+	}
+
+	// Responded in time, resolution window nearly over
+	responded := created.Add(30 * time.Minute)
+	tk = ticket(models.StatusInProgress)
+	tk.FirstRespondedAt = &responded
+	if s := Evaluate(WallClock{}, tk, created.Add(7*time.Hour)); s.State != models.SLAStateAtRisk {
+		t.Fatalf("expected at_risk on resolution, got %s", s.State)
+	}
+
+	// Completed in time is met, even when evaluated later
+	closed := created.Add(6 * time.Hour)
+	tk = ticket(models.StatusCompleted)
+	tk.FirstRespondedAt = &responded
+	tk.ClosedAt = &closed
+	if s := Evaluate(WallClock{}, tk, created.Add(48*time.Hour)); s.State != models.SLAStateMet {
+		t.Fatalf("expected met, got %s", s.State)
+	}
+
+	// Completed late is breached
+	late := created.Add(9 * time.Hour)
+	tk.ClosedAt = &late
+	if s := Evaluate(WallClock{}, tk, late); s.State != models.SLAStateBreached || !s.ResolutionBreached {
+		t.Fatalf("expected resolution breach, got %+v", s)
+	}
+
+	// A reopen restarts both clocks and owes a new response
+	reopened := created.Add(24 * time.Hour)
+	redue := DueDates(WallClock{}, reopened, models.SLAPolicy{ResponseMinutes: 60, ResolutionMinutes: 8 * 60})
+	tk = ticket(models.StatusInProgress)
+	tk.LastReopenedAt = &reopened
+	tk.ResponseDueAt, tk.ResolutionDueAt = &redue.Response, &redue.Resolution
+	if s := Evaluate(WallClock{}, tk, reopened.Add(50*time.Minute)); s.State != models.SLAStateAtRisk {
+		t.Fatalf("expected at_risk awaiting a response after reopen, got %s", s.State)
+	}
+	if s := Evaluate(WallClock{}, tk, reopened.Add(2*time.Hour)); !s.ResponseBreached {
+		t.Fatalf("expected response breach after reopen, got %+v", s)
+	}
+
+	// Canceled tickets and tickets without deadlines have no SLA
+	if s := Evaluate(WallClock{}, ticket(models.StatusCanceled), created); s != nil {
+		t.Fatalf("expected nil for canceled ticket")
+	}
+	if s := Evaluate(WallClock{}, models.Ticket{Status: models.StatusPending}, created); s != nil {
+		t.Fatalf("expected nil without deadlines")
+	}
+}
+
+// nightClock only runs between 09:00 and 17:00 UTC, every day.
+type nightClock struct{}
+
+func (nightClock) Add(start time.Time, d time.Duration) time.Time {
+	t := start
+	for d > 0 {
+		open := time.Date(t.Year(), t.Month(), t.Day(), 9, 0, 0, 0, time.UTC)
+		close := open.Add(8 * time.Hour)
+		if t.Before(open) {
+			t = open
+		}
+		if !t.Before(close) {
+			t = open.Add(24 * time.Hour)
+			continue
+		}
+		if avail := close.Sub(t); d > avail {
+			d -= avail
+			t = open.Add(24 * time.Hour)
+			continue
+		}
+		return t.Add(d)
+	}
+	return t
+}
+
+func (nightClock) Between(from, to time.Time) time.Duration {
+	var total time.Duration
+	for t := from; t.Before(to); t = t.Add(time.Minute) {
+		if h := t.Hour(); h >= 9 && h < 17 {
+			total += time.Minute
+		}
+	}
+	return total
+}
+
+func TestDueDatesPauseOutsideWorkingTime(t *testing.T) {
+	created := time.Date(2025, 1, 6, 16, 0, 0, 0, time.UTC)
+	due := DueDates(nightClock{}, created, models.SLAPolicy{ResponseMinutes: 120, ResolutionMinutes: 8 * 60})
+	if want := time.Date(2025, 1, 7, 10, 0, 0, 0, time.UTC); !due.Response.Equal(want) {
+		t.Fatalf("expected response due %v, got %v", want, due.Response)
+	}
+	if want := time.Date(2025, 1, 7, 16, 0, 0, 0, time.UTC); !due.Resolution.Equal(want) {
+		t.Fatalf("expected resolution due %v, got %v", want, due.Resolution)
+	}
+
+	// Overnight the clock is stopped, so the ticket is not yet at risk
+	tk := models.Ticket{Status: models.StatusPending, CreatedAt: created, ResponseDueAt: &due.Response, ResolutionDueAt: &due.Resolution}
+	if s := Evaluate(nightClock{}, tk, time.Date(2025, 1, 7, 3, 0, 0, 0, time.UTC)); s.State != models.SLAStateOnTrack {
+		t.Fatalf("expected on_track overnight, got %s", s.State)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	due := DueDates(WallClock{}, created, models.SLAPolicy{ResponseMinutes: 60, ResolutionMinutes: 8 * 60})
+	paused := created.Add(2 * time.Hour)
+	responded := created.Add(10 * time.Minute)
+	tk := models.Ticket{
+		Status:           models.StatusWaitingForRequester,
+		CreatedAt:        created,
+		ResponseDueAt:    &due.Response,
+		ResolutionDueAt:  &due.Resolution,
+		FirstRespondedAt: &responded,
+		PausedAt:         &paused,
+	}
+
+	// Long after the deadline, a paused ticket is still judged at pause time
+	if s := Evaluate(WallClock{}, tk, created.Add(48*time.Hour)); s.State != models.SLAStateOnTrack {
+		t.Fatalf("expected on_track while paused, got %s", s.State)
+	}
+
+	// Resuming a day later keeps the 6h that were left
+	resumed := paused.Add(24 * time.Hour)
+	if got, want := Resume(WallClock{}, due.Resolution, paused, resumed), resumed.Add(6*time.Hour); !got.Equal(want) {
+		t.Fatalf("expected resolution due %v, got %v", want, got)
+	}
+
+	// Deadlines already missed before the pause do not move
+	if got := Resume(WallClock{}, due.Response, paused, resumed); !got.Equal(due.Response) {
+		t.Fatalf("expected breached deadline to stay, got %v", got)
+	}
+}
+
+func TestExtendMatchesResume(t *testing.T) {
+	created := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	policy := models.SLAPolicy{ResponseMinutes: 60, ResolutionMinutes: 8 * 60}
+	due := DueDates(nightClock{}, created, policy)
+	paused := created.Add(2 * time.Hour)
+	resumed := paused.Add(24 * time.Hour)
+	resumedDue := Resume(nightClock{}, due.Resolution, paused, resumed)
+
+	// Recalculating from creation plus the working time spent paused gives the
+	// deadline resuming did, so a later priority change keeps the extension
+	recalculated := DueDates(nightClock{}, created, policy).Extend(nightClock{}, nightClock{}.Between(paused, resumed))
+	if want := time.Date(2025, 1, 7, 17, 0, 0, 0, time.UTC); !resumedDue.Equal(want) || !recalculated.Resolution.Equal(want) {
+		t.Fatalf("expected resolution due %v, got %v after resume and %v after recalculation", want, resumedDue, recalculated.Resolution)
+	}
+}
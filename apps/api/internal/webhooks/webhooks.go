@@ -0,0 +1,117 @@
+// Package webhooks delivers ticket events to the HTTP endpoints Managers
+// subscribe.
+//
+// Deliveries are queued in the transaction that publishes the event (see
+// repositories.EventRepo.Publish), one per matching subscription, and a
+// Worker POSTs them with an HMAC-SHA256 signature, retrying failures with
+// exponential backoff. Every attempt is kept in the delivery log.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/it-tms/apps/api/internal/events"
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+// Events are the event types subscriptions can receive
+var Events = []string{
+	events.TicketCreated,
+	events.TicketStatusChanged,
+	events.TicketAssigned,
+	events.CommentCreated,
+	events.TicketClassified,
+}
+
+// Supports reports whether webhooks are sent for an event type
+func Supports(eventType string) bool {
+	return slices.Contains(Events, eventType)
+}
+
+// Request headers
+const (
+	HeaderEvent     = "X-TMS-Event"
+	HeaderDelivery  = "X-TMS-Delivery"
+	HeaderTimestamp = "X-TMS-Timestamp"
+	// HeaderSignature is "sha256=" followed by Sign's hex digest
+	HeaderSignature = "X-TMS-Signature"
+)
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event   string         `json:"event"`
+	Ticket  Ticket         `json:"ticket"`
+	ActorID *string        `json:"actorId,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+	At      time.Time      `json:"at"`
+}
+
+// Ticket summarises the ticket an event is about, as it was at the event
+type Ticket struct {
+	ID        string                `json:"id"`
+	Code      int32                 `json:"code"`
+	Title     string                `json:"title"`
+	Status    models.TicketStatus   `json:"status"`
+	Priority  models.TicketPriority `json:"priority"`
+	Assignees []string              `json:"assignees"`
+}
+
+// Sign returns the hex HMAC-SHA256 of body and the send time under secret,
+// the same construction signPath uses for download links: the message is
+// body, "|", then the Unix timestamp sent in HeaderTimestamp.
+func Sign(secret string, body []byte, t time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	io.WriteString(mac, "|")
+	io.WriteString(mac, strconv.FormatInt(t.Unix(), 10))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a HeaderSignature value in constant time
+func Verify(secret string, body []byte, t time.Time, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte("sha256="+Sign(secret, body, t)))
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidateURL accepts absolute http and https URLs
+func ValidateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q, want an absolute http(s) URL", s)
+	}
+	return nil
+}
+
+// ValidateEvents checks a subscription's event filter; empty means all
+func ValidateEvents(types []string) error {
+	for _, t := range types {
+		if !Supports(t) {
+			return fmt.Errorf("invalid event %q", t)
+		}
+	}
+	return nil
+}
+
+// ValidateSecret requires secrets long enough to be worth signing with
+func ValidateSecret(s string) error {
+	if len(s) < 16 {
+		return errors.New("secret must be at least 16 characters")
+	}
+	return nil
+}
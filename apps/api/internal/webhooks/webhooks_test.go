@@ -0,0 +1,47 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign_MatchesSignPathConstruction(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"ticket.created"}`)
+
+	sig := Sign("s3cret-s3cret-s3cret", body, at)
+	assert.Len(t, sig, 64)
+	assert.Equal(t, sig, Sign("s3cret-s3cret-s3cret", body, at))
+	assert.NotEqual(t, sig, Sign("another-secret-value", body, at))
+	assert.NotEqual(t, sig, Sign("s3cret-s3cret-s3cret", body, at.Add(time.Second)))
+	assert.NotEqual(t, sig, Sign("s3cret-s3cret-s3cret", []byte(`{"event":"ticket.assigned"}`), at))
+
+	assert.True(t, Verify("s3cret-s3cret-s3cret", body, at, "sha256="+sig))
+	assert.False(t, Verify("s3cret-s3cret-s3cret", body, at, sig))
+	assert.False(t, Verify("s3cret-s3cret-s3cret", []byte(`{}`), at, "sha256="+sig))
+}
+
+func TestValidation(t *testing.T) {
+	assert.NoError(t, ValidateURL("https://chat.example.com/hooks/123"))
+	assert.NoError(t, ValidateURL("http://ci.internal:8080/tms"))
+	for _, u := range []string{"", "chat.example.com/hook", "ftp://example.com", "https://", "/relative"} {
+		assert.Error(t, ValidateURL(u), u)
+	}
+
+	assert.NoError(t, ValidateEvents(nil))
+	assert.NoError(t, ValidateEvents([]string{"ticket.created", "ticket.classified"}))
+	assert.Error(t, ValidateEvents([]string{"ticket.updated"}))
+
+	assert.Error(t, ValidateSecret("short"))
+	assert.NoError(t, ValidateSecret(NewSecret()))
+	assert.NotEqual(t, NewSecret(), NewSecret())
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+}
@@ -0,0 +1,175 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Delivery is a queued event claimed for one subscription; Attempts
+// includes the current one.
+type Delivery struct {
+	ID       string
+	URL      string
+	Secret   string
+	Event    string
+	Payload  []byte
+	Attempts int
+}
+
+// Attempt is the outcome of one POST. Status is 0 when no response came
+// back, in which case Error says why.
+type Attempt struct {
+	Status   int
+	Response string
+	Error    string
+	Duration time.Duration
+}
+
+// OK reports whether the endpoint accepted the delivery
+func (a Attempt) OK() bool { return a.Status >= 200 && a.Status < 300 }
+
+// Outbox is the queue a Worker drains
+type Outbox interface {
+	// Claim leases up to limit due deliveries for lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id string, a Attempt) error
+	// MarkFailed records a and retries at retryAt, or gives up when nil
+	MarkFailed(ctx context.Context, id string, a Attempt, retryAt *time.Time) error
+}
+
+// Worker defaults
+const (
+	DefaultMaxAttempts = 10
+	DefaultBatchSize   = 20
+	DefaultLease       = 2 * time.Minute
+	DefaultTimeout     = 10 * time.Second
+	// maxResponse is how much of a response body the log keeps
+	maxResponse = 1024
+)
+
+// Worker POSTs due deliveries, retrying failures with Backoff until
+// MaxAttempts.
+type Worker struct {
+	Outbox      Outbox
+	Client      *http.Client
+	MaxAttempts int
+	BatchSize   int
+	// Lease keeps a claimed delivery from being claimed again while it is
+	// being sent; it must outlast the client timeout
+	Lease time.Duration
+	Now   func() time.Time
+}
+
+// Backoff is the wait before retrying after the given number of attempts:
+// thirty seconds, doubling up to six hours.
+func Backoff(attempts int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempts && d < 6*time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, 6*time.Hour)
+}
+
+// Run sends due deliveries every interval until ctx is done
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if delivered, failed, err := w.DeliverDue(ctx); err != nil {
+			log.Error().Err(err).Msg("webhook delivery run failed")
+		} else if delivered > 0 || failed > 0 {
+			log.Info().Int("delivered", delivered).Int("failed", failed).Msg("sent webhooks")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue drains the due deliveries batch by batch and reports how many
+// were accepted and how many failed (to be retried or given up on).
+func (w *Worker) DeliverDue(ctx context.Context) (delivered, failed int, err error) {
+	maxAttempts, batchSize, lease, now, client := w.MaxAttempts, w.BatchSize, w.Lease, w.Now, w.Client
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	if now == nil {
+		now = time.Now
+	}
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	for {
+		batch, err := w.Outbox.Claim(ctx, batchSize, lease)
+		if err != nil {
+			return delivered, failed, err
+		}
+		for _, d := range batch {
+			a := Send(ctx, client, d, now())
+			if a.OK() {
+				if err := w.Outbox.MarkDelivered(ctx, d.ID, a); err != nil {
+					return delivered, failed, err
+				}
+				delivered++
+				continue
+			}
+
+			failed++
+			var retryAt *time.Time
+			if d.Attempts < maxAttempts {
+				t := now().Add(Backoff(d.Attempts))
+				retryAt = &t
+			}
+			log.Warn().Str("delivery", d.ID).Int("status", a.Status).Str("error", a.Error).Int("attempts", d.Attempts).Bool("retry", retryAt != nil).Msg("webhook not delivered")
+			if err := w.Outbox.MarkFailed(ctx, d.ID, a, retryAt); err != nil {
+				return delivered, failed, err
+			}
+		}
+		if len(batch) < batchSize || ctx.Err() != nil {
+			return delivered, failed, ctx.Err()
+		}
+	}
+}
+
+// Send POSTs one delivery, signed as of now, and reports what happened
+func Send(ctx context.Context, client *http.Client, d Delivery, now time.Time) Attempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return Attempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "IT-TMS-Webhooks/1")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(d.Secret, d.Payload, now))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return Attempt{Error: err.Error(), Duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	a := Attempt{Status: resp.StatusCode, Response: strings.ToValidUTF8(string(body), ""), Duration: time.Since(start)}
+	if !a.OK() {
+		a.Error = "unexpected status " + resp.Status
+	}
+	return a
+}
@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memOutbox is an Outbox over a slice, honouring leases and retry times
+type memOutbox struct {
+	now   time.Time
+	items []*memItem
+}
+
+type memItem struct {
+	Delivery
+	due       time.Time
+	delivered bool
+	gaveUp    bool
+	attempts  []Attempt
+}
+
+func (o *memOutbox) add(id, url string) {
+	o.items = append(o.items, &memItem{
+		Delivery: Delivery{ID: id, URL: url, Secret: "0123456789abcdef", Event: "ticket.created", Payload: []byte(`{"event":"ticket.created"}`)},
+		due:      o.now,
+	})
+}
+
+func (o *memOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	var out []Delivery
+	for _, it := range o.items {
+		if len(out) == limit {
+			break
+		}
+		if !it.delivered && !it.gaveUp && !it.due.After(o.now) {
+			it.Attempts++
+			it.due = o.now.Add(lease)
+			out = append(out, it.Delivery)
+		}
+	}
+	return out, nil
+}
+
+func (o *memOutbox) find(id string) *memItem {
+	for _, it := range o.items {
+		if it.ID == id {
+			return it
+		}
+	}
+	return nil
+}
+
+func (o *memOutbox) MarkDelivered(ctx context.Context, id string, a Attempt) error {
+	it := o.find(id)
+	it.delivered = true
+	it.attempts = append(it.attempts, a)
+	return nil
+}
+
+func (o *memOutbox) MarkFailed(ctx context.Context, id string, a Attempt, retryAt *time.Time) error {
+	it := o.find(id)
+	it.attempts = append(it.attempts, a)
+	if retryAt == nil {
+		it.gaveUp = true
+	} else {
+		it.due = *retryAt
+	}
+	return nil
+}
+
+// endpoint records requests and answers with status
+type endpoint struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, r)
+	e.bodies = append(e.bodies, body)
+	w.WriteHeader(e.status)
+	io.WriteString(w, "thanks")
+}
+
+func TestWorker_DeliversSigned(t *testing.T) {
+	ep := &endpoint{status: http.StatusNoContent}
+	srv := httptest.NewServer(ep)
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	outbox := &memOutbox{now: now}
+	outbox.add("d1", srv.URL)
+	w := &Worker{Outbox: outbox, Now: func() time.Time { return now }}
+
+	delivered, failed, err := w.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, failed)
+
+	require.Len(t, ep.requests, 1)
+	r := ep.requests[0]
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, "ticket.created", r.Header.Get(HeaderEvent))
+	assert.Equal(t, "d1", r.Header.Get(HeaderDelivery))
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("0123456789abcdef", ep.bodies[0], time.Unix(ts, 0), r.Header.Get(HeaderSignature)))
+
+	it := outbox.find("d1")
+	assert.True(t, it.delivered)
+	assert.Equal(t, http.StatusNoContent, it.attempts[0].Status)
+}
+
+func TestWorker_RetriesThenGivesUp(t *testing.T) {
+	ep := &endpoint{status: http.StatusBadGateway}
+	srv := httptest.NewServer(ep)
+	defer srv.Close()
+
+	outbox := &memOutbox{now: time.Unix(1700000000, 0)}
+	outbox.add("d1", srv.URL)
+	w := &Worker{Outbox: outbox, MaxAttempts: 3, Now: func() time.Time { return outbox.now }}
+
+	for i := 1; i <= 3; i++ {
+		_, failed, err := w.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, failed, "attempt %d", i)
+
+		// Not due again until the backoff has passed
+		_, failed, _ = w.DeliverDue(context.Background())
+		assert.Equal(t, 0, failed)
+		outbox.now = outbox.now.Add(Backoff(i))
+	}
+	it := outbox.find("d1")
+	assert.True(t, it.gaveUp)
+	require.Len(t, it.attempts, 3)
+	assert.Equal(t, http.StatusBadGateway, it.attempts[0].Status)
+	assert.Equal(t, "thanks", it.attempts[0].Response)
+	assert.Contains(t, it.attempts[0].Error, "502")
+
+	_, failed, _ := w.DeliverDue(context.Background())
+	assert.Equal(t, 0, failed)
+}
+
+func TestSend_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	a := Send(context.Background(), &http.Client{Timeout: time.Second}, Delivery{ID: "d1", URL: url, Payload: []byte(`{}`)}, time.Now())
+	assert.False(t, a.OK())
+	assert.Zero(t, a.Status)
+	assert.NotEmpty(t, a.Error)
+}
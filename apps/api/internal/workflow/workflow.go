@@ -0,0 +1,197 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+// Guard is a precondition that must hold before a transition may happen.
+type Guard string
+
+const (
+	// GuardHasAssignee requires at least one assignee on the ticket.
+	GuardHasAssignee Guard = "has_assignee"
+	// GuardIsCreator requires the caller to have opened the ticket.
+	GuardIsCreator Guard = "is_creator"
+	// GuardIsAssignee requires the caller to be assigned to the ticket.
+	GuardIsAssignee Guard = "is_assignee"
+)
+
+// Facts describes the ticket and caller as far as guards are concerned.
+type Facts struct {
+	HasAssignee bool
+	IsCreator   bool
+	IsAssignee  bool
+}
+
+func (f Facts) holds(g Guard) bool {
+	switch g {
+	case GuardHasAssignee:
+		return f.HasAssignee
+	case GuardIsCreator:
+		return f.IsCreator
+	case GuardIsAssignee:
+		return f.IsAssignee
+	}
+	return false
+}
+
+var guardMessages = map[Guard]string{
+	GuardHasAssignee: "ticket must have at least one assignee",
+	GuardIsCreator:   "only the ticket creator can do this",
+	GuardIsAssignee:  "only an assignee can do this",
+}
+
+// Transition is an edge of the workflow. Roles maps every role allowed to
+// take the edge to the guards that apply to that role only; Guards apply to
+// every role.
+type Transition struct {
+	From   models.TicketStatus
+	To     models.TicketStatus
+	Roles  map[models.Role][]Guard
+	Guards []Guard
+}
+
+var staff = []models.Role{models.RoleSupervisor, models.RoleManager}
+
+func rolesFor(user []Guard) map[models.Role][]Guard {
+	roles := map[models.Role][]Guard{models.RoleUser: user}
+	for _, r := range staff {
+		roles[r] = nil
+	}
+	return roles
+}
+
+// Default is the ticket workflow. Users act on tickets they work on or
+// opened; Supervisors and Managers may take any edge. Canceled is final and
+// completed tickets leave only through Reopen.
+var Default = New([]Transition{
+	{From: models.StatusPending, To: models.StatusInProgress, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusPending, To: models.StatusCanceled, Roles: rolesFor([]Guard{GuardIsCreator})},
+	{From: models.StatusInProgress, To: models.StatusPending, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusInProgress, To: models.StatusCompleted, Roles: rolesFor([]Guard{GuardIsAssignee}), Guards: []Guard{GuardHasAssignee}},
+	{From: models.StatusInProgress, To: models.StatusCanceled, Roles: rolesFor([]Guard{GuardIsCreator})},
+
+	// Pausing: blocked on a third party or on the requester
+	{From: models.StatusInProgress, To: models.StatusOnHold, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusInProgress, To: models.StatusWaitingForRequester, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusOnHold, To: models.StatusWaitingForRequester, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusWaitingForRequester, To: models.StatusOnHold, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusOnHold, To: models.StatusInProgress, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusWaitingForRequester, To: models.StatusInProgress, Roles: rolesFor([]Guard{GuardIsAssignee})},
+	{From: models.StatusWaitingForRequester, To: models.StatusCompleted, Roles: rolesFor([]Guard{GuardIsAssignee}), Guards: []Guard{GuardHasAssignee}},
+	{From: models.StatusOnHold, To: models.StatusCanceled, Roles: rolesFor([]Guard{GuardIsCreator})},
+	{From: models.StatusWaitingForRequester, To: models.StatusCanceled, Roles: rolesFor([]Guard{GuardIsCreator})},
+})
+
+// Reopen holds the edge taken by POST /tickets/:id/reopen. It is kept out of
+// Default so that a completed ticket only comes back through the reopen flow,
+// which records a reason, counts the reopen and reverses awarded points.
+var Reopen = New([]Transition{
+	{From: models.StatusCompleted, To: models.StatusInProgress, Roles: rolesFor([]Guard{GuardIsCreator})},
+})
+
+// SystemRole is the role background jobs such as auto-close check their
+// moves with. They may take the edges staff may take, but as nobody is the
+// caller, no creator or assignee guard holds for them.
+const SystemRole = models.RoleManager
+
+// IsPaused reports whether SLA clocks are stopped in status s.
+func IsPaused(s models.TicketStatus) bool {
+	return s == models.StatusOnHold || s == models.StatusWaitingForRequester
+}
+
+// RequiresReason reports whether moving to status s needs a reason comment.
+func RequiresReason(s models.TicketStatus) bool {
+	return IsPaused(s)
+}
+
+// Workflow is a set of allowed status transitions.
+type Workflow struct {
+	transitions []Transition
+}
+
+// New builds a workflow from its transitions.
+func New(transitions []Transition) *Workflow {
+	return &Workflow{transitions: transitions}
+}
+
+// TransitionError explains why a transition was refused. Allowed lists the
+// statuses the caller could move the ticket to instead.
+type TransitionError struct {
+	From    models.TicketStatus
+	To      models.TicketStatus
+	Reason  string
+	Allowed []models.TicketStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change status from %s to %s: %s", e.From, e.To, e.Reason)
+}
+
+// IsStatus reports whether s is a status known to the workflow.
+func (w *Workflow) IsStatus(s models.TicketStatus) bool {
+	for _, t := range w.transitions {
+		if t.From == s || t.To == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workflow) find(from, to models.TicketStatus) (Transition, bool) {
+	for _, t := range w.transitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// failedGuard returns the first guard of t that does not hold for role, if any.
+func failedGuard(t Transition, role models.Role, facts Facts) (Guard, bool) {
+	for _, g := range append(append([]Guard{}, t.Guards...), t.Roles[role]...) {
+		if !facts.holds(g) {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+// Check validates a transition for a caller. It returns a *TransitionError
+// when the edge does not exist, the role may not take it, or a guard fails.
+func (w *Workflow) Check(from, to models.TicketStatus, role models.Role, facts Facts) error {
+	refuse := func(reason string) error {
+		return &TransitionError{From: from, To: to, Reason: reason, Allowed: w.Available(from, role, facts)}
+	}
+	t, ok := w.find(from, to)
+	if !ok {
+		return refuse("transition not allowed")
+	}
+	if _, ok := t.Roles[role]; !ok {
+		return refuse(fmt.Sprintf("role %s cannot make this transition", role))
+	}
+	if g, failed := failedGuard(t, role, facts); failed {
+		return refuse(guardMessages[g])
+	}
+	return nil
+}
+
+// Available lists the statuses the caller can move a ticket to right now.
+func (w *Workflow) Available(from models.TicketStatus, role models.Role, facts Facts) []models.TicketStatus {
+	next := []models.TicketStatus{}
+	for _, t := range w.transitions {
+		if t.From != from {
+			continue
+		}
+		if _, ok := t.Roles[role]; !ok {
+			continue
+		}
+		if _, failed := failedGuard(t, role, facts); failed {
+			continue
+		}
+		next = append(next, t.To)
+	}
+	return next
+}
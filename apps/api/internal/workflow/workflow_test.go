@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/it-tms/apps/api/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	w := Default
+
+	// Staff can start any pending ticket
+	if err := w.Check(models.StatusPending, models.StatusInProgress, models.RoleSupervisor, Facts{}); err != nil {
+		t.Fatalf("expected supervisor to start ticket, got %v", err)
+	}
+
+	// Users must be assigned to work on a ticket
+	if err := w.Check(models.StatusPending, models.StatusInProgress, models.RoleUser, Facts{}); err == nil {
+		t.Fatalf("expected unassigned user to be refused")
+	}
+	if err := w.Check(models.StatusPending, models.StatusInProgress, models.RoleUser, Facts{IsAssignee: true, HasAssignee: true}); err != nil {
+		t.Fatalf("expected assignee to start ticket, got %v", err)
+	}
+
+	// Completion needs an assignee, even for managers
+	err := w.Check(models.StatusInProgress, models.StatusCompleted, models.RoleManager, Facts{})
+	var te *TransitionError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected TransitionError, got %v", err)
+	}
+	if te.Reason != guardMessages[GuardHasAssignee] {
+		t.Fatalf("expected has_assignee reason, got %q", te.Reason)
+	}
+
+	// Canceled is final and reports no way out
+	err = w.Check(models.StatusCanceled, models.StatusInProgress, models.RoleManager, Facts{HasAssignee: true})
+	if !errors.As(err, &te) || len(te.Allowed) != 0 {
+		t.Fatalf("expected refusal with no allowed states, got %v", err)
+	}
+
+	// Completed tickets only come back through Reopen
+	if err := w.Check(models.StatusCompleted, models.StatusInProgress, models.RoleManager, Facts{HasAssignee: true}); err == nil {
+		t.Fatalf("expected status change out of completed to be refused")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	// Requesters and staff can reopen; other users cannot
+	if err := Reopen.Check(models.StatusCompleted, models.StatusInProgress, models.RoleUser, Facts{IsCreator: true}); err != nil {
+		t.Fatalf("expected requester to reopen, got %v", err)
+	}
+	if err := Reopen.Check(models.StatusCompleted, models.StatusInProgress, models.RoleSupervisor, Facts{}); err != nil {
+		t.Fatalf("expected supervisor to reopen, got %v", err)
+	}
+	if err := Reopen.Check(models.StatusCompleted, models.StatusInProgress, models.RoleUser, Facts{IsAssignee: true}); err == nil {
+		t.Fatalf("expected non-requester user to be refused")
+	}
+
+	// Only completed tickets can be reopened
+	if err := Reopen.Check(models.StatusCanceled, models.StatusInProgress, models.RoleManager, Facts{}); err == nil {
+		t.Fatalf("expected canceled ticket reopen to be refused")
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	w := Default
+
+	got := w.Available(models.StatusInProgress, models.RoleUser, Facts{IsCreator: true})
+	want := []models.TicketStatus{models.StatusCanceled}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("creator without assignment: expected %v, got %v", want, got)
+	}
+
+	got = w.Available(models.StatusInProgress, models.RoleSupervisor, Facts{HasAssignee: true})
+	want = []models.TicketStatus{models.StatusPending, models.StatusCompleted, models.StatusCanceled, models.StatusOnHold, models.StatusWaitingForRequester}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("supervisor: expected %v, got %v", want, got)
+	}
+
+	if got := w.Available(models.StatusPending, models.RoleAnonymous, Facts{}); len(got) != 0 {
+		t.Fatalf("anonymous should have no transitions, got %v", got)
+	}
+}
+
+func TestPausedStatuses(t *testing.T) {
+	w := Default
+
+	// An assignee can wait on the requester and pick the work back up
+	facts := Facts{IsAssignee: true, HasAssignee: true}
+	if err := w.Check(models.StatusInProgress, models.StatusWaitingForRequester, models.RoleUser, facts); err != nil {
+		t.Fatalf("expected assignee to pause ticket, got %v", err)
+	}
+	if err := w.Check(models.StatusWaitingForRequester, models.StatusInProgress, models.RoleUser, facts); err != nil {
+		t.Fatalf("expected assignee to resume ticket, got %v", err)
+	}
+
+	// A pending ticket cannot be put on hold before work has started
+	if err := w.Check(models.StatusPending, models.StatusOnHold, models.RoleManager, facts); err == nil {
+		t.Fatalf("expected pending -> on_hold to be refused")
+	}
+
+	if !IsPaused(models.StatusOnHold) || !IsPaused(models.StatusWaitingForRequester) || IsPaused(models.StatusInProgress) {
+		t.Fatalf("unexpected IsPaused result")
+	}
+	if !RequiresReason(models.StatusOnHold) || RequiresReason(models.StatusCompleted) {
+		t.Fatalf("unexpected RequiresReason result")
+	}
+}
+
+func TestSystemRoleCancelsPausedTickets(t *testing.T) {
+	for _, from := range []models.TicketStatus{models.StatusOnHold, models.StatusWaitingForRequester} {
+		if err := Default.Check(from, models.StatusCanceled, SystemRole, Facts{}); err != nil {
+			t.Fatalf("expected auto-close to cancel %s ticket, got %v", from, err)
+		}
+	}
+	// There is no edge from on_hold to completed, and completion needs an assignee
+	if err := Default.Check(models.StatusOnHold, models.StatusCompleted, SystemRole, Facts{HasAssignee: true}); err == nil {
+		t.Fatalf("expected on_hold -> completed to be refused")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -16,11 +17,56 @@ type Config struct {
 	UploadDir          string
 	SecureCookies      bool
 	WebAppURL          string
+	BusinessTimezone   string
+	AutoCloseIdleDays  int
+	MigrateOnStart     bool
+	// Outgoing mail; notifications are off while SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	// MailLocale is the language of notification emails (th or en)
+	MailLocale string
+	// DigestTime is when daily digests go out (HH:MM, business timezone)
+	DigestTime string
+	// MentionWatch makes people mentioned in a comment watchers of its ticket
+	MentionWatch bool
+	// MailInboundToken authenticates the inbound email gateway, which is
+	// off while it is empty
+	MailInboundToken string
+	// AccessTokenTTL is how long a signed JWT is accepted; RefreshTokenTTL
+	// is how long a session lasts without being refreshed
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// SignupMode is "open" (anyone can register as a User) or "invite"
+	// (accounts only come from Managers)
+	SignupMode string
+	// InviteTTL is how long an invitation link can be redeemed and
+	// PasswordResetTTL how long a password reset link can be used
+	InviteTTL        time.Duration
+	PasswordResetTTL time.Duration
+	// Single sign-on through an OpenID Connect provider, off while
+	// OIDCIssuerURL is empty. OIDCRoleRules maps provider groups to roles
+	// ("group=Role,..."); users in none of them get OIDCDefaultRole, or are
+	// turned away when it is empty.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       string
+	OIDCGroupsClaim  string
+	OIDCRoleRules    string
+	OIDCDefaultRole  string
 }
 
 func Load() Config {
 	port, _ := strconv.Atoi(get("PORT", "8080"))
 	secure := strings.ToLower(get("SECURE_COOKIES", "false")) == "true"
+	autoCloseDays, _ := strconv.Atoi(get("AUTO_CLOSE_IDLE_DAYS", "14"))
+	migrateOnStart := strings.ToLower(get("MIGRATE_ON_START", "false")) == "true"
+	smtpPort, _ := strconv.Atoi(get("SMTP_PORT", "587"))
+	mentionWatch := strings.ToLower(get("MENTION_WATCH", "true")) == "true"
 
 	return Config{
 		Port:               port,
@@ -30,9 +76,44 @@ func Load() Config {
 		UploadDir:          get("UPLOAD_DIR", "uploads"),
 		SecureCookies:      secure,
 		WebAppURL:          get("WEB_APP_URL", "http://localhost:3000"),
+		BusinessTimezone:   get("BUSINESS_TIMEZONE", "Asia/Bangkok"),
+		AutoCloseIdleDays:  autoCloseDays,
+		MigrateOnStart:     migrateOnStart,
+		SMTPHost:           get("SMTP_HOST", ""),
+		SMTPPort:           smtpPort,
+		SMTPUsername:       get("SMTP_USERNAME", ""),
+		SMTPPassword:       get("SMTP_PASSWORD", ""),
+		MailFrom:           get("MAIL_FROM", "IT-TMS <no-reply@localhost>"),
+		MailLocale:         get("MAIL_LOCALE", "th"),
+		DigestTime:         get("DIGEST_TIME", "08:00"),
+		MentionWatch:       mentionWatch,
+		MailInboundToken:   get("MAIL_INBOUND_TOKEN", ""),
+		AccessTokenTTL:     duration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:    duration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		SignupMode:         strings.ToLower(get("SIGNUP_MODE", "open")),
+		InviteTTL:          duration("INVITE_TTL", 7*24*time.Hour),
+		PasswordResetTTL:   duration("PASSWORD_RESET_TTL", time.Hour),
+		OIDCIssuerURL:      get("OIDC_ISSUER_URL", ""),
+		OIDCClientID:       get("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:   get("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:    get("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+		OIDCScopes:         get("OIDC_SCOPES", "openid email profile"),
+		OIDCGroupsClaim:    get("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCRoleRules:      get("OIDC_ROLE_RULES", ""),
+		OIDCDefaultRole:    get("OIDC_DEFAULT_ROLE", ""),
 	}
 }
 
+// duration reads a Go duration such as "15m", falling back to def when the
+// value is missing, malformed or not positive
+func duration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(get(key, ""))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
 func get(key, def string) string {
 	if v := viper.GetString(key); v != "" {
 		return v
@@ -41,4 +122,4 @@ func get(key, def string) string {
 		return v
 	}
 	return def
-}
\ No newline at end of file
+}
@@ -0,0 +1,137 @@
+"use client";
+import { useForm } from "react-hook-form";
+import { z } from "zod";
+import { zodResolver } from "@hookform/resolvers/zod";
+import { Input, Button, Card, CardBody, CardHeader } from "@heroui/react";
+import { useSearchParams } from "next/navigation";
+import { useTranslations } from 'next-intl';
+import { Suspense, useEffect, useState } from 'react';
+
+type Invitation = { email: string; role: string; expiresAt: string };
+
+// Use current hostname with port 8000 for production-like environment
+const API = typeof window !== 'undefined'
+  ? `${window.location.protocol}//${window.location.hostname}:8000`
+  : (process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080");
+
+function AcceptInviteForm() {
+  const t = useTranslations('auth');
+  const searchParams = useSearchParams();
+  const token = searchParams.get('token') || '';
+  const [invitation, setInvitation] = useState<Invitation | null>(null);
+  const [invalid, setInvalid] = useState(false);
+
+  const schema = z.object({
+    name: z.string().trim().min(1).max(100),
+    password: z.string().min(8, t('passwordMin')),
+    confirmPassword: z.string(),
+  }).refine((v) => v.password === v.confirmPassword, { message: t('passwordMismatch'), path: ['confirmPassword'] });
+  type Form = z.infer<typeof schema>;
+  const { register, handleSubmit, formState: { errors, isSubmitting }, setError } = useForm<Form>({ resolver: zodResolver(schema) });
+
+  useEffect(() => {
+    if (!token) {
+      setInvalid(true);
+      return;
+    }
+    fetch(`${API}/api/v1/auth/invitation?token=${encodeURIComponent(token)}`)
+      .then(async (res) => {
+        if (!res.ok) throw new Error('invalid invitation');
+        setInvitation((await res.json()).data);
+      })
+      .catch(() => setInvalid(true));
+  }, [token]);
+
+  async function onSubmit(values: Form) {
+    const res = await fetch(`${API}/api/v1/auth/invitation/accept`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      credentials: "include",
+      body: JSON.stringify({ token, name: values.name, password: values.password }),
+    });
+    if (res.status === 409) {
+      setError("confirmPassword", { message: t('emailTaken') });
+      return;
+    }
+    if (!res.ok) {
+      setInvalid(true);
+      return;
+    }
+    window.location.href = '/dashboard';
+  }
+
+  return (
+    <div className="container flex items-center justify-center min-h-[80vh]">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <h1 className="text-3xl font-bold gradient-text mb-2">{t('acceptInvite')}</h1>
+          {invitation && <p className="text-white/70">{t('invitedAs', { role: invitation.role })}</p>}
+        </div>
+
+        <Card className="glass">
+          <CardHeader className="text-center pb-2">
+            <h2 className="text-xl font-semibold">{t('setUpAccount')}</h2>
+          </CardHeader>
+          <CardBody className="space-y-6">
+            {invalid ? (
+              <p className="text-danger text-center">{t('invalidInvitation')}</p>
+            ) : (
+              <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
+                <Input
+                  label={t('email')}
+                  variant="bordered"
+                  type="email"
+                  value={invitation?.email || ''}
+                  isReadOnly
+                />
+                <Input
+                  label={t('name')}
+                  variant="bordered"
+                  placeholder={t('enterName')}
+                  {...register("name")}
+                  isInvalid={!!errors.name}
+                  errorMessage={errors.name?.message}
+                />
+                <Input
+                  label={t('password')}
+                  type="password"
+                  variant="bordered"
+                  placeholder={t('enterPassword')}
+                  {...register("password")}
+                  isInvalid={!!errors.password}
+                  errorMessage={errors.password?.message}
+                />
+                <Input
+                  label={t('confirmPassword')}
+                  type="password"
+                  variant="bordered"
+                  {...register("confirmPassword")}
+                  isInvalid={!!errors.confirmPassword}
+                  errorMessage={errors.confirmPassword?.message}
+                />
+                <Button
+                  type="submit"
+                  color="primary"
+                  size="lg"
+                  isLoading={isSubmitting}
+                  isDisabled={!invitation}
+                  className="w-full font-semibold"
+                >
+                  {isSubmitting ? t('creatingAccount') : t('createAccount')}
+                </Button>
+              </form>
+            )}
+          </CardBody>
+        </Card>
+      </div>
+    </div>
+  );
+}
+
+export default function AcceptInvite() {
+  return (
+    <Suspense fallback={<div className="container flex items-center justify-center min-h-[80vh]">Loading...</div>}>
+      <AcceptInviteForm />
+    </Suspense>
+  );
+}
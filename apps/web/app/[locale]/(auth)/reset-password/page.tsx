@@ -0,0 +1,133 @@
+"use client";
+import { useForm } from "react-hook-form";
+import { z } from "zod";
+import { zodResolver } from "@hookform/resolvers/zod";
+import { Input, Button, Card, CardBody, CardHeader } from "@heroui/react";
+import { useSearchParams } from "next/navigation";
+import { useTranslations } from 'next-intl';
+import { Suspense, useState } from 'react';
+
+// Use current hostname with port 8000 for production-like environment
+const API = typeof window !== 'undefined'
+  ? `${window.location.protocol}//${window.location.hostname}:8000`
+  : (process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080");
+
+// ForgotForm asks for a reset link
+function ForgotForm() {
+  const t = useTranslations('auth');
+  const schema = z.object({ email: z.string().email() });
+  type Form = z.infer<typeof schema>;
+  const { register, handleSubmit, formState: { errors, isSubmitting } } = useForm<Form>({ resolver: zodResolver(schema) });
+  const [result, setResult] = useState<'sent' | 'unavailable' | null>(null);
+
+  async function onSubmit(values: Form) {
+    const res = await fetch(`${API}/api/v1/auth/password/forgot`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(values),
+    });
+    setResult(res.status === 404 ? 'unavailable' : 'sent');
+  }
+
+  if (result) {
+    return <p className="text-center text-white/80">{result === 'sent' ? t('resetLinkSent') : t('resetUnavailable')}</p>;
+  }
+  return (
+    <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
+      <p className="text-sm text-white/70">{t('forgotPasswordHint')}</p>
+      <Input
+        label={t('email')}
+        variant="bordered"
+        type="email"
+        placeholder={t('enterEmail')}
+        {...register("email")}
+        isInvalid={!!errors.email}
+        errorMessage={errors.email?.message}
+      />
+      <Button type="submit" color="primary" size="lg" isLoading={isSubmitting} className="w-full font-semibold">
+        {isSubmitting ? t('sending') : t('sendResetLink')}
+      </Button>
+    </form>
+  );
+}
+
+// NewPasswordForm sets the new password with the token from the emailed link
+function NewPasswordForm({ token }: { token: string }) {
+  const t = useTranslations('auth');
+  const schema = z.object({
+    password: z.string().min(8, t('passwordMin')),
+    confirmPassword: z.string(),
+  }).refine((v) => v.password === v.confirmPassword, { message: t('passwordMismatch'), path: ['confirmPassword'] });
+  type Form = z.infer<typeof schema>;
+  const { register, handleSubmit, formState: { errors, isSubmitting }, setError } = useForm<Form>({ resolver: zodResolver(schema) });
+
+  async function onSubmit(values: Form) {
+    const res = await fetch(`${API}/api/v1/auth/password/reset`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      credentials: "include",
+      body: JSON.stringify({ token, password: values.password }),
+    });
+    if (!res.ok) {
+      setError("confirmPassword", { message: t('invalidResetLink') });
+      return;
+    }
+    window.location.href = '/dashboard';
+  }
+
+  return (
+    <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
+      <Input
+        label={t('newPassword')}
+        type="password"
+        variant="bordered"
+        {...register("password")}
+        isInvalid={!!errors.password}
+        errorMessage={errors.password?.message}
+      />
+      <Input
+        label={t('confirmPassword')}
+        type="password"
+        variant="bordered"
+        {...register("confirmPassword")}
+        isInvalid={!!errors.confirmPassword}
+        errorMessage={errors.confirmPassword?.message}
+      />
+      <Button type="submit" color="primary" size="lg" isLoading={isSubmitting} className="w-full font-semibold">
+        {isSubmitting ? t('resetting') : t('resetPassword')}
+      </Button>
+    </form>
+  );
+}
+
+function ResetPasswordCard() {
+  const t = useTranslations('auth');
+  const token = useSearchParams().get('token');
+
+  return (
+    <div className="container flex items-center justify-center min-h-[80vh]">
+      <div className="w-full max-w-md">
+        <div className="text-center mb-8">
+          <h1 className="text-3xl font-bold gradient-text mb-2">{t('resetPassword')}</h1>
+        </div>
+
+        <Card className="glass">
+          <CardHeader className="text-center pb-2">
+            <h2 className="text-xl font-semibold">{token ? t('newPassword') : t('forgotPassword')}</h2>
+          </CardHeader>
+          <CardBody className="space-y-6">
+            {token ? <NewPasswordForm token={token} /> : <ForgotForm />}
+          </CardBody>
+        </Card>
+      </div>
+    </div>
+  );
+}
+
+export default function ResetPassword() {
+  return (
+    <Suspense fallback={<div className="container flex items-center justify-center min-h-[80vh]">Loading...</div>}>
+      <ResetPasswordCard />
+    </Suspense>
+  );
+}
@@ -5,7 +5,7 @@ import { zodResolver } from "@hookform/resolvers/zod";
 import { Input, Button, Card, CardBody, CardHeader } from "@heroui/react";
 import { Eye, EyeOff } from "lucide-react";
 import { useSearchParams } from "next/navigation";
-import { useTranslations } from 'next-intl';
+import { useLocale, useTranslations } from 'next-intl';
 import { Suspense, useState } from 'react';
 
 const schema = z.object({
@@ -18,12 +18,17 @@ type Form = z.infer<typeof schema>;
 const API = typeof window !== 'undefined' 
   ? `${window.location.protocol}//${window.location.hostname}:8000`
   : (process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080");
+const SSO_ENABLED = process.env.NEXT_PUBLIC_SSO_ENABLED === 'true';
+// Messages for the error query the single sign-on callback returns with
+const SSO_ERRORS: Record<string, string> = { sso_failed: 'ssoFailed', sso_denied: 'ssoDenied', sso_conflict: 'ssoConflict' };
 
 function SignInForm() {
   const t = useTranslations('auth');
+  const locale = useLocale();
   const { register, handleSubmit, formState: { errors, isSubmitting }, setError } = useForm<Form>({ resolver: zodResolver(schema) });
   const searchParams = useSearchParams();
   const [isPasswordVisible, setIsPasswordVisible] = useState(false);
+  const ssoError = SSO_ERRORS[searchParams.get('error') || ''];
 
   async function onSubmit(values: Form) {
     const res = await fetch(`${API}/api/v1/auth/sign-in`, {
@@ -55,6 +60,21 @@ function SignInForm() {
             <h2 className="text-xl font-semibold">{t('signIn')}</h2>
           </CardHeader>
           <CardBody className="space-y-6">
+            {ssoError && <p className="text-danger text-center text-sm">{t(ssoError)}</p>}
+            {SSO_ENABLED && (
+              <>
+                <Button
+                  as="a"
+                  href={`${API}/api/v1/auth/oidc/login?redirect=${encodeURIComponent(searchParams.get('redirect') || '/dashboard')}`}
+                  variant="bordered"
+                  size="lg"
+                  className="w-full font-semibold"
+                >
+                  {t('signInWithSso')}
+                </Button>
+                <p className="text-center text-sm text-white/50">{t('orDivider')}</p>
+              </>
+            )}
             <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
               <Input 
                 label={t('email')} 
@@ -98,6 +118,11 @@ function SignInForm() {
                 {isSubmitting ? t('signingIn') : t('signIn')}
               </Button>
             </form>
+            <div className="text-center">
+              <a href={`/${locale}/reset-password`} className="text-sm text-white/70 hover:text-white">
+                {t('forgotPassword')}
+              </a>
+            </div>
           </CardBody>
         </Card>
       </div>
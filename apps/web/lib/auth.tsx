@@ -7,6 +7,9 @@ const API = typeof window !== 'undefined' && window.location.port === '8000'
   ? '' // Use relative URLs when accessed through port 8000 (production-like)
   : (process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080");
 
+// Access tokens last 15 minutes (ACCESS_TOKEN_TTL); refresh well before that
+const SESSION_REFRESH_MS = 10 * 60 * 1000;
+
 export type UserRole = "Anonymous" | "User" | "Supervisor" | "Manager";
 
 export interface User {
@@ -44,16 +47,45 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     checkAuth();
   }, []);
 
-  const checkAuth = async () => {
+  // Keep the access token cookie fresh while someone is signed in
+  useEffect(() => {
+    if (!user) return;
+    const timer = setInterval(refreshSession, SESSION_REFRESH_MS);
+    return () => clearInterval(timer);
+  }, [user?.id]);
+
+  // Trades the refresh token cookie for a new access token
+  const refreshSession = async (): Promise<boolean> => {
     try {
-      const response = await fetch(`${API}/api/v1/me`, {
+      const response = await fetch(`${API}/api/v1/auth/refresh`, {
+        method: "POST",
         credentials: "include",
       });
-      if (response.ok) {
-        const data = await response.json();
-        if (data.data.role !== "Anonymous") {
-          setUser(data.data);
-        }
+      return response.ok;
+    } catch (error) {
+      console.error("Session refresh failed:", error);
+      return false;
+    }
+  };
+
+  const fetchMe = async (): Promise<User | null> => {
+    const response = await fetch(`${API}/api/v1/me`, {
+      credentials: "include",
+    });
+    if (!response.ok) return null;
+    const data = await response.json();
+    return data.data.role !== "Anonymous" ? data.data : null;
+  };
+
+  const checkAuth = async () => {
+    try {
+      let me = await fetchMe();
+      // The access token may have expired while the session is still alive
+      if (!me && (await refreshSession())) {
+        me = await fetchMe();
+      }
+      if (me) {
+        setUser(me);
       }
     } catch (error) {
       console.error("Auth check failed:", error);